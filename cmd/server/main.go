@@ -16,9 +16,17 @@ import (
     "distributed-cache/internal/cache"
     "distributed-cache/internal/config"
     "distributed-cache/internal/handlers"
+    "distributed-cache/internal/hotkeys"
+    "distributed-cache/internal/metrics"
     "distributed-cache/internal/middleware"
+    "distributed-cache/internal/scheduler"
+    "distributed-cache/internal/tlsutil"
+    "distributed-cache/internal/tracing"
 )
 
+// version del servicio, reportada en logs y trazas
+const version = "1.0.0"
+
 func main() {
     // Load configuration
     cfg, err := config.LoadConfig()
@@ -36,10 +44,16 @@ func main() {
     defer logger.Sync()
 
     logger.Info("Starting Distributed Cache Server",
-        zap.String("version", "1.0.0"),
+        zap.String("version", version),
         zap.String("address", cfg.Server.GetAddress()),
     )
 
+    // Initialize tracing
+    shutdownTracing, err := tracing.Setup(cfg.Tracing, version, logger)
+    if err != nil {
+        logger.Fatal("Failed to initialize tracing", zap.Error(err))
+    }
+
     // Initialize cache
     cacheInstance, err := cache.NewRedisCache(&cfg.Cache, logger)
     if err != nil {
@@ -51,10 +65,13 @@ func main() {
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
     defer cancel()
 
+    // NewRedisCache only succeeds without Redis when cache.start_degraded is set;
+    // /readyz reports the outage until the connection is established
     if err := cacheInstance.Ping(ctx); err != nil {
-        logger.Fatal("Failed to connect to cache", zap.Error(err))
+        logger.Warn("Cache unavailable, serving in degraded mode", zap.Error(err))
+    } else {
+        logger.Info("Cache connection established successfully")
     }
-    logger.Info("Cache connection established successfully")
 
     // Configure Gin
     if cfg.Logger.Level == "debug" {
@@ -66,48 +83,239 @@ func main() {
     // Create router
     router := gin.New()
 
+    // Client IPs feed the rate limiter, so X-Forwarded-For is only honored
+    // when it comes from a configured proxy
+    if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+        logger.Fatal("Invalid trusted proxies", zap.Error(err))
+    }
+
     // Middlewares
+    // RequestID and Tracing run first so the request logger can carry their IDs
     router.Use(middleware.Recovery(logger))
+    router.Use(middleware.RequestID())
+    router.Use(middleware.Tracing())
     router.Use(middleware.Logger(logger))
     router.Use(middleware.CORS())
-    router.Use(middleware.RequestID())
-    router.Use(middleware.RateLimiter())
+
+    // Metrics
+    if cfg.Metrics.Enabled {
+        m := metrics.New(cacheInstance.Status, cfg.Metrics.MaxNamespaces)
+        cacheInstance.AddObserver(m)
+        router.Use(middleware.Metrics(m))
+        router.GET(cfg.Metrics.Path, gin.WrapH(m.Handler()))
+    }
+
+    // Authentication runs before rate limiting so per-key limits apply
+    authenticate, err := middleware.Authenticate(cfg.Auth, logger)
+    if err != nil {
+        logger.Fatal("Failed to configure authentication", zap.Error(err))
+    }
+    rateLimiter := middleware.RateLimiter(cacheInstance, cfg.RateLimit, logger)
+    idempotency := middleware.Idempotency(cacheInstance, cfg.Idempotency, logger)
 
     // Initialize handlers
     cacheHandler := handlers.NewCacheHandler(cacheInstance, logger)
+    patchHandler := handlers.NewPatchHandler(cacheInstance, logger)
+    lockHandler := handlers.NewLockHandler(cacheInstance, logger)
+    rateLimitHandler := handlers.NewRateLimitHandler(cacheInstance, logger)
+    idempotencyHandler := handlers.NewIdempotencyHandler(cacheInstance, logger)
+    sessionHandler := handlers.NewSessionHandler(cacheInstance, logger)
+    hllHandler := handlers.NewHyperLogLogHandler(cacheInstance, cfg.HyperLogLog, logger)
+    bloomHandler := handlers.NewBloomHandler(cacheInstance, cfg.Bloom, logger)
+    scheduleHandler := handlers.NewScheduleHandler(cacheInstance, cfg.Scheduler, logger)
+    pubsubHandler := handlers.NewPubSubHandler(cacheInstance, cfg.PubSub, logger)
+    hashHandler := handlers.NewHashHandler(cacheInstance, logger)
+    // Blocking pops must leave pool connections for the rest of the traffic
+    if cfg.Cache.PoolSize > 0 && cfg.List.MaxBlockingPops >= cfg.Cache.PoolSize {
+        logger.Fatal("list.max_blocking_pops must be lower than cache.pool_size",
+            zap.Int("max_blocking_pops", cfg.List.MaxBlockingPops),
+            zap.Int("pool_size", cfg.Cache.PoolSize))
+    }
+    listHandler := handlers.NewListHandler(cacheInstance, cfg.List, logger)
+    setHandler := handlers.NewSetHandler(cacheInstance, logger)
+    zsetHandler := handlers.NewZSetHandler(cacheInstance, logger)
+    healthHandler := handlers.NewHealthHandler(cacheInstance, cfg.Health, logger)
 
     // Health routes
     router.GET("/health", cacheHandler.Health)
+    router.GET("/livez", healthHandler.Livez)
+    router.GET("/readyz", healthHandler.Readyz)
     router.GET("/ping", func(c *gin.Context) {
         c.JSON(http.StatusOK, gin.H{"message": "pong"})
     })
 
+    // Permission scopes
+    read := middleware.RequireScope(middleware.ScopeRead)
+    write := middleware.RequireScope(middleware.ScopeWrite)
+    admin := middleware.RequireScope(middleware.ScopeAdmin)
+
     // Cache routes
-    api := router.Group("/api/v1")
+    api := router.Group("/api/v1", authenticate, rateLimiter, idempotency)
     {
         cache := api.Group("/cache")
         {
             // Individual operations
-            cache.PUT("/:key", cacheHandler.SetItem)
-            cache.GET("/:key", cacheHandler.GetItem)
-            cache.DELETE("/:key", cacheHandler.DeleteItem)
-            cache.HEAD("/:key", cacheHandler.ExistsItem)
+            cache.PUT("/:key", write, cacheHandler.SetItem)
+            cache.GET("/:key", read, cacheHandler.GetItem)
+            cache.DELETE("/:key", write, cacheHandler.DeleteItem)
+            cache.POST("/:key/getset", write, cacheHandler.GetSetItem)
+            cache.POST("/:key/getdel", write, cacheHandler.GetDelItem)
+            cache.PATCH("/:key", write, patchHandler.PatchItem)
+            cache.HEAD("/:key", read, cacheHandler.ExistsItem)
 
             // TTL operations
-            cache.PUT("/:key/expire", cacheHandler.SetExpiration)
-            cache.GET("/:key/ttl", cacheHandler.GetTTL)
+            cache.PUT("/:key/expire", write, cacheHandler.SetExpiration)
+            cache.GET("/:key/ttl", read, cacheHandler.GetTTL)
 
             // Batch operations
-            cache.POST("/batch", cacheHandler.SetMultiple)
-            cache.POST("/batch/get", cacheHandler.GetMultiple)
-            cache.DELETE("/batch", cacheHandler.DeleteMultiple)
+            cache.POST("/batch", write, cacheHandler.SetMultiple)
+            cache.POST("/batch/get", read, cacheHandler.GetMultiple)
+            cache.DELETE("/batch", write, cacheHandler.DeleteMultiple)
 
             // Management operations
-            cache.DELETE("/", cacheHandler.Clear)
-            cache.GET("/keys", cacheHandler.GetKeys)
-            cache.GET("/stats", cacheHandler.GetStats)
+            cache.DELETE("/", admin, cacheHandler.Clear)
+            cache.GET("/keys", read, cacheHandler.GetKeys)
+            cache.GET("/stats", admin, cacheHandler.GetStats)
+        }
+
+        // Distributed lock routes
+        locks := api.Group("/locks")
+        {
+            locks.POST("/:name", write, lockHandler.Acquire)
+            locks.PUT("/:name/renew", write, lockHandler.Renew)
+            locks.DELETE("/:name", write, lockHandler.Release)
+        }
+
+        // Hash routes
+        hash := api.Group("/hash")
+        {
+            hash.GET("/:key", read, hashHandler.GetHash)
+            hash.GET("/:key/len", read, hashHandler.CountFields)
+            hash.PUT("/:key/fields", write, hashHandler.SetFields)
+            hash.GET("/:key/fields/:field", read, hashHandler.GetField)
+            hash.PUT("/:key/fields/:field", write, hashHandler.SetField)
+            hash.DELETE("/:key/fields/:field", write, hashHandler.DeleteField)
+            hash.POST("/:key/fields/:field/incr", write, hashHandler.IncrementField)
+        }
+
+        // List routes
+        list := api.Group("/list")
+        {
+            list.GET("/:key", read, listHandler.GetRange)
+            list.GET("/:key/len", read, listHandler.Length)
+            list.POST("/:key/push", write, listHandler.Push)
+            list.POST("/:key/pop", write, listHandler.Pop)
+            list.PUT("/:key/trim", write, listHandler.Trim)
+        }
+
+        // Set routes
+        set := api.Group("/set")
+        {
+            set.POST("/union", read, setHandler.Union)
+            set.POST("/inter", read, setHandler.Intersection)
+            set.GET("/:key/members", read, setHandler.ScanMembers)
+            set.POST("/:key/members", write, setHandler.AddMembers)
+            set.DELETE("/:key/members", write, setHandler.RemoveMembers)
+            set.GET("/:key/members/:member", read, setHandler.IsMember)
+            set.DELETE("/:key/members/:member", write, setHandler.RemoveMember)
+            set.GET("/:key/card", read, setHandler.Cardinality)
+        }
+
+        // Sorted set routes
+        zset := api.Group("/zset")
+        {
+            zset.POST("/:key/members", write, zsetHandler.AddMembers)
+            zset.GET("/:key/members/:member", read, zsetHandler.GetRank)
+            zset.DELETE("/:key/members/:member", write, zsetHandler.RemoveMember)
+            zset.POST("/:key/members/:member/incr", write, zsetHandler.IncrementScore)
+            zset.GET("/:key/range", read, zsetHandler.RangeByRank)
+            zset.GET("/:key/range/score", read, zsetHandler.RangeByScore)
+            zset.GET("/:key/card", read, zsetHandler.Cardinality)
+        }
+
+        // HyperLogLog routes
+        hll := api.Group("/hll")
+        {
+            hll.POST("/:key", write, hllHandler.Add)
+            hll.GET("/:key", read, hllHandler.Count)
+            hll.POST("/:key/merge", write, hllHandler.Merge)
         }
+
+        // Bloom filter routes
+        bloom := api.Group("/bloom")
+        {
+            bloom.PUT("/:key", write, bloomHandler.Reserve)
+            bloom.GET("/:key", read, bloomHandler.Info)
+            bloom.DELETE("/:key", write, bloomHandler.Delete)
+            bloom.POST("/:key/items", write, bloomHandler.AddItems)
+            bloom.POST("/:key/items/exists", read, bloomHandler.CheckItems)
+            bloom.GET("/:key/items/:item", read, bloomHandler.CheckItem)
+        }
+
+        // Scheduled job routes. Consuming claims jobs, so it needs the write scope.
+        schedule := api.Group("/schedule")
+        {
+            schedule.POST("", write, scheduleHandler.Schedule)
+            schedule.POST("/:topic/poll", write, scheduleHandler.Poll)
+            schedule.GET("/:topic/events", write, scheduleHandler.Events)
+            schedule.GET("/:topic/jobs/:id", read, scheduleHandler.GetJob)
+            schedule.DELETE("/:topic/jobs/:id", write, scheduleHandler.CancelJob)
+            schedule.POST("/:topic/jobs/:id/ack", write, scheduleHandler.AckJob)
+            schedule.POST("/:topic/jobs/:id/retry", write, scheduleHandler.RetryJob)
+        }
+
+        // Pub/sub routes. Subscriptions are Server-Sent Events streams.
+        pubsub := api.Group("/pubsub")
+        {
+            pubsub.GET("", read, pubsubHandler.Subscribe)
+            pubsub.POST("/:channel", write, pubsubHandler.Publish)
+        }
+
+        // Rate limit oracle routes
+        api.POST("/ratelimit/:bucket/take", write, rateLimitHandler.Take)
+
+        // Idempotency key routes
+        idempotencyKeys := api.Group("/idempotency")
+        {
+            idempotencyKeys.POST("/:id/claim", write, idempotencyHandler.Claim)
+            idempotencyKeys.PUT("/:id/complete", write, idempotencyHandler.Complete)
+            idempotencyKeys.DELETE("/:id", write, idempotencyHandler.Release)
+            idempotencyKeys.GET("/:id", read, idempotencyHandler.Get)
+        }
+
+        // Session routes
+        sessions := api.Group("/sessions")
+        {
+            sessions.POST("", write, sessionHandler.CreateSession)
+            // Per-user routes act on the sessions of any user, so they are admin-only
+            sessions.GET("", admin, sessionHandler.ListUserSessions)
+            sessions.DELETE("", admin, sessionHandler.DeleteUserSessions)
+            sessions.GET("/:id", read, sessionHandler.GetSession)
+            sessions.PATCH("/:id", write, sessionHandler.UpdateSession)
+            sessions.POST("/:id/touch", write, sessionHandler.TouchSession)
+            sessions.DELETE("/:id", write, sessionHandler.DeleteSession)
+        }
+
+        // Diagnostics routes
+        if cfg.HotKeys.Enabled {
+            tracker := hotkeys.New(cfg.HotKeys, logger)
+            cacheInstance.AddObserver(tracker)
+            adminHandler := handlers.NewAdminHandler(tracker, logger)
+
+            diagnostics := api.Group("/admin", admin)
+            {
+                diagnostics.GET("/hotkeys", adminHandler.HotKeys)
+                diagnostics.GET("/bigkeys", adminHandler.BigKeys)
+            }
+        }
+    }
+
+    // Webhook delivery of scheduled jobs
+    dispatcher, err := scheduler.NewDispatcher(cacheInstance, cfg.Scheduler, logger)
+    if err != nil {
+        logger.Fatal("Failed to configure scheduled job webhooks", zap.Error(err))
     }
+    dispatcher.Start()
 
     // Configure HTTP server
     server := &http.Server{
@@ -117,11 +325,38 @@ func main() {
         WriteTimeout: cfg.Server.WriteTimeout,
         IdleTimeout:  cfg.Server.IdleTimeout,
     }
+    // Shutdown does not cancel the context of the event streams
+    server.RegisterOnShutdown(scheduleHandler.Shutdown)
+    server.RegisterOnShutdown(pubsubHandler.Shutdown)
+
+    if cfg.Server.TLS.Enabled {
+        tlsConfig, err := tlsutil.ServerConfig(tlsutil.ServerOptions{
+            CertFile:       cfg.Server.TLS.CertFile,
+            KeyFile:        cfg.Server.TLS.KeyFile,
+            ClientCAFile:   cfg.Server.TLS.ClientCAFile,
+            ClientAuth:     cfg.Server.TLS.ClientAuth,
+            ReloadInterval: cfg.Server.TLS.ReloadInterval,
+        }, logger)
+        if err != nil {
+            logger.Fatal("Failed to configure TLS", zap.Error(err))
+        }
+        server.TLSConfig = tlsConfig
+    }
 
     // Start server in goroutine
     go func() {
-        logger.Info("Server starting", zap.String("address", server.Addr))
-        if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+        logger.Info("Server starting",
+            zap.String("address", server.Addr),
+            zap.Bool("tls", server.TLSConfig != nil))
+
+        var err error
+        if server.TLSConfig != nil {
+            // Certificates come from TLSConfig.GetCertificate
+            err = server.ListenAndServeTLS("", "")
+        } else {
+            err = server.ListenAndServe()
+        }
+        if err != nil && err != http.ErrServerClosed {
             logger.Fatal("Failed to start server", zap.Error(err))
         }
     }()
@@ -130,16 +365,34 @@ func main() {
     quit := make(chan os.Signal, 1)
     signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
     <-quit
+
+    // Fail readiness first so load balancers stop routing new requests
+    // before the listener is closed
+    healthHandler.SetDraining(true)
+    logger.Info("Draining server...", zap.Duration("delay", cfg.Server.ShutdownDelay))
+    time.Sleep(cfg.Server.ShutdownDelay)
     logger.Info("Shutting down server...")
 
     // Graceful shutdown
-    ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+    ctx, cancel = context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
     defer cancel()
 
     if err := server.Shutdown(ctx); err != nil {
         logger.Error("Server forced to shutdown", zap.Error(err))
     }
 
+    // Jobs claimed by a stopped delivery are redelivered when their lease expires
+    dispatcher.Stop()
+
+    // No more requests arrive; send the writes still in the write-behind queue
+    if err := cacheInstance.Flush(ctx); err != nil {
+        logger.Error("Failed to flush queued writes", zap.Error(err))
+    }
+
+    if err := shutdownTracing(ctx); err != nil {
+        logger.Error("Failed to flush traces", zap.Error(err))
+    }
+
     logger.Info("Server exited")
 }
 
@@ -0,0 +1,35 @@
+package cache
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "distributed-cache/pkg/models"
+)
+
+var (
+    // ErrBloomExists is returned when reserving a filter that already exists
+    ErrBloomExists = errors.New("bloom filter already exists")
+    // ErrBloomNotFound is returned when adding to or checking a missing filter
+    ErrBloomNotFound = errors.New("bloom filter not found")
+    // ErrInvalidBloom is returned for a capacity or error rate that cannot be used
+    ErrInvalidBloom = errors.New("invalid bloom filter parameters")
+)
+
+// BloomStore defines Bloom filter operations. A filter answers whether an
+// item was added with no false negatives and a false-positive rate close to
+// the one it was reserved with, as long as it holds at most its capacity.
+type BloomStore interface {
+    // BloomReserve creates a filter sized for capacity items at errorRate.
+    // A positive ttl sets the filter expiration.
+    BloomReserve(ctx context.Context, key string, capacity int64, errorRate float64, ttl time.Duration) (*models.BloomFilter, error)
+    // BloomAdd adds items and reports, for each one, whether it was new
+    BloomAdd(ctx context.Context, key string, items []string) ([]bool, error)
+    // BloomExists reports, for each item, whether it may have been added
+    BloomExists(ctx context.Context, key string, items []string) ([]bool, error)
+    // BloomInfo returns the filter, nil if it does not exist
+    BloomInfo(ctx context.Context, key string) (*models.BloomFilter, error)
+    // BloomDelete removes the filter and reports whether it existed
+    BloomDelete(ctx context.Context, key string) (bool, error)
+}
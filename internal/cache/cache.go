@@ -14,6 +14,12 @@ type Cache interface {
     Delete(ctx context.Context, key string) error
     Exists(ctx context.Context, key string) (bool, error)
 
+    // Conditional operations
+    SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) // Only if the key is missing
+    SetXX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) // Only if the key exists
+    GetSet(ctx context.Context, key string, value interface{}, ttl time.Duration) (*models.CacheItem, error)
+    GetDel(ctx context.Context, key string) (*models.CacheItem, error)
+
     // Batch operations
     SetMultiple(ctx context.Context, items map[string]*models.CacheItem) error
     GetMultiple(ctx context.Context, keys []string) (map[string]*models.CacheItem, error)
@@ -30,25 +36,69 @@ type Cache interface {
 
     // Statistics
     Size(ctx context.Context) (int64, error)
-    Info(ctx context.Context) (map[string]interface{}, error)
+    Info(ctx context.Context, sections ...string) (*ServerInfo, error)
+
+    // Statistics
+    Stats() StatsSnapshot
 
     // Connection
     Ping(ctx context.Context) error
+    Status() Status
     Close() error
 }
 
+// Status runtime state of the cache backend
+type Status struct {
+    Pool        PoolStats          `json:"pool"`
+    Breaker     *BreakerStatus     `json:"breaker,omitempty"`      // nil when the circuit breaker is disabled
+    Fallback    *FallbackStatus    `json:"fallback,omitempty"`     // nil when the local fallback is disabled
+    WriteBehind *WriteBehindStatus `json:"write_behind,omitempty"` // nil when write-behind is disabled
+}
+
+// PoolStats connection pool statistics
+type PoolStats struct {
+    Hits       uint32 `json:"hits"`     // Times a free connection was found in the pool
+    Misses     uint32 `json:"misses"`   // Times a free connection was not found in the pool
+    Timeouts   uint32 `json:"timeouts"` // Times a wait for a connection timed out
+    TotalConns uint32 `json:"total_conns"`
+    IdleConns  uint32 `json:"idle_conns"`
+    StaleConns uint32 `json:"stale_conns"` // Stale connections removed from the pool
+    PoolSize   int    `json:"pool_size"`
+}
+
 // CacheConfig configuration for the cache
 type CacheConfig struct {
-    Addresses    []string      `mapstructure:"addresses"`
-    Password     string        `mapstructure:"password"`
-    Database     int           `mapstructure:"database"`
-    MaxRetries   int           `mapstructure:"max_retries"`
-    PoolSize     int           `mapstructure:"pool_size"`
-    MinIdleConns int           `mapstructure:"min_idle_conns"`
-    DialTimeout  time.Duration `mapstructure:"dial_timeout"`
-    ReadTimeout  time.Duration `mapstructure:"read_timeout"`
-    WriteTimeout time.Duration `mapstructure:"write_timeout"`
-    PoolTimeout  time.Duration `mapstructure:"pool_timeout"`
+    Addresses    []string          `mapstructure:"addresses"`
+    Password     string            `mapstructure:"password"`
+    Database     int               `mapstructure:"database"`
+    MaxRetries   int               `mapstructure:"max_retries"`
+    PoolSize     int               `mapstructure:"pool_size"`
+    MinIdleConns int               `mapstructure:"min_idle_conns"`
+    DialTimeout  time.Duration     `mapstructure:"dial_timeout"`
+    ReadTimeout  time.Duration     `mapstructure:"read_timeout"`
+    WriteTimeout time.Duration     `mapstructure:"write_timeout"`
+    PoolTimeout  time.Duration     `mapstructure:"pool_timeout"`
+    TLS          TLSConfig         `mapstructure:"tls"`
+    Stats        StatsConfig       `mapstructure:"stats"`
+    Breaker      BreakerConfig     `mapstructure:"circuit_breaker"`
+    Fallback     FallbackConfig    `mapstructure:"fallback"`
+    WriteBehind  WriteBehindConfig `mapstructure:"write_behind"`
+
+    // StartDegraded lets NewRedisCache succeed when Redis is unreachable;
+    // the connection is retried by later commands and /readyz fails until
+    // it succeeds. Off by default so a misconfigured address fails at startup.
+    StartDegraded bool `mapstructure:"start_degraded"`
+}
+
+// TLSConfig TLS configuration for Redis connections
+type TLSConfig struct {
+    Enabled            bool          `mapstructure:"enabled"`
+    CAFile             string        `mapstructure:"ca_file"`   // Custom CA, system roots if empty
+    CertFile           string        `mapstructure:"cert_file"` // Client certificate for mTLS
+    KeyFile            string        `mapstructure:"key_file"`
+    ServerName         string        `mapstructure:"server_name"`
+    InsecureSkipVerify bool          `mapstructure:"insecure_skip_verify"`
+    ReloadInterval     time.Duration `mapstructure:"reload_interval"`
 }
 
 // DefaultCacheConfig returns the default configuration
@@ -64,5 +114,23 @@ func DefaultCacheConfig() *CacheConfig {
         ReadTimeout:  3 * time.Second,
         WriteTimeout: 3 * time.Second,
         PoolTimeout:  4 * time.Second,
+        Stats:        StatsConfig{MaxNamespaces: 100},
+        Breaker: BreakerConfig{
+            Enabled:          true,
+            FailureThreshold: 5,
+            OpenTimeout:      10 * time.Second,
+            WritePolicy:      WritePolicyFail,
+            BufferSize:       1000,
+        },
+        Fallback: FallbackConfig{
+            MaxItems:          10000,
+            MaxValueSize:      64 * 1024,
+            ReconcileInterval: 5 * time.Second,
+        },
+        WriteBehind: WriteBehindConfig{
+            QueueSize:     10000,
+            BatchSize:     500,
+            FlushInterval: 50 * time.Millisecond,
+        },
     }
 }
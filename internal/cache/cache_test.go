@@ -20,8 +20,8 @@ func setupTestCache(t *testing.T) Cache {
     cache, err := NewRedisCache(config, logger)
     require.NoError(t, err)
 
-    // Clear the cache for tests
-    err = cache.Clear(context.Background())
+    // Clear keeps the internal keys, so the tests start from an empty database
+    err = cache.client.FlushDB(context.Background()).Err()
     require.NoError(t, err)
 
     return cache
@@ -285,11 +285,17 @@ func TestRedisCache_Info(t *testing.T) {
     ctx := context.Background()
 
     info, err := cache.Info(ctx)
-    assert.NoError(t, err)
-    assert.NotEmpty(t, info)
+    require.NoError(t, err)
+    assert.NotEmpty(t, info.Sections)
 
     // Verificar que contiene información típica de Redis
-    assert.Contains(t, info, "redis_version")
+    assert.NotEmpty(t, info.String("server", "redis_version"))
+
+    // Solicitar una única sección
+    info, err = cache.Info(ctx, "memory")
+    require.NoError(t, err)
+    assert.Contains(t, info.Sections, "memory")
+    assert.NotContains(t, info.Sections, "server")
 }
 
 func TestRedisCache_Clear(t *testing.T) {
@@ -369,3 +375,78 @@ func BenchmarkRedisCache_Get(b *testing.B) {
         }
     })
 }
+
+func TestRedisCache_ConditionalWrites(t *testing.T) {
+    cache := setupTestCache(t)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    // SetNX solo crea la clave una vez
+    stored, err := cache.SetNX(ctx, "dedupe:msg-1", "first", time.Hour)
+    require.NoError(t, err)
+    assert.True(t, stored)
+
+    stored, err = cache.SetNX(ctx, "dedupe:msg-1", "second", time.Hour)
+    require.NoError(t, err)
+    assert.False(t, stored)
+
+    item, err := cache.Get(ctx, "dedupe:msg-1")
+    require.NoError(t, err)
+    assert.Equal(t, "first", item.Value)
+
+    // SetXX solo sobrescribe claves existentes
+    stored, err = cache.SetXX(ctx, "missing", "value", time.Hour)
+    require.NoError(t, err)
+    assert.False(t, stored)
+
+    exists, err := cache.Exists(ctx, "missing")
+    require.NoError(t, err)
+    assert.False(t, exists)
+
+    stored, err = cache.SetXX(ctx, "dedupe:msg-1", "updated", time.Minute)
+    require.NoError(t, err)
+    assert.True(t, stored)
+
+    // GetSet devuelve el valor anterior y aplica el nuevo TTL
+    previous, err := cache.GetSet(ctx, "dedupe:msg-1", "latest", time.Hour)
+    require.NoError(t, err)
+    require.NotNil(t, previous)
+    assert.Equal(t, "updated", previous.Value)
+
+    ttl, err := cache.TTL(ctx, "dedupe:msg-1")
+    require.NoError(t, err)
+    assert.Greater(t, ttl, time.Minute)
+
+    previous, err = cache.GetSet(ctx, "fresh", "value", time.Hour)
+    require.NoError(t, err)
+    assert.Nil(t, previous)
+
+    // GetDel devuelve el elemento eliminado
+    deleted, err := cache.GetDel(ctx, "dedupe:msg-1")
+    require.NoError(t, err)
+    require.NotNil(t, deleted)
+    assert.Equal(t, "latest", deleted.Value)
+
+    deleted, err = cache.GetDel(ctx, "dedupe:msg-1")
+    require.NoError(t, err)
+    assert.Nil(t, deleted)
+}
+
+func TestRedisCache_ConditionalWritesWriteBehind(t *testing.T) {
+    rc := setupWriteBehindCache(t, WriteBehindConfig{QueueSize: 100, BatchSize: 100, FlushInterval: time.Hour})
+    defer rc.Close()
+    ctx := context.Background()
+
+    // Una escritura encolada cuenta como clave existente
+    require.NoError(t, rc.Set(ctx, "wb:nx", "queued", time.Minute))
+    stored, err := rc.SetNX(ctx, "wb:nx", "other", time.Minute)
+    require.NoError(t, err)
+    assert.False(t, stored)
+
+    require.NoError(t, rc.Set(ctx, "wb:getdel", "queued", time.Minute))
+    deleted, err := rc.GetDel(ctx, "wb:getdel")
+    require.NoError(t, err)
+    require.NotNil(t, deleted)
+    assert.Equal(t, "queued", deleted.Value)
+}
@@ -0,0 +1,462 @@
+package cache
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+)
+
+// ErrCircuitOpen is returned without contacting Redis while the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Write policies applied while the circuit breaker is open
+const (
+    WritePolicyFail   = "fail"   // Return ErrCircuitOpen
+    WritePolicyDrop   = "drop"   // Discard the write and report success
+    WritePolicyBuffer = "buffer" // Queue the write and replay it once Redis recovers
+)
+
+// Circuit breaker states
+const (
+    BreakerClosed   = "closed"
+    BreakerOpen     = "open"
+    BreakerHalfOpen = "half_open"
+)
+
+// BreakerConfig configuration of the circuit breaker in front of Redis
+type BreakerConfig struct {
+    Enabled          bool          `mapstructure:"enabled"`
+    FailureThreshold int           `mapstructure:"failure_threshold"` // Consecutive failures that open the circuit
+    OpenTimeout      time.Duration `mapstructure:"open_timeout"`      // Time open before a probe command is let through
+    MissOnOpen       bool          `mapstructure:"miss_on_open"`      // Reads return a miss instead of ErrCircuitOpen
+    WritePolicy      string        `mapstructure:"write_policy"`      // fail, drop or buffer
+    BufferSize       int           `mapstructure:"buffer_size"`       // Writes kept by the buffer policy
+}
+
+// BreakerStatus state of the circuit breaker
+type BreakerStatus struct {
+    State          string    `json:"state"`
+    Failures       int       `json:"failures"` // Consecutive failures
+    Since          time.Time `json:"since"`    // Last state change
+    Rejected       uint64    `json:"rejected"` // Commands rejected while open
+    DroppedWrites  uint64    `json:"dropped_writes"`
+    BufferedWrites int       `json:"buffered_writes"`
+}
+
+// circuitBreaker is a redis.Hook that rejects commands with ErrCircuitOpen
+// after FailureThreshold consecutive failures. Once OpenTimeout has elapsed a
+// single probe command is let through (half-open): success closes the circuit,
+// failure opens it again. If onRecover is set it runs between a successful
+// probe and the close, while every other command is still rejected.
+type circuitBreaker struct {
+    cfg    BreakerConfig
+    logger *zap.Logger
+
+    mu         sync.Mutex
+    state      string
+    failures   int
+    since      time.Time
+    generation uint64 // Incremented on every state change to discard stale results
+    probing    bool
+    rejected   uint64
+    onRecover  func(ctx context.Context) error // Commands issued with ctx bypass the breaker
+    onClose    func()
+}
+
+// breakerCallKey context key carrying the generation a command was admitted in
+type breakerCallKey struct{}
+
+// breakerBypassKey context key marking the commands issued by onRecover
+type breakerBypassKey struct{}
+
+func newCircuitBreaker(cfg BreakerConfig, logger *zap.Logger) *circuitBreaker {
+    if cfg.FailureThreshold <= 0 {
+        cfg.FailureThreshold = 5
+    }
+    if cfg.OpenTimeout <= 0 {
+        cfg.OpenTimeout = 10 * time.Second
+    }
+    return &circuitBreaker{
+        cfg:    cfg,
+        logger: logger,
+        state:  BreakerClosed,
+        since:  time.Now(),
+    }
+}
+
+// allow admits a command, returning the generation to report its result under
+func (b *circuitBreaker) allow() (uint64, error) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    switch b.state {
+    case BreakerOpen:
+        if time.Since(b.since) < b.cfg.OpenTimeout {
+            b.rejected++
+            return 0, ErrCircuitOpen
+        }
+        b.transition(BreakerHalfOpen)
+        b.probing = true
+    case BreakerHalfOpen:
+        if b.probing {
+            b.rejected++
+            return 0, ErrCircuitOpen
+        }
+        b.probing = true
+    }
+    return b.generation, nil
+}
+
+// record reports the result of a command admitted in the given generation
+func (b *circuitBreaker) record(generation uint64, err error) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    if generation != b.generation {
+        return
+    }
+
+    switch {
+    case isBackendFailure(err):
+        b.failures++
+        if b.state == BreakerHalfOpen || b.failures >= b.cfg.FailureThreshold {
+            b.transition(BreakerOpen)
+        }
+    case err == nil || err == redis.Nil || isRedisReply(err):
+        // Redis answered, even if with an error reply
+        b.failures = 0
+        if b.state == BreakerHalfOpen {
+            if b.onRecover != nil {
+                // Keep the probe slot taken so other commands wait for recovery
+                go b.recover(b.generation)
+                return
+            }
+            b.transition(BreakerClosed)
+        }
+    default:
+        // Neither outcome (caller canceled): free the probe slot
+        b.probing = false
+    }
+}
+
+// transition changes state; callers hold b.mu
+func (b *circuitBreaker) transition(state string) {
+    previous := b.state
+    b.state = state
+    b.since = time.Now()
+    b.generation++
+    b.probing = false
+
+    switch state {
+    case BreakerOpen:
+        b.logger.Warn("circuit breaker opened",
+            zap.String("previous", previous),
+            zap.Int("failures", b.failures),
+            zap.Duration("open_timeout", b.cfg.OpenTimeout))
+    case BreakerClosed:
+        b.failures = 0
+        b.logger.Info("circuit breaker closed")
+        if b.onClose != nil {
+            go b.onClose()
+        }
+    }
+}
+
+// recover runs onRecover and closes the circuit, or opens it again if
+// recovery failed or the state changed meanwhile
+func (b *circuitBreaker) recover(generation uint64) {
+    err := b.onRecover(context.WithValue(context.Background(), breakerBypassKey{}, true))
+
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    if generation != b.generation {
+        return
+    }
+    if err != nil {
+        b.transition(BreakerOpen)
+        return
+    }
+    b.transition(BreakerClosed)
+}
+
+// trip opens the circuit, e.g. when Redis is unreachable at startup
+func (b *circuitBreaker) trip() {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    if b.state != BreakerOpen {
+        b.transition(BreakerOpen)
+    }
+}
+
+// status returns the breaker counters; the write counters are filled by the cache
+func (b *circuitBreaker) status() BreakerStatus {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    // Report an elapsed open timeout as half-open even before the next command
+    state := b.state
+    if state == BreakerOpen && time.Since(b.since) >= b.cfg.OpenTimeout {
+        state = BreakerHalfOpen
+    }
+    return BreakerStatus{
+        State:    state,
+        Failures: b.failures,
+        Since:    b.since,
+        Rejected: b.rejected,
+    }
+}
+
+// BeforeProcess implements redis.Hook
+func (b *circuitBreaker) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+    if ctx.Value(breakerBypassKey{}) != nil {
+        return ctx, nil
+    }
+    generation, err := b.allow()
+    if err != nil {
+        return ctx, err
+    }
+    return context.WithValue(ctx, breakerCallKey{}, generation), nil
+}
+
+// AfterProcess implements redis.Hook
+func (b *circuitBreaker) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+    if generation, ok := ctx.Value(breakerCallKey{}).(uint64); ok {
+        b.record(generation, cmd.Err())
+    }
+    return nil
+}
+
+// BeforeProcessPipeline implements redis.Hook
+func (b *circuitBreaker) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+    return b.BeforeProcess(ctx, nil)
+}
+
+// AfterProcessPipeline implements redis.Hook
+func (b *circuitBreaker) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+    generation, ok := ctx.Value(breakerCallKey{}).(uint64)
+    if !ok {
+        return nil
+    }
+
+    var err error
+    for _, cmd := range cmds {
+        if cmdErr := cmd.Err(); cmdErr != nil && cmdErr != redis.Nil {
+            err = cmdErr
+            if isBackendFailure(cmdErr) {
+                break
+            }
+        }
+    }
+    b.record(generation, err)
+    return nil
+}
+
+// isRedisReply reports whether err is an error reply sent by Redis
+func isRedisReply(err error) bool {
+    var reply redis.Error
+    return errors.As(err, &reply)
+}
+
+// isBackendFailure reports whether err means Redis is unavailable: network
+// errors, timeouts, and replies of a server that cannot serve requests.
+// Error replies caused by the command itself (WRONGTYPE...) are not failures.
+func isBackendFailure(err error) bool {
+    switch {
+    case err == nil, err == redis.Nil, err == redis.ErrClosed:
+        return false
+    case errors.Is(err, ErrCircuitOpen), errors.Is(err, context.Canceled):
+        return false
+    case isRedisReply(err):
+        msg := err.Error()
+        for _, prefix := range []string{"LOADING", "READONLY", "MASTERDOWN", "CLUSTERDOWN", "TRYAGAIN"} {
+            if strings.HasPrefix(msg, prefix) {
+                return true
+            }
+        }
+        return false
+    default:
+        return true
+    }
+}
+
+// bufferedWrite a write queued while the circuit was open. apply receives the
+// time spent in the buffer so TTLs can be shortened accordingly.
+type bufferedWrite struct {
+    key      string
+    queuedAt time.Time
+    apply    func(ctx context.Context, elapsed time.Duration) error
+}
+
+// writeBuffer bounded FIFO of writes replayed once the circuit closes
+type writeBuffer struct {
+    mu     sync.Mutex
+    size   int
+    writes []bufferedWrite
+}
+
+func newWriteBuffer(size int) *writeBuffer {
+    if size <= 0 {
+        size = 1000
+    }
+    return &writeBuffer{size: size}
+}
+
+// push queues all the writes or none; it returns false if they do not fit
+func (wb *writeBuffer) push(writes ...bufferedWrite) bool {
+    wb.mu.Lock()
+    defer wb.mu.Unlock()
+
+    if len(wb.writes)+len(writes) > wb.size {
+        return false
+    }
+    wb.writes = append(wb.writes, writes...)
+    return true
+}
+
+// take removes and returns every queued write
+func (wb *writeBuffer) take() []bufferedWrite {
+    wb.mu.Lock()
+    defer wb.mu.Unlock()
+
+    writes := wb.writes
+    wb.writes = nil
+    return writes
+}
+
+// requeue puts writes that could not be replayed back at the front,
+// returning how many newer writes were discarded to make room
+func (wb *writeBuffer) requeue(writes []bufferedWrite) int {
+    wb.mu.Lock()
+    defer wb.mu.Unlock()
+
+    wb.writes = append(writes, wb.writes...)
+    excess := len(wb.writes) - wb.size
+    if excess <= 0 {
+        return 0
+    }
+    wb.writes = wb.writes[:wb.size]
+    return excess
+}
+
+func (wb *writeBuffer) len() int {
+    wb.mu.Lock()
+    defer wb.mu.Unlock()
+    return len(wb.writes)
+}
+
+// missOnOpen reports whether a read rejected by the circuit breaker should be
+// served as a miss
+func (rc *RedisCache) missOnOpen(op *operation, err error) bool {
+    if !errors.Is(err, ErrCircuitOpen) || !rc.config.Breaker.MissOnOpen {
+        return false
+    }
+    op.logger.Debug("circuit breaker open, serving miss")
+    return true
+}
+
+// degradeWrite applies the write policy to writes rejected by the circuit
+// breaker. It returns nil if they were dropped or buffered.
+func (rc *RedisCache) degradeWrite(op *operation, err error, writes ...bufferedWrite) error {
+    switch rc.config.Breaker.WritePolicy {
+    case WritePolicyDrop:
+        rc.droppedWrites.Add(uint64(len(writes)))
+        op.logger.Debug("circuit breaker open, write dropped", zap.Int("count", len(writes)))
+        return nil
+    case WritePolicyBuffer:
+        if rc.writes.push(writes...) {
+            op.logger.Debug("circuit breaker open, write buffered", zap.Int("count", len(writes)))
+            return nil
+        }
+        op.logger.Warn("circuit breaker open and write buffer full", zap.Int("count", len(writes)))
+    }
+    return err
+}
+
+// bufferedSet queues a SET; the TTL is reduced by the time spent in the buffer
+func (rc *RedisCache) bufferedSet(key string, data []byte, ttl time.Duration) bufferedWrite {
+    return bufferedWrite{
+        key:      key,
+        queuedAt: time.Now(),
+        apply: func(ctx context.Context, elapsed time.Duration) error {
+            if ttl <= 0 {
+                return rc.client.Set(ctx, key, data, ttl).Err()
+            }
+            if elapsed >= ttl {
+                // The value would already have expired; drop whatever Redis holds
+                return rc.client.Del(ctx, key).Err()
+            }
+            return rc.client.Set(ctx, key, data, ttl-elapsed).Err()
+        },
+    }
+}
+
+// bufferedDelete queues a DEL
+func (rc *RedisCache) bufferedDelete(key string) bufferedWrite {
+    return bufferedWrite{
+        key:      key,
+        queuedAt: time.Now(),
+        apply: func(ctx context.Context, _ time.Duration) error {
+            return rc.client.Del(ctx, key).Err()
+        },
+    }
+}
+
+// bufferedExpire queues an EXPIRE; the TTL is reduced by the time spent in the buffer
+func (rc *RedisCache) bufferedExpire(key string, ttl time.Duration) bufferedWrite {
+    return bufferedWrite{
+        key:      key,
+        queuedAt: time.Now(),
+        apply: func(ctx context.Context, elapsed time.Duration) error {
+            if elapsed >= ttl {
+                return rc.client.Del(ctx, key).Err()
+            }
+            return rc.client.Expire(ctx, key, ttl-elapsed).Err()
+        },
+    }
+}
+
+// replayWrites applies the buffered writes, including those buffered while it
+// runs. It is called before the circuit closes, so that the writes reach Redis
+// ahead of any newer write to the same keys. If Redis fails again the
+// remaining writes go back to the buffer and the error is returned.
+func (rc *RedisCache) replayWrites(ctx context.Context) error {
+    replayed, failed := 0, 0
+    defer func() {
+        if replayed > 0 || failed > 0 {
+            rc.logger.Info("buffered writes replayed", zap.Int("replayed", replayed), zap.Int("failed", failed))
+        }
+    }()
+
+    for {
+        writes := rc.writes.take()
+        if len(writes) == 0 {
+            return nil
+        }
+
+        for i, write := range writes {
+            err := write.apply(ctx, time.Since(write.queuedAt))
+            if err == nil {
+                replayed++
+                continue
+            }
+            if errors.Is(err, ErrCircuitOpen) || isBackendFailure(err) {
+                dropped := rc.writes.requeue(writes[i:])
+                rc.droppedWrites.Add(uint64(dropped))
+                rc.logger.Warn("buffered write replay interrupted",
+                    zap.Int("replayed", replayed),
+                    zap.Int("pending", len(writes)-i),
+                    zap.Error(err))
+                return err
+            }
+            failed++
+            rc.logger.Warn("failed to replay buffered write", zap.String("key", write.key), zap.Error(err))
+        }
+    }
+}
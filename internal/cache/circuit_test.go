@@ -0,0 +1,206 @@
+package cache
+
+import (
+    "context"
+    "errors"
+    "net"
+    "testing"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap/zaptest"
+)
+
+var errNetwork = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+func TestCircuitBreaker_StateMachine(t *testing.T) {
+    b := newCircuitBreaker(BreakerConfig{FailureThreshold: 3, OpenTimeout: time.Hour}, zaptest.NewLogger(t))
+
+    // Failures below the threshold, then a success, keep it closed
+    for i := 0; i < 2; i++ {
+        gen, err := b.allow()
+        require.NoError(t, err)
+        b.record(gen, errNetwork)
+    }
+    gen, _ := b.allow()
+    b.record(gen, nil)
+    assert.Equal(t, BreakerClosed, b.status().State)
+    assert.Equal(t, 0, b.status().Failures)
+
+    // Error replies and misses are not failures
+    gen, _ = b.allow()
+    b.record(gen, redis.Nil)
+    gen, _ = b.allow()
+    b.record(gen, context.Canceled)
+    assert.Equal(t, 0, b.status().Failures)
+
+    for i := 0; i < 3; i++ {
+        gen, err := b.allow()
+        require.NoError(t, err)
+        b.record(gen, errNetwork)
+    }
+    assert.Equal(t, BreakerOpen, b.status().State)
+
+    _, err := b.allow()
+    assert.ErrorIs(t, err, ErrCircuitOpen)
+    assert.Equal(t, uint64(1), b.status().Rejected)
+
+    // After the open timeout a single probe goes through
+    b.mu.Lock()
+    b.since = time.Now().Add(-2 * time.Hour)
+    b.mu.Unlock()
+    assert.Equal(t, BreakerHalfOpen, b.status().State)
+
+    probe, err := b.allow()
+    require.NoError(t, err)
+    _, err = b.allow()
+    assert.ErrorIs(t, err, ErrCircuitOpen, "only one probe while half-open")
+
+    // A failed probe opens the circuit again
+    b.record(probe, errNetwork)
+    assert.Equal(t, BreakerOpen, b.status().State)
+
+    b.mu.Lock()
+    b.since = time.Now().Add(-2 * time.Hour)
+    b.mu.Unlock()
+    probe, err = b.allow()
+    require.NoError(t, err)
+    b.record(probe, nil)
+    assert.Equal(t, BreakerClosed, b.status().State)
+}
+
+func TestCircuitBreaker_IgnoresStaleResults(t *testing.T) {
+    b := newCircuitBreaker(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Hour}, zaptest.NewLogger(t))
+
+    stale, err := b.allow()
+    require.NoError(t, err)
+
+    gen, _ := b.allow()
+    b.record(gen, errNetwork)
+    require.Equal(t, BreakerOpen, b.status().State)
+
+    // A command admitted before the circuit opened cannot close it
+    b.record(stale, nil)
+    assert.Equal(t, BreakerOpen, b.status().State)
+}
+
+func TestIsBackendFailure(t *testing.T) {
+    assert.False(t, isBackendFailure(nil))
+    assert.False(t, isBackendFailure(redis.Nil))
+    assert.False(t, isBackendFailure(ErrCircuitOpen))
+    assert.False(t, isBackendFailure(context.Canceled))
+    assert.True(t, isBackendFailure(errNetwork))
+    assert.True(t, isBackendFailure(context.DeadlineExceeded))
+}
+
+// openBreaker forces the circuit of rc open
+func openBreaker(rc *RedisCache) {
+    rc.breaker.mu.Lock()
+    rc.breaker.transition(BreakerOpen)
+    rc.breaker.mu.Unlock()
+}
+
+// expireOpenTimeout lets the next command through as the half-open probe
+func expireOpenTimeout(rc *RedisCache) {
+    rc.breaker.mu.Lock()
+    rc.breaker.since = time.Now().Add(-time.Hour)
+    rc.breaker.mu.Unlock()
+}
+
+func setupBreakerCache(t *testing.T, cfg BreakerConfig) *RedisCache {
+    config := DefaultCacheConfig()
+    config.Breaker = cfg
+    config.Breaker.Enabled = true
+
+    rc, err := NewRedisCache(config, zaptest.NewLogger(t))
+    require.NoError(t, err)
+    require.NoError(t, rc.Clear(context.Background()))
+    return rc
+}
+
+func TestRedisCache_BreakerFailFast(t *testing.T) {
+    rc := setupBreakerCache(t, BreakerConfig{WritePolicy: WritePolicyFail})
+    defer rc.Close()
+    ctx := context.Background()
+
+    openBreaker(rc)
+
+    _, err := rc.Get(ctx, "breaker:key")
+    assert.ErrorIs(t, err, ErrCircuitOpen)
+    err = rc.Set(ctx, "breaker:key", "value", time.Minute)
+    assert.ErrorIs(t, err, ErrCircuitOpen)
+
+    status := rc.Status()
+    require.NotNil(t, status.Breaker)
+    assert.Equal(t, BreakerOpen, status.Breaker.State)
+    assert.Equal(t, uint64(2), status.Breaker.Rejected)
+}
+
+func TestRedisCache_BreakerMissAndDrop(t *testing.T) {
+    rc := setupBreakerCache(t, BreakerConfig{MissOnOpen: true, WritePolicy: WritePolicyDrop})
+    defer rc.Close()
+    ctx := context.Background()
+
+    openBreaker(rc)
+
+    item, err := rc.Get(ctx, "breaker:key")
+    assert.NoError(t, err)
+    assert.Nil(t, item)
+
+    items, err := rc.GetMultiple(ctx, []string{"breaker:a", "breaker:b"})
+    assert.NoError(t, err)
+    assert.Empty(t, items)
+
+    assert.NoError(t, rc.Set(ctx, "breaker:key", "value", time.Minute))
+    assert.NoError(t, rc.Delete(ctx, "breaker:other"))
+    assert.Equal(t, uint64(2), rc.Status().Breaker.DroppedWrites)
+}
+
+func TestRedisCache_BreakerBufferReplay(t *testing.T) {
+    rc := setupBreakerCache(t, BreakerConfig{WritePolicy: WritePolicyBuffer, BufferSize: 2})
+    defer rc.Close()
+    ctx := context.Background()
+
+    require.NoError(t, rc.Set(ctx, "breaker:deleted", "old", time.Minute))
+
+    openBreaker(rc)
+
+    assert.NoError(t, rc.Set(ctx, "breaker:key", "value", time.Minute))
+    assert.NoError(t, rc.Delete(ctx, "breaker:deleted"))
+    // The buffer is full
+    assert.ErrorIs(t, rc.Set(ctx, "breaker:overflow", "value", time.Minute), ErrCircuitOpen)
+    assert.Equal(t, 2, rc.Status().Breaker.BufferedWrites)
+
+    // A successful probe replays the buffer in order before closing the circuit
+    expireOpenTimeout(rc)
+    require.NoError(t, rc.Ping(ctx))
+    require.Eventually(t, func() bool {
+        return rc.Status().Breaker.State == BreakerClosed
+    }, time.Second, 10*time.Millisecond)
+    assert.Equal(t, 0, rc.Status().Breaker.BufferedWrites)
+
+    exists, err := rc.Exists(ctx, "breaker:deleted")
+    require.NoError(t, err)
+    assert.False(t, exists)
+
+    item, err := rc.Get(ctx, "breaker:key")
+    require.NoError(t, err)
+    require.NotNil(t, item)
+    assert.Equal(t, "value", item.Value)
+
+    ttl, err := rc.TTL(ctx, "breaker:key")
+    require.NoError(t, err)
+    assert.True(t, ttl > 0 && ttl <= time.Minute)
+}
+
+func TestRedisCache_ClearDiscardsBufferedWrites(t *testing.T) {
+    rc := setupBreakerCache(t, BreakerConfig{WritePolicy: WritePolicyBuffer})
+    defer rc.Close()
+    ctx := context.Background()
+
+    require.True(t, rc.writes.push(rc.bufferedSet("breaker:key", []byte(`"value"`), time.Minute)))
+    require.NoError(t, rc.Clear(ctx))
+    assert.Equal(t, 0, rc.Status().Breaker.BufferedWrites)
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+    "errors"
+    "strings"
+)
+
+var (
+    // ErrWrongType is returned when an operation targets a key holding another data type
+    ErrWrongType = errors.New("key holds a different data type")
+    // ErrNotInteger is returned when incrementing a value that is not an integer
+    ErrNotInteger = errors.New("value is not an integer")
+)
+
+// dataTypeError translates Redis replies caused by the value stored at a key
+// into the package errors; other errors are returned unchanged
+func dataTypeError(err error) error {
+    if !isRedisReply(err) {
+        return err
+    }
+    msg := err.Error()
+    switch {
+    case strings.HasPrefix(msg, "WRONGTYPE"):
+        return ErrWrongType
+    case strings.Contains(msg, "not an integer"):
+        return ErrNotInteger
+    }
+    return err
+}
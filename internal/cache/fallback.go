@@ -0,0 +1,208 @@
+package cache
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+
+    "distributed-cache/pkg/models"
+)
+
+// FallbackConfig configuration of the in-process store used while Redis is unreachable
+type FallbackConfig struct {
+    Enabled           bool          `mapstructure:"enabled"`
+    MaxItems          int           `mapstructure:"max_items"`          // Entries kept, least recently used are evicted
+    MaxValueSize      int           `mapstructure:"max_value_size"`     // Larger values are not kept locally (0 = no limit)
+    ReconcileInterval time.Duration `mapstructure:"reconcile_interval"` // How often pending writes are retried
+}
+
+// FallbackStatus state of the local fallback store
+type FallbackStatus struct {
+    Items         int    `json:"items"`
+    PendingWrites int    `json:"pending_writes"` // Writes awaiting reconciliation with Redis
+    Hits          uint64 `json:"hits"`           // Reads served locally because Redis was unavailable
+    Reconciled    uint64 `json:"reconciled"`
+    Conflicts     uint64 `json:"conflicts"`   // Keys also written to Redis by someone else during the outage
+    LostWrites    uint64 `json:"lost_writes"` // Pending writes evicted before reconciliation
+}
+
+// maxReconcileRetries WATCH attempts per key before giving up until the next round
+const maxReconcileRetries = 3
+
+// unavailable reports whether err means the command did not reach a healthy Redis
+func unavailable(err error) bool {
+    return errors.Is(err, ErrCircuitOpen) || isBackendFailure(err)
+}
+
+// expiresAt converts a TTL to the absolute expiry used by the local store
+func expiresAt(ttl time.Duration, now time.Time) time.Time {
+    if ttl <= 0 {
+        return time.Time{}
+    }
+    return now.Add(ttl)
+}
+
+// localRead serves key from the fallback store. found is false if there is no
+// usable entry; a nil item with found set means a pending delete. With
+// dirtyOnly only pending writes are served, so they win over Redis until
+// reconciled.
+func (rc *RedisCache) localRead(op *operation, key string, dirtyOnly bool) (_ *models.CacheItem, found bool) {
+    if rc.local == nil {
+        return nil, false
+    }
+    entry, ok := rc.local.get(key, dirtyOnly)
+    if !ok {
+        return nil, false
+    }
+    if !dirtyOnly {
+        rc.localHits.Add(1)
+    }
+
+    if entry.data == nil || entry.expired(time.Now()) {
+        op.access(key, AccessMiss, 0)
+        return nil, true
+    }
+
+    var item models.CacheItem
+    if err := op.decode(entry.data, &item); err != nil {
+        return nil, false
+    }
+    op.access(key, AccessHit, len(entry.data))
+    return &item, true
+}
+
+// localWrite keeps a write made while Redis is unavailable; data nil is a delete
+func (rc *RedisCache) localWrite(op *operation, key string, data []byte, ttl time.Duration) {
+    now := time.Now()
+    rc.local.write(key, data, expiresAt(ttl, now), now)
+    if data == nil {
+        op.access(key, AccessDelete, 0)
+    } else {
+        op.access(key, AccessSet, len(data))
+    }
+}
+
+// runReconciler retries pending writes until the cache is closed
+func (rc *RedisCache) runReconciler(interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-rc.done:
+            return
+        case <-ticker.C:
+            if _, dirty, _ := rc.local.counts(); dirty > 0 {
+                rc.reconcile()
+            }
+        }
+    }
+}
+
+// reconcile replays the pending local writes against Redis. A key also
+// written by someone else during the outage is a conflict, resolved by
+// keeping the most recent write.
+func (rc *RedisCache) reconcile() {
+    if !rc.reconciling.TryLock() {
+        return
+    }
+    defer rc.reconciling.Unlock()
+
+    pending := rc.local.pending()
+    if len(pending) == 0 {
+        return
+    }
+
+    ctx := context.Background()
+    reconciled, conflicts := 0, 0
+    for _, entry := range pending {
+        conflict, err := rc.reconcileEntry(ctx, entry)
+        if unavailable(err) {
+            rc.logger.Debug("reconciliation interrupted, Redis unavailable",
+                zap.Int("reconciled", reconciled),
+                zap.Int("pending", len(pending)-reconciled),
+                zap.Error(err))
+            return
+        }
+        if err != nil {
+            // Keep the write pending; it is retried on the next round
+            rc.logger.Warn("failed to reconcile pending write", zap.String("key", entry.key), zap.Error(err))
+            continue
+        }
+
+        rc.local.settle(entry.key, entry.writtenAt)
+        rc.reconciled.Add(1)
+        reconciled++
+        if conflict {
+            rc.conflicts.Add(1)
+            conflicts++
+        }
+    }
+
+    rc.logger.Info("pending local writes reconciled",
+        zap.Int("reconciled", reconciled),
+        zap.Int("conflicts", conflicts))
+}
+
+// reconcileEntry applies one pending write under WATCH. It reports a conflict
+// when the Redis value changed since the write was made locally.
+func (rc *RedisCache) reconcileEntry(ctx context.Context, entry localEntry) (conflict bool, err error) {
+    for attempt := 0; attempt < maxReconcileRetries; attempt++ {
+        conflict = false
+        err = rc.client.Watch(ctx, func(tx *redis.Tx) error {
+            current, err := tx.Get(ctx, entry.key).Bytes()
+            if err == redis.Nil {
+                current = nil
+            } else if err != nil {
+                return err
+            }
+
+            if entry.baseKnown {
+                conflict = hashValue(current) != entry.baseHash
+            } else {
+                // Without a base only a write newer than ours is known to conflict
+                conflict = current != nil && writtenAfter(current, entry.writtenAt)
+            }
+            if conflict && writtenAfter(current, entry.writtenAt) {
+                return nil // Redis holds the most recent write
+            }
+
+            _, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+                switch {
+                case entry.data == nil || entry.expired(time.Now()):
+                    pipe.Del(ctx, entry.key)
+                case entry.expiresAt.IsZero():
+                    pipe.Set(ctx, entry.key, entry.data, 0)
+                default:
+                    pipe.Set(ctx, entry.key, entry.data, time.Until(entry.expiresAt))
+                }
+                return nil
+            })
+            return err
+        }, entry.key)
+
+        if err != redis.TxFailedErr {
+            return conflict, err
+        }
+    }
+    return conflict, err
+}
+
+// writtenAfter reports whether the encoded item was created after t. Values
+// that are not cache items are treated as older.
+func writtenAfter(data []byte, t time.Time) bool {
+    if data == nil {
+        return false
+    }
+    var item struct {
+        CreatedAt time.Time `json:"created_at"`
+    }
+    if err := json.Unmarshal(data, &item); err != nil {
+        return false
+    }
+    return item.CreatedAt.After(t)
+}
@@ -0,0 +1,177 @@
+package cache
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap/zaptest"
+)
+
+func TestLocalStore_LRUAndPendingWrites(t *testing.T) {
+    s := newLocalStore(2, 0)
+    now := time.Now()
+
+    s.remember("a", []byte("1"), time.Time{})
+    s.remember("b", []byte("2"), time.Time{})
+    _, ok := s.get("a", false) // a becomes the most recently used
+    require.True(t, ok)
+    s.remember("c", []byte("3"), time.Time{})
+
+    _, ok = s.get("b", false)
+    assert.False(t, ok, "least recently used entry is evicted")
+
+    // A pending write records the value it replaced as its base
+    s.write("a", []byte("local"), time.Time{}, now)
+    pending := s.pending()
+    require.Len(t, pending, 1)
+    assert.True(t, pending[0].baseKnown)
+    assert.Equal(t, hashValue([]byte("1")), pending[0].baseHash)
+
+    // Reads from Redis do not replace pending writes; successful writes do
+    s.refresh("a", []byte("redis"), time.Time{})
+    entry, _ := s.get("a", true)
+    assert.Equal(t, "local", string(entry.data))
+
+    // Settling an overwritten write keeps it pending
+    s.write("a", []byte("newer"), time.Time{}, now.Add(time.Second))
+    s.settle("a", now)
+    _, dirty, _ := s.counts()
+    assert.Equal(t, 1, dirty)
+    s.settle("a", now.Add(time.Second))
+    _, dirty, _ = s.counts()
+    assert.Equal(t, 0, dirty)
+
+    // Evicting a pending write counts it as lost
+    s.write("d", nil, time.Time{}, now)
+    s.remember("e", []byte("5"), time.Time{})
+    s.remember("f", []byte("6"), time.Time{})
+    items, dirty, lost := s.counts()
+    assert.Equal(t, 2, items)
+    assert.Equal(t, 0, dirty)
+    assert.Equal(t, uint64(1), lost)
+}
+
+func TestLocalStore_MaxValueSize(t *testing.T) {
+    s := newLocalStore(10, 4)
+    s.remember("small", []byte("1234"), time.Time{})
+    s.remember("big", []byte("12345"), time.Time{})
+
+    _, ok := s.get("small", false)
+    assert.True(t, ok)
+    _, ok = s.get("big", false)
+    assert.False(t, ok)
+}
+
+func setupFallbackCache(t *testing.T) *RedisCache {
+    config := DefaultCacheConfig()
+    config.Fallback.Enabled = true
+    config.Fallback.ReconcileInterval = time.Hour // Reconciled explicitly by the tests
+
+    rc, err := NewRedisCache(config, zaptest.NewLogger(t))
+    require.NoError(t, err)
+    require.NoError(t, rc.Clear(context.Background()))
+    return rc
+}
+
+func TestRedisCache_FallbackServesRecentKeys(t *testing.T) {
+    rc := setupFallbackCache(t)
+    defer rc.Close()
+    ctx := context.Background()
+
+    require.NoError(t, rc.Set(ctx, "fallback:seen", "value", time.Minute))
+    openBreaker(rc)
+
+    item, err := rc.Get(ctx, "fallback:seen")
+    require.NoError(t, err)
+    require.NotNil(t, item)
+    assert.Equal(t, "value", item.Value)
+
+    // Keys never seen cannot be served
+    _, err = rc.Get(ctx, "fallback:unknown")
+    assert.ErrorIs(t, err, ErrCircuitOpen)
+
+    // Writes during the outage are visible locally
+    require.NoError(t, rc.Set(ctx, "fallback:new", "local", time.Minute))
+    require.NoError(t, rc.Delete(ctx, "fallback:seen"))
+
+    item, err = rc.Get(ctx, "fallback:seen")
+    require.NoError(t, err)
+    assert.Nil(t, item)
+
+    items, err := rc.GetMultiple(ctx, []string{"fallback:new", "fallback:seen"})
+    require.NoError(t, err)
+    assert.Len(t, items, 1)
+    assert.Equal(t, "local", items["fallback:new"].Value)
+
+    status := rc.Status()
+    require.NotNil(t, status.Fallback)
+    assert.Equal(t, 2, status.Fallback.PendingWrites)
+    assert.Equal(t, uint64(3), status.Fallback.Hits)
+}
+
+func TestRedisCache_FallbackReconcile(t *testing.T) {
+    rc := setupFallbackCache(t)
+    defer rc.Close()
+    other := setupTestCache(t)
+    defer other.Close()
+    ctx := context.Background()
+
+    require.NoError(t, rc.Set(ctx, "fallback:contested", "before", time.Minute))
+    require.NoError(t, rc.Set(ctx, "fallback:deleted", "before", time.Minute))
+
+    openBreaker(rc)
+    require.NoError(t, rc.Set(ctx, "fallback:contested", "ours", time.Minute))
+    require.NoError(t, rc.Set(ctx, "fallback:new", "ours", time.Minute))
+    require.NoError(t, rc.Delete(ctx, "fallback:deleted"))
+
+    // Another instance still reaching Redis writes the same key afterwards
+    require.NoError(t, other.Set(ctx, "fallback:contested", "theirs", time.Minute))
+
+    expireOpenTimeout(rc)
+    require.NoError(t, rc.Ping(ctx))
+    require.Eventually(t, func() bool {
+        return rc.Status().Fallback.PendingWrites == 0
+    }, time.Second, 10*time.Millisecond)
+
+    status := rc.Status().Fallback
+    assert.Equal(t, uint64(3), status.Reconciled)
+    assert.Equal(t, uint64(1), status.Conflicts)
+
+    // The most recent write wins the conflict
+    item, err := other.Get(ctx, "fallback:contested")
+    require.NoError(t, err)
+    assert.Equal(t, "theirs", item.Value)
+
+    item, err = other.Get(ctx, "fallback:new")
+    require.NoError(t, err)
+    require.NotNil(t, item)
+    assert.Equal(t, "ours", item.Value)
+
+    exists, err := other.Exists(ctx, "fallback:deleted")
+    require.NoError(t, err)
+    assert.False(t, exists)
+}
+
+func TestNewRedisCache_StartDegraded(t *testing.T) {
+    config := DefaultCacheConfig()
+    config.Addresses = []string{"127.0.0.1:1"}
+    config.MaxRetries = -1
+    config.DialTimeout = 100 * time.Millisecond
+
+    config.StartDegraded = false
+    _, err := NewRedisCache(config, zaptest.NewLogger(t))
+    assert.Error(t, err)
+
+    config.StartDegraded = true
+    rc, err := NewRedisCache(config, zaptest.NewLogger(t))
+    require.NoError(t, err)
+    defer rc.Close()
+
+    // The breaker starts open so requests fail fast until Redis is reachable
+    assert.Equal(t, BreakerOpen, rc.Status().Breaker.State)
+    _, err = rc.Get(context.Background(), "key")
+    assert.ErrorIs(t, err, ErrCircuitOpen)
+}
@@ -0,0 +1,27 @@
+package cache
+
+import (
+    "context"
+    "time"
+)
+
+// HashStore defines field-level operations on Redis hashes. Field values are
+// stored JSON encoded, so integers written with HSet can be incremented.
+type HashStore interface {
+    // HSet writes the fields and returns how many were new. A positive ttl
+    // sets the key expiration; zero keeps the current one.
+    HSet(ctx context.Context, key string, fields map[string]interface{}, ttl time.Duration) (int64, error)
+    // HGet returns the value of field; found is false if the field or key is missing
+    HGet(ctx context.Context, key, field string) (value interface{}, found bool, err error)
+    // HMGet returns the values of the fields that exist
+    HMGet(ctx context.Context, key string, fields ...string) (map[string]interface{}, error)
+    // HDel removes the fields and returns how many existed
+    HDel(ctx context.Context, key string, fields ...string) (int64, error)
+    // HGetAll returns every field of the hash, empty if the key is missing
+    HGetAll(ctx context.Context, key string) (map[string]interface{}, error)
+    // HIncrBy adds delta to an integer field and returns the new value. A
+    // positive ttl sets the key expiration; zero keeps the current one.
+    HIncrBy(ctx context.Context, key, field string, delta int64, ttl time.Duration) (int64, error)
+    // HLen returns the number of fields in the hash
+    HLen(ctx context.Context, key string) (int64, error)
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRedisCache_HashFields(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    added, err := cache.HSet(ctx, "users:42", map[string]interface{}{
+        "name":   "Ada",
+        "visits": 1,
+        "tags":   []string{"admin"},
+    }, time.Minute)
+    require.NoError(t, err)
+    assert.Equal(t, int64(3), added)
+
+    // Solo cambia el campo actualizado
+    added, err = cache.HSet(ctx, "users:42", map[string]interface{}{"name": "Ada L."}, 0)
+    require.NoError(t, err)
+    assert.Equal(t, int64(0), added)
+
+    value, found, err := cache.HGet(ctx, "users:42", "name")
+    require.NoError(t, err)
+    assert.True(t, found)
+    assert.Equal(t, "Ada L.", value)
+
+    _, found, err = cache.HGet(ctx, "users:42", "missing")
+    require.NoError(t, err)
+    assert.False(t, found)
+
+    values, err := cache.HMGet(ctx, "users:42", "name", "tags", "missing")
+    require.NoError(t, err)
+    assert.Len(t, values, 2)
+    assert.Equal(t, []interface{}{"admin"}, values["tags"])
+
+    count, err := cache.HIncrBy(ctx, "users:42", "visits", 2, 0)
+    require.NoError(t, err)
+    assert.Equal(t, int64(3), count)
+
+    all, err := cache.HGetAll(ctx, "users:42")
+    require.NoError(t, err)
+    assert.Equal(t, float64(3), all["visits"])
+
+    removed, err := cache.HDel(ctx, "users:42", "tags", "missing")
+    require.NoError(t, err)
+    assert.Equal(t, int64(1), removed)
+
+    length, err := cache.HLen(ctx, "users:42")
+    require.NoError(t, err)
+    assert.Equal(t, int64(2), length)
+
+    // El TTL se aplica a la clave y se conserva al escribir sin TTL
+    ttl, err := cache.TTL(ctx, "users:42")
+    require.NoError(t, err)
+    assert.Greater(t, ttl, time.Duration(0))
+}
+
+func TestRedisCache_HashTypeErrors(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    require.NoError(t, cache.Set(ctx, "plain", "value", time.Minute))
+    _, err := cache.HSet(ctx, "plain", map[string]interface{}{"field": 1}, 0)
+    assert.ErrorIs(t, err, ErrWrongType)
+    _, _, err = cache.HGet(ctx, "plain", "field")
+    assert.ErrorIs(t, err, ErrWrongType)
+
+    _, err = cache.HSet(ctx, "hash", map[string]interface{}{"name": "text"}, 0)
+    require.NoError(t, err)
+    _, err = cache.HIncrBy(ctx, "hash", "name", 1, 0)
+    assert.ErrorIs(t, err, ErrNotInteger)
+
+    // Los errores de tipo no cuentan como fallos del backend
+    assert.Equal(t, BreakerClosed, cache.Status().Breaker.State)
+    assert.Zero(t, cache.Status().Breaker.Failures)
+}
+
+func TestRedisCache_HashWriteBehind(t *testing.T) {
+    rc := setupWriteBehindCache(t, WriteBehindConfig{QueueSize: 100, BatchSize: 100, FlushInterval: time.Hour})
+    defer rc.Close()
+    ctx := context.Background()
+
+    // La escritura encolada llega a Redis antes que los campos
+    require.NoError(t, rc.Set(ctx, "wb:hash", "queued", time.Minute))
+    _, err := rc.HSet(ctx, "wb:hash", map[string]interface{}{"field": 1}, 0)
+    assert.ErrorIs(t, err, ErrWrongType)
+
+    // Un borrado encolado no elimina después el hash escrito
+    require.NoError(t, rc.Delete(ctx, "wb:hash"))
+    _, err = rc.HIncrBy(ctx, "wb:hash", "field", 2, 0)
+    require.NoError(t, err)
+    require.NoError(t, rc.Flush(ctx))
+
+    value, found, err := rc.HGet(ctx, "wb:hash", "field")
+    require.NoError(t, err)
+    assert.True(t, found)
+    assert.Equal(t, float64(2), value)
+}
@@ -0,0 +1,19 @@
+package cache
+
+import (
+    "context"
+    "time"
+)
+
+// HyperLogLogStore defines operations on HyperLogLog counters, which
+// estimate the number of distinct elements with a standard error of 0.81%
+// using at most 12KB per key.
+type HyperLogLogStore interface {
+    // PFAdd adds elements and reports whether the estimate changed. A
+    // positive ttl sets the key expiration; zero keeps the current one.
+    PFAdd(ctx context.Context, key string, elements []string, ttl time.Duration) (bool, error)
+    // PFCount returns the estimated number of distinct elements in the union of the keys
+    PFCount(ctx context.Context, keys ...string) (int64, error)
+    // PFMerge merges the sources into dest and returns the estimate of dest
+    PFMerge(ctx context.Context, dest string, sources []string, ttl time.Duration) (int64, error)
+}
@@ -0,0 +1,28 @@
+package cache
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "distributed-cache/pkg/models"
+)
+
+// ErrIdempotencyNotOwned is returned when completing or releasing a key that
+// is not claimed by the caller, or that was already completed
+var ErrIdempotencyNotOwned = errors.New("idempotency key is not claimed by this owner")
+
+// IdempotencyStore defines the operations behind exactly-once request handling.
+// A request claims its key, does its work and stores the final response;
+// duplicates get the record of the request that claimed the key.
+type IdempotencyStore interface {
+    // ClaimIdempotencyKey claims key for owner for up to lockTTL. If the key
+    // is already claimed or completed it returns that record and false.
+    ClaimIdempotencyKey(ctx context.Context, key, owner, fingerprint string, lockTTL time.Duration) (*models.IdempotencyRecord, bool, error)
+    // CompleteIdempotencyKey stores the final response and keeps it for ttl
+    CompleteIdempotencyKey(ctx context.Context, key, owner string, response *models.IdempotentResponse, ttl time.Duration) (*models.IdempotencyRecord, error)
+    // ReleaseIdempotencyKey drops an in-progress claim so the request can be retried
+    ReleaseIdempotencyKey(ctx context.Context, key, owner string) error
+    // GetIdempotencyKey returns the record of key, nil if it does not exist
+    GetIdempotencyKey(ctx context.Context, key string) (*models.IdempotencyRecord, error)
+}
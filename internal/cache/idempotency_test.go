@@ -0,0 +1,93 @@
+package cache
+
+import (
+    "context"
+    "net/http"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "distributed-cache/pkg/models"
+)
+
+func TestRedisCache_IdempotencyKey(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    record, claimed, err := cache.ClaimIdempotencyKey(ctx, "order-1", "owner-a", "fp", 30*time.Second)
+    require.NoError(t, err)
+    assert.True(t, claimed)
+    assert.Equal(t, "owner-a", record.Owner)
+    assert.Equal(t, models.IdempotencyInProgress, record.Status)
+
+    // Un duplicado ve la petición en curso, sin el owner
+    record, claimed, err = cache.ClaimIdempotencyKey(ctx, "order-1", "owner-b", "fp", 30*time.Second)
+    require.NoError(t, err)
+    assert.False(t, claimed)
+    assert.Equal(t, models.IdempotencyInProgress, record.Status)
+    assert.Equal(t, "fp", record.Fingerprint)
+    assert.Empty(t, record.Owner)
+    assert.True(t, record.ExpiresAt.After(time.Now()))
+
+    // Solo el owner puede completar la clave
+    response := &models.IdempotentResponse{
+        StatusCode: http.StatusCreated,
+        Header:     map[string][]string{"Content-Type": {"application/json"}},
+        Body:       []byte(`{"id":1}`),
+    }
+    _, err = cache.CompleteIdempotencyKey(ctx, "order-1", "owner-b", response, time.Hour)
+    assert.ErrorIs(t, err, ErrIdempotencyNotOwned)
+
+    record, err = cache.CompleteIdempotencyKey(ctx, "order-1", "owner-a", response, time.Hour)
+    require.NoError(t, err)
+    assert.True(t, record.Completed())
+    assert.Equal(t, response, record.Response)
+
+    // Los duplicados posteriores reciben la respuesta guardada
+    record, claimed, err = cache.ClaimIdempotencyKey(ctx, "order-1", "owner-b", "fp", 30*time.Second)
+    require.NoError(t, err)
+    assert.False(t, claimed)
+    assert.True(t, record.Completed())
+    assert.Equal(t, response, record.Response)
+    assert.True(t, record.ExpiresAt.After(time.Now().Add(30*time.Minute)))
+
+    // Una clave completada ya no se puede completar ni liberar
+    _, err = cache.CompleteIdempotencyKey(ctx, "order-1", "owner-a", response, time.Hour)
+    assert.ErrorIs(t, err, ErrIdempotencyNotOwned)
+    assert.ErrorIs(t, cache.ReleaseIdempotencyKey(ctx, "order-1", "owner-a"), ErrIdempotencyNotOwned)
+
+    record, err = cache.GetIdempotencyKey(ctx, "order-1")
+    require.NoError(t, err)
+    require.NotNil(t, record)
+    assert.Equal(t, "order-1", record.Key)
+    assert.True(t, record.Completed())
+    assert.False(t, record.CreatedAt.IsZero())
+
+    record, err = cache.GetIdempotencyKey(ctx, "missing")
+    require.NoError(t, err)
+    assert.Nil(t, record)
+}
+
+func TestRedisCache_ReleaseIdempotencyKey(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    _, claimed, err := cache.ClaimIdempotencyKey(ctx, "order-2", "owner-a", "fp", 30*time.Second)
+    require.NoError(t, err)
+    require.True(t, claimed)
+
+    assert.ErrorIs(t, cache.ReleaseIdempotencyKey(ctx, "order-2", "owner-b"), ErrIdempotencyNotOwned)
+    require.NoError(t, cache.ReleaseIdempotencyKey(ctx, "order-2", "owner-a"))
+
+    // Tras liberarla otra petición puede reclamarla
+    record, claimed, err := cache.ClaimIdempotencyKey(ctx, "order-2", "owner-b", "other", 30*time.Second)
+    require.NoError(t, err)
+    assert.True(t, claimed)
+    assert.Equal(t, "other", record.Fingerprint)
+}
@@ -0,0 +1,183 @@
+package cache
+
+import (
+    "math"
+    "strconv"
+    "strings"
+)
+
+// KeyspaceSection name of the INFO section listing the databases
+const KeyspaceSection = "keyspace"
+
+// ServerInfo parsed output of the Redis INFO command
+type ServerInfo struct {
+    // Sections maps each lowercase section name ("server", "memory"...) to
+    // its fields. Values are int64, float64 or string; fields holding
+    // "k=v,k=v" lists (replicas, command stats) are parsed into nested maps.
+    Sections map[string]map[string]interface{} `json:"sections"`
+    // Keyspace maps each database ("db0") to its key counts
+    Keyspace map[string]KeyspaceInfo `json:"keyspace"`
+}
+
+// KeyspaceInfo key counts of a single database
+type KeyspaceInfo struct {
+    Keys    int64 `json:"keys"`
+    Expires int64 `json:"expires"`
+    AvgTTL  int64 `json:"avg_ttl"` // Milliseconds
+}
+
+// ParseInfo parses the raw output of INFO. Unknown or malformed lines are
+// skipped; fields appearing before any section header go to "default".
+func ParseInfo(raw string) *ServerInfo {
+    info := &ServerInfo{
+        Sections: make(map[string]map[string]interface{}),
+        Keyspace: make(map[string]KeyspaceInfo),
+    }
+
+    section := "default"
+    for _, line := range strings.Split(raw, "\n") {
+        line = strings.TrimSpace(line)
+        if line == "" {
+            continue
+        }
+        if strings.HasPrefix(line, "#") {
+            section = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "#")))
+            // Keep empty sections so callers can tell "no fields" from "not returned"
+            if _, ok := info.Sections[section]; !ok && section != KeyspaceSection {
+                info.Sections[section] = make(map[string]interface{})
+            }
+            continue
+        }
+
+        // Only the first colon separates name and value ("executable:/usr/bin/redis")
+        name, value, ok := strings.Cut(line, ":")
+        if !ok || name == "" {
+            continue
+        }
+
+        if section == KeyspaceSection {
+            info.Keyspace[name] = parseKeyspace(value)
+            continue
+        }
+
+        fields, ok := info.Sections[section]
+        if !ok {
+            fields = make(map[string]interface{})
+            info.Sections[section] = fields
+        }
+        fields[name] = parseInfoValue(value)
+    }
+
+    return info
+}
+
+// Section returns the fields of a section, or nil if it is missing
+func (i *ServerInfo) Section(name string) map[string]interface{} {
+    return i.Sections[strings.ToLower(name)]
+}
+
+// String returns a field as a string, formatting numbers if needed
+func (i *ServerInfo) String(section, field string) string {
+    switch v := i.Section(section)[field].(type) {
+    case string:
+        return v
+    case int64:
+        return strconv.FormatInt(v, 10)
+    case float64:
+        return strconv.FormatFloat(v, 'f', -1, 64)
+    default:
+        return ""
+    }
+}
+
+// Int returns an integer field; ok is false if it is missing or not an integer
+func (i *ServerInfo) Int(section, field string) (int64, bool) {
+    v, ok := i.Section(section)[field].(int64)
+    return v, ok
+}
+
+// Float returns a numeric field as float64; ok is false if it is missing or not numeric
+func (i *ServerInfo) Float(section, field string) (float64, bool) {
+    switch v := i.Section(section)[field].(type) {
+    case float64:
+        return v, true
+    case int64:
+        return float64(v), true
+    default:
+        return 0, false
+    }
+}
+
+// parseKeyspace parses "keys=1,expires=0,avg_ttl=0"
+func parseKeyspace(value string) KeyspaceInfo {
+    var keyspace KeyspaceInfo
+    for _, pair := range strings.Split(value, ",") {
+        name, raw, ok := strings.Cut(pair, "=")
+        if !ok {
+            continue
+        }
+        n, err := strconv.ParseInt(raw, 10, 64)
+        if err != nil {
+            continue
+        }
+        switch name {
+        case "keys":
+            keyspace.Keys = n
+        case "expires":
+            keyspace.Expires = n
+        case "avg_ttl":
+            keyspace.AvgTTL = n
+        }
+    }
+    return keyspace
+}
+
+// parseInfoValue converts a field value to int64, float64, a nested map for
+// "k=v,k=v" lists, or leaves it as a string
+func parseInfoValue(value string) interface{} {
+    if nested, ok := parseInfoList(value); ok {
+        return nested
+    }
+    return parseScalar(value)
+}
+
+// parseInfoList parses values such as "ip=10.0.0.2,port=6379,state=online"
+func parseInfoList(value string) (map[string]interface{}, bool) {
+    if !strings.Contains(value, "=") {
+        return nil, false
+    }
+
+    pairs := strings.Split(value, ",")
+    nested := make(map[string]interface{}, len(pairs))
+    for _, pair := range pairs {
+        name, raw, ok := strings.Cut(pair, "=")
+        if !ok || name == "" {
+            return nil, false
+        }
+        nested[name] = parseScalar(raw)
+    }
+    return nested, true
+}
+
+// parseScalar converts a single value to int64 or float64 when possible
+func parseScalar(value string) interface{} {
+    if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+        return n
+    }
+    // Redis writes decimals as plain numbers; reject forms ParseFloat accepts
+    // but INFO never emits as numbers (inf, nan, hex floats)
+    if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) && isDecimal(value) {
+        return f
+    }
+    return value
+}
+
+// isDecimal reports whether s only contains digits, a sign, a dot or an exponent
+func isDecimal(s string) bool {
+    for _, r := range s {
+        if !strings.ContainsRune("0123456789.-+eE", r) {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+const sampleInfo = "# Server\r\n" +
+    "redis_version:7.2.4\r\n" +
+    "executable:/usr/local/bin/redis-server\r\n" +
+    "uptime_in_seconds:3600\r\n" +
+    "\r\n" +
+    "# Memory\r\n" +
+    "used_memory:1048576\r\n" +
+    "mem_fragmentation_ratio:1.25\r\n" +
+    "maxmemory_policy:allkeys-lru\r\n" +
+    "\r\n" +
+    "# Replication\r\n" +
+    "role:master\r\n" +
+    "connected_slaves:1\r\n" +
+    "slave0:ip=10.0.0.2,port=6379,state=online,offset=1234,lag=0\r\n" +
+    "\r\n" +
+    "# Commandstats\r\n" +
+    "cmdstat_get:calls=10,usec=25,usec_per_call=2.50\r\n" +
+    "\r\n" +
+    "# Keyspace\r\n" +
+    "db0:keys=12,expires=3,avg_ttl=45000\r\n" +
+    "db1:keys=1,expires=0,avg_ttl=0\r\n"
+
+func TestParseInfo(t *testing.T) {
+    info := ParseInfo(sampleInfo)
+
+    assert.Equal(t, "7.2.4", info.String("server", "redis_version"))
+    assert.Equal(t, "/usr/local/bin/redis-server", info.String("server", "executable"))
+
+    uptime, ok := info.Int("server", "uptime_in_seconds")
+    assert.True(t, ok)
+    assert.Equal(t, int64(3600), uptime)
+
+    ratio, ok := info.Float("Memory", "mem_fragmentation_ratio")
+    assert.True(t, ok)
+    assert.Equal(t, 1.25, ratio)
+    assert.Equal(t, "allkeys-lru", info.String("memory", "maxmemory_policy"))
+
+    assert.Equal(t, "master", info.String("replication", "role"))
+    replica, ok := info.Section("replication")["slave0"].(map[string]interface{})
+    require.True(t, ok)
+    assert.Equal(t, "10.0.0.2", replica["ip"])
+    assert.Equal(t, int64(6379), replica["port"])
+    assert.Equal(t, "online", replica["state"])
+
+    cmdstat, ok := info.Section("commandstats")["cmdstat_get"].(map[string]interface{})
+    require.True(t, ok)
+    assert.Equal(t, 2.5, cmdstat["usec_per_call"])
+
+    require.Len(t, info.Keyspace, 2)
+    assert.Equal(t, KeyspaceInfo{Keys: 12, Expires: 3, AvgTTL: 45000}, info.Keyspace["db0"])
+    assert.NotContains(t, info.Sections, KeyspaceSection)
+}
+
+func TestParseInfo_EmptySectionAndOddValues(t *testing.T) {
+    info := ParseInfo("# Persistence\r\n# Stats\r\nweird:inf\r\nhex:0x1p-2\r\nnot a field\r\n")
+
+    assert.Contains(t, info.Sections, "persistence")
+    assert.Empty(t, info.Section("persistence"))
+    assert.Equal(t, "inf", info.Section("stats")["weird"])
+    assert.Equal(t, "0x1p-2", info.Section("stats")["hex"])
+    assert.Len(t, info.Section("stats"), 2)
+
+    _, ok := info.Int("stats", "missing")
+    assert.False(t, ok)
+}
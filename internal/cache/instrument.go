@@ -0,0 +1,240 @@
+package cache
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+    "go.opentelemetry.io/otel/trace"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/logging"
+)
+
+// tracerName instrumentation scope of the cache spans
+const tracerName = "distributed-cache/internal/cache"
+
+var tracer = otel.Tracer(tracerName)
+
+// operation tracks a single cache method call for metrics and tracing
+type operation struct {
+    rc        *RedisCache
+    ctx       context.Context
+    span      trace.Span
+    logger    *zap.Logger
+    name      string
+    namespace string
+    start     time.Time
+    hits      int
+    misses    int
+}
+
+// startOperation begins tracking a cache method call on the given keys. The
+// returned context carries the operation span, so Redis commands issued with
+// it become children of the operation. The operation logger is the
+// request-scoped logger from ctx tagged with the keys' namespace.
+func (rc *RedisCache) startOperation(ctx context.Context, name string, keys ...string) (context.Context, *operation) {
+    namespace := commonNamespace(keys)
+
+    attrs := []attribute.KeyValue{
+        semconv.DBSystemRedis,
+        attribute.String("cache.operation", name),
+    }
+    if namespace != "" {
+        attrs = append(attrs, attribute.String("cache.namespace", namespace))
+    }
+    if len(keys) == 1 {
+        attrs = append(attrs, attribute.String("cache.key_hash", hashKey(keys[0])))
+    }
+
+    ctx, span := tracer.Start(ctx, "cache."+name,
+        trace.WithSpanKind(trace.SpanKindInternal),
+        trace.WithAttributes(attrs...))
+
+    logger := logging.FromContext(ctx, rc.logger)
+    if namespace != "" {
+        logger = logger.With(zap.String("namespace", namespace))
+    }
+
+    return ctx, &operation{
+        rc:        rc,
+        ctx:       ctx,
+        span:      span,
+        logger:    logger,
+        name:      name,
+        namespace: namespace,
+        start:     time.Now(),
+    }
+}
+
+// end reports the outcome of the operation; use it as `defer op.end(&err)`
+func (op *operation) end(err *error) {
+    failed := err != nil && *err != nil && !isOutcome(*err)
+    duration := time.Since(op.start)
+    for _, observer := range op.rc.observers {
+        observer.ObserveOperation(op.name, op.namespace, failed, duration)
+    }
+
+    if op.hits+op.misses > 0 {
+        op.span.SetAttributes(
+            attribute.Int("cache.hits", op.hits),
+            attribute.Int("cache.misses", op.misses))
+    }
+    if failed {
+        op.span.RecordError(*err)
+        op.span.SetStatus(codes.Error, (*err).Error())
+    }
+    op.span.End()
+}
+
+// batch records the number of items handled by a batch operation
+func (op *operation) batch(size int) {
+    op.span.SetAttributes(attribute.Int("cache.batch_size", size))
+}
+
+// access reports a key touched by the operation
+func (op *operation) access(key, kind string, size int) {
+    switch kind {
+    case AccessHit:
+        op.hits++
+    case AccessMiss:
+        op.misses++
+    }
+
+    if len(op.rc.observers) == 0 {
+        return
+    }
+
+    access := KeyAccess{
+        Operation: op.name,
+        Key:       key,
+        Namespace: Namespace(key),
+        Kind:      kind,
+        Size:      size,
+    }
+    for _, observer := range op.rc.observers {
+        observer.ObserveKeyAccess(access)
+    }
+}
+
+// encode serializes v to JSON inside its own span
+func (op *operation) encode(v interface{}) ([]byte, error) {
+    _, span := tracer.Start(op.ctx, "cache.encode")
+    defer span.End()
+
+    data, err := json.Marshal(v)
+    if err != nil {
+        span.SetStatus(codes.Error, err.Error())
+        return nil, err
+    }
+    span.SetAttributes(attribute.Int("cache.value_size", len(data)))
+    return data, nil
+}
+
+// decode deserializes JSON data into v inside its own span
+func (op *operation) decode(data []byte, v interface{}) error {
+    _, span := tracer.Start(op.ctx, "cache.decode",
+        trace.WithAttributes(attribute.Int("cache.value_size", len(data))))
+    defer span.End()
+
+    if err := json.Unmarshal(data, v); err != nil {
+        span.SetStatus(codes.Error, err.Error())
+        return err
+    }
+    return nil
+}
+
+// commonNamespace returns the namespace shared by all keys, or "" if they differ
+func commonNamespace(keys []string) string {
+    if len(keys) == 0 {
+        return ""
+    }
+
+    namespace := Namespace(keys[0])
+    for _, key := range keys[1:] {
+        if Namespace(key) != namespace {
+            return ""
+        }
+    }
+    return namespace
+}
+
+// hashKey returns a short, stable digest of a key so spans do not carry raw keys
+func hashKey(key string) string {
+    sum := sha256.Sum256([]byte(key))
+    return hex.EncodeToString(sum[:8])
+}
+
+// tracingHook creates a client span for every Redis command and pipeline
+type tracingHook struct{}
+
+var _ redis.Hook = tracingHook{}
+
+// BeforeProcess implements redis.Hook
+func (tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+    if !trace.SpanFromContext(ctx).IsRecording() {
+        return ctx, nil
+    }
+
+    ctx, _ = tracer.Start(ctx, "redis."+cmd.Name(),
+        trace.WithSpanKind(trace.SpanKindClient),
+        trace.WithAttributes(
+            semconv.DBSystemRedis,
+            semconv.DBOperation(cmd.Name()),
+        ))
+    return ctx, nil
+}
+
+// AfterProcess implements redis.Hook
+func (tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+    endCommandSpan(ctx, cmd.Err())
+    return nil
+}
+
+// BeforeProcessPipeline implements redis.Hook
+func (tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+    if !trace.SpanFromContext(ctx).IsRecording() {
+        return ctx, nil
+    }
+
+    ctx, _ = tracer.Start(ctx, "redis.pipeline",
+        trace.WithSpanKind(trace.SpanKindClient),
+        trace.WithAttributes(
+            semconv.DBSystemRedis,
+            attribute.Int("db.redis.num_cmd", len(cmds)),
+        ))
+    return ctx, nil
+}
+
+// AfterProcessPipeline implements redis.Hook
+func (tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+    var err error
+    for _, cmd := range cmds {
+        if cmdErr := cmd.Err(); cmdErr != nil && cmdErr != redis.Nil {
+            err = cmdErr
+            break
+        }
+    }
+    endCommandSpan(ctx, err)
+    return nil
+}
+
+// endCommandSpan ends the span started by the hook, if any
+func endCommandSpan(ctx context.Context, err error) {
+    span := trace.SpanFromContext(ctx)
+    if !span.IsRecording() {
+        return
+    }
+    if err != nil && err != redis.Nil {
+        span.RecordError(err)
+        span.SetStatus(codes.Error, err.Error())
+    }
+    span.End()
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+    "context"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type recordingObserver struct {
+    mu       sync.Mutex
+    ops      []string
+    accesses []KeyAccess
+}
+
+func (o *recordingObserver) ObserveOperation(operation, namespace string, failed bool, duration time.Duration) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    o.ops = append(o.ops, operation+"/"+namespace)
+}
+
+func (o *recordingObserver) ObserveKeyAccess(access KeyAccess) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    o.accesses = append(o.accesses, access)
+}
+
+func TestRedisCache_Observer(t *testing.T) {
+    cache := setupTestCache(t)
+    defer cache.Close()
+
+    observer := &recordingObserver{}
+    cache.(*RedisCache).AddObserver(observer)
+
+    ctx := context.Background()
+    require.NoError(t, cache.Set(ctx, "users:1", "alice", time.Hour))
+    _, err := cache.GetMultiple(ctx, []string{"users:1", "users:2"})
+    require.NoError(t, err)
+
+    assert.Equal(t, []string{"set/users", "get_multiple/users"}, observer.ops)
+    require.Len(t, observer.accesses, 3)
+    assert.Equal(t, AccessSet, observer.accesses[0].Kind)
+    assert.Greater(t, observer.accesses[0].Size, 0)
+    assert.Equal(t, AccessHit, observer.accesses[1].Kind)
+    assert.Equal(t, "users:1", observer.accesses[1].Key)
+    assert.Equal(t, AccessMiss, observer.accesses[2].Kind)
+    assert.Equal(t, "users", observer.accesses[2].Namespace)
+}
+
+func TestRedisCache_TracingSpans(t *testing.T) {
+    recorder := tracetest.NewSpanRecorder()
+    otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+    defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+    cache := setupTestCache(t)
+    defer cache.Close()
+
+    ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+    require.NoError(t, cache.Set(ctx, "users:1", "alice", time.Hour))
+    item, err := cache.Get(ctx, "users:1")
+    require.NoError(t, err)
+    require.NotNil(t, item)
+    parent.End()
+
+    spans := make(map[string]sdktrace.ReadOnlySpan)
+    for _, span := range recorder.Ended() {
+        spans[span.Name()] = span
+    }
+
+    for _, name := range []string{"cache.set", "cache.get", "cache.encode", "cache.decode", "redis.set", "redis.get"} {
+        assert.Contains(t, spans, name)
+    }
+    assert.Equal(t, parent.SpanContext().SpanID(), spans["cache.get"].Parent().SpanID())
+    assert.Equal(t, spans["cache.get"].SpanContext().SpanID(), spans["redis.get"].Parent().SpanID())
+    assert.Contains(t, spans["cache.get"].Attributes(), attribute.String("cache.key_hash", hashKey("users:1")))
+    assert.Contains(t, spans["cache.get"].Attributes(), attribute.Int("cache.hits", 1))
+}
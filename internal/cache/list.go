@@ -0,0 +1,35 @@
+package cache
+
+import (
+    "context"
+    "time"
+)
+
+// ListEnd selects the end of a list an operation works on
+type ListEnd string
+
+// List ends
+const (
+    ListLeft  ListEnd = "left"  // Head, index 0
+    ListRight ListEnd = "right" // Tail, index -1
+)
+
+// ListStore defines operations on Redis lists, usable as queues and bounded
+// feeds. Elements are stored JSON encoded.
+type ListStore interface {
+    // ListPush adds values at end and returns the new length. A positive
+    // maxLen trims the list to the maxLen elements nearest that end; a
+    // positive ttl sets the key expiration, zero keeps the current one.
+    ListPush(ctx context.Context, key string, end ListEnd, values []interface{}, ttl time.Duration, maxLen int64) (int64, error)
+    // ListPop removes an element from end. With a positive timeout it waits
+    // for an element until the timeout elapses or ctx is done; found is false
+    // if the list stayed empty.
+    ListPop(ctx context.Context, key string, end ListEnd, timeout time.Duration) (value interface{}, found bool, err error)
+    // ListRange returns the elements between start and stop, both inclusive;
+    // negative indexes count from the tail
+    ListRange(ctx context.Context, key string, start, stop int64) ([]interface{}, error)
+    // ListTrim keeps only the elements between start and stop
+    ListTrim(ctx context.Context, key string, start, stop int64) error
+    // ListLen returns the number of elements in the list
+    ListLen(ctx context.Context, key string) (int64, error)
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRedisCache_ListPushPop(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    length, err := cache.ListPush(ctx, "jobs", ListRight, []interface{}{"a", "b"}, time.Minute, 0)
+    require.NoError(t, err)
+    assert.Equal(t, int64(2), length)
+    _, err = cache.ListPush(ctx, "jobs", ListLeft, []interface{}{map[string]interface{}{"id": 1}}, 0, 0)
+    require.NoError(t, err)
+
+    values, err := cache.ListRange(ctx, "jobs", 0, -1)
+    require.NoError(t, err)
+    assert.Equal(t, []interface{}{map[string]interface{}{"id": float64(1)}, "a", "b"}, values)
+
+    value, found, err := cache.ListPop(ctx, "jobs", ListRight, 0)
+    require.NoError(t, err)
+    assert.True(t, found)
+    assert.Equal(t, "b", value)
+
+    require.NoError(t, cache.ListTrim(ctx, "jobs", 1, -1))
+    length, err = cache.ListLen(ctx, "jobs")
+    require.NoError(t, err)
+    assert.Equal(t, int64(1), length)
+
+    _, found, err = cache.ListPop(ctx, "empty", ListLeft, 0)
+    require.NoError(t, err)
+    assert.False(t, found)
+}
+
+func TestRedisCache_ListBoundedFeed(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    // Las entradas más recientes se conservan al insertar por la cabeza
+    for i := 1; i <= 5; i++ {
+        length, err := cache.ListPush(ctx, "feed", ListLeft, []interface{}{i}, time.Minute, 3)
+        require.NoError(t, err)
+        assert.LessOrEqual(t, length, int64(3))
+    }
+
+    values, err := cache.ListRange(ctx, "feed", 0, -1)
+    require.NoError(t, err)
+    assert.Equal(t, []interface{}{float64(5), float64(4), float64(3)}, values)
+}
+
+func TestRedisCache_ListBlockingPop(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    // El pop espera hasta que llega un elemento
+    go func() {
+        time.Sleep(100 * time.Millisecond)
+        cache.ListPush(ctx, "queue", ListRight, []interface{}{"job"}, 0, 0)
+    }()
+    value, found, err := cache.ListPop(ctx, "queue", ListLeft, 2*time.Second)
+    require.NoError(t, err)
+    assert.True(t, found)
+    assert.Equal(t, "job", value)
+
+    // Sin elementos termina al agotar el timeout
+    _, found, err = cache.ListPop(ctx, "queue", ListLeft, 50*time.Millisecond)
+    require.NoError(t, err)
+    assert.False(t, found)
+
+    // La cancelación del contexto interrumpe la espera
+    cancelCtx, cancel := context.WithCancel(ctx)
+    time.AfterFunc(50*time.Millisecond, cancel)
+    start := time.Now()
+    _, _, err = cache.ListPop(cancelCtx, "queue", ListLeft, 20*time.Second)
+    assert.ErrorIs(t, err, context.Canceled)
+    assert.Less(t, time.Since(start), 2*blockingPollInterval)
+}
+
+func TestRedisCache_ListWriteBehind(t *testing.T) {
+    rc := setupWriteBehindCache(t, WriteBehindConfig{QueueSize: 100, BatchSize: 100, FlushInterval: time.Hour})
+    defer rc.Close()
+    ctx := context.Background()
+
+    // La escritura encolada llega a Redis antes que los elementos
+    require.NoError(t, rc.Set(ctx, "wb:list", "queued", time.Minute))
+    _, err := rc.ListPush(ctx, "wb:list", ListRight, []interface{}{"a"}, 0, 0)
+    assert.ErrorIs(t, err, ErrWrongType)
+
+    // Un borrado encolado no elimina después la lista escrita
+    require.NoError(t, rc.Delete(ctx, "wb:list"))
+    _, err = rc.ListPush(ctx, "wb:list", ListRight, []interface{}{"a", "b"}, 0, 0)
+    require.NoError(t, err)
+    require.NoError(t, rc.Flush(ctx))
+
+    length, err := rc.ListLen(ctx, "wb:list")
+    require.NoError(t, err)
+    assert.Equal(t, int64(2), length)
+}
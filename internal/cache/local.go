@@ -0,0 +1,228 @@
+package cache
+
+import (
+    "container/list"
+    "hash/fnv"
+    "sync"
+    "time"
+)
+
+// localEntry a value held by the in-process fallback store
+type localEntry struct {
+    key       string
+    data      []byte    // Encoded CacheItem; nil for a pending delete
+    expiresAt time.Time // Zero if the value does not expire
+
+    // Pending writes made while Redis was unavailable
+    dirty     bool
+    writtenAt time.Time
+    baseHash  uint64 // Hash of the Redis value the write replaced
+    baseKnown bool
+}
+
+func (e *localEntry) expired(now time.Time) bool {
+    return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// localStore bounded LRU of recently seen values, used to serve reads and
+// hold writes while Redis is unreachable
+type localStore struct {
+    mu           sync.Mutex
+    maxItems     int
+    maxValueSize int
+    ll           *list.List
+    items        map[string]*list.Element
+    dirty        int
+    lost         uint64 // Pending writes evicted before reconciliation
+}
+
+func newLocalStore(maxItems, maxValueSize int) *localStore {
+    if maxItems <= 0 {
+        maxItems = 10000
+    }
+    return &localStore{
+        maxItems:     maxItems,
+        maxValueSize: maxValueSize,
+        ll:           list.New(),
+        items:        make(map[string]*list.Element),
+    }
+}
+
+// get returns a copy of the entry for key; expired clean entries are removed
+func (s *localStore) get(key string, dirtyOnly bool) (localEntry, bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if dirtyOnly && s.dirty == 0 {
+        return localEntry{}, false
+    }
+    el, ok := s.items[key]
+    if !ok {
+        return localEntry{}, false
+    }
+    entry := el.Value.(*localEntry)
+    if dirtyOnly && !entry.dirty {
+        return localEntry{}, false
+    }
+    if entry.expired(time.Now()) && !entry.dirty {
+        s.removeElement(el)
+        return localEntry{}, false
+    }
+    s.ll.MoveToFront(el)
+    return *entry, true
+}
+
+// remember stores a value read from or written to Redis, replacing any
+// pending write for the key
+func (s *localStore) remember(key string, data []byte, expiresAt time.Time) {
+    if s.maxValueSize > 0 && len(data) > s.maxValueSize {
+        s.forget(key)
+        return
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.put(&localEntry{key: key, data: data, expiresAt: expiresAt})
+}
+
+// refresh stores a value read from Redis unless a pending write exists
+func (s *localStore) refresh(key string, data []byte, expiresAt time.Time) {
+    s.mu.Lock()
+    if el, ok := s.items[key]; ok && el.Value.(*localEntry).dirty {
+        s.mu.Unlock()
+        return
+    }
+    s.mu.Unlock()
+
+    s.remember(key, data, expiresAt)
+}
+
+// forget removes the entry for key, including a pending write
+func (s *localStore) forget(key string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if el, ok := s.items[key]; ok {
+        s.removeElement(el)
+    }
+}
+
+// forgetClean removes the entry for key unless it holds a pending write
+func (s *localStore) forgetClean(key string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if el, ok := s.items[key]; ok && !el.Value.(*localEntry).dirty {
+        s.removeElement(el)
+    }
+}
+
+// write records a pending write (data nil for a delete) made while Redis was
+// unavailable. The value previously seen from Redis becomes the base used to
+// detect conflicting writes during reconciliation.
+func (s *localStore) write(key string, data []byte, expiresAt, now time.Time) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    entry := &localEntry{key: key, data: data, expiresAt: expiresAt, dirty: true, writtenAt: now}
+    if el, ok := s.items[key]; ok {
+        previous := el.Value.(*localEntry)
+        entry.baseHash, entry.baseKnown = previous.baseHash, previous.baseKnown
+        if !previous.dirty {
+            entry.baseHash, entry.baseKnown = hashValue(previous.data), true
+        }
+    }
+    s.put(entry)
+}
+
+// pending returns a copy of every pending write, oldest first
+func (s *localStore) pending() []localEntry {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    entries := make([]localEntry, 0, s.dirty)
+    for el := s.ll.Back(); el != nil; el = el.Prev() {
+        if entry := el.Value.(*localEntry); entry.dirty {
+            entries = append(entries, *entry)
+        }
+    }
+    return entries
+}
+
+// settle marks a pending write as reconciled, unless it was overwritten since
+func (s *localStore) settle(key string, writtenAt time.Time) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    el, ok := s.items[key]
+    if !ok {
+        return
+    }
+    entry := el.Value.(*localEntry)
+    if !entry.dirty || !entry.writtenAt.Equal(writtenAt) {
+        return
+    }
+    if entry.data == nil {
+        s.removeElement(el)
+        return
+    }
+    entry.dirty = false
+    s.dirty--
+}
+
+// clear drops every entry, pending writes included
+func (s *localStore) clear() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.ll.Init()
+    s.items = make(map[string]*list.Element)
+    s.dirty = 0
+}
+
+// counts returns the number of entries, pending writes and lost writes
+func (s *localStore) counts() (items, dirty int, lost uint64) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.ll.Len(), s.dirty, s.lost
+}
+
+// put inserts or replaces an entry and evicts the least recently used ones;
+// callers hold s.mu
+func (s *localStore) put(entry *localEntry) {
+    if el, ok := s.items[entry.key]; ok {
+        s.removeElement(el)
+    }
+    s.items[entry.key] = s.ll.PushFront(entry)
+    if entry.dirty {
+        s.dirty++
+    }
+
+    for s.ll.Len() > s.maxItems {
+        oldest := s.ll.Back()
+        if oldest.Value.(*localEntry).dirty {
+            s.lost++
+        }
+        s.removeElement(oldest)
+    }
+}
+
+// removeElement callers hold s.mu
+func (s *localStore) removeElement(el *list.Element) {
+    entry := s.ll.Remove(el).(*localEntry)
+    delete(s.items, entry.key)
+    if entry.dirty {
+        s.dirty--
+    }
+}
+
+// hashValue fingerprints a Redis value; nil (missing key) hashes to zero
+func hashValue(data []byte) uint64 {
+    if data == nil {
+        return 0
+    }
+    h := fnv.New64a()
+    h.Write(data)
+    return h.Sum64()
+}
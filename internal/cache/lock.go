@@ -0,0 +1,26 @@
+package cache
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "distributed-cache/pkg/models"
+)
+
+var (
+    // ErrLockHeld is returned when the lock is already held by another owner
+    ErrLockHeld = errors.New("lock is held by another owner")
+    // ErrLockNotOwned is returned when the lock is missing or held by someone else
+    ErrLockNotOwned = errors.New("lock is not held by this owner")
+)
+
+// Locker defines distributed lock operations with fencing tokens
+type Locker interface {
+    // AcquireLock takes the lock for owner and returns a new fencing token
+    AcquireLock(ctx context.Context, name, owner string, ttl time.Duration) (*models.Lock, error)
+    // RenewLock extends the lock TTL, keeping its fencing token
+    RenewLock(ctx context.Context, name, owner string, ttl time.Duration) (*models.Lock, error)
+    // ReleaseLock frees the lock only if it is held by owner
+    ReleaseLock(ctx context.Context, name, owner string) error
+}
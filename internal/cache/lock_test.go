@@ -0,0 +1,94 @@
+package cache
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRedisCache_AcquireLock(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    lock, err := cache.AcquireLock(ctx, "job", "owner-a", 1*time.Minute)
+    require.NoError(t, err)
+    assert.Equal(t, "owner-a", lock.Owner)
+    assert.Equal(t, int64(1), lock.Token)
+
+    // Otro propietario no puede tomar el lock
+    _, err = cache.AcquireLock(ctx, "job", "owner-b", 1*time.Minute)
+    assert.ErrorIs(t, err, ErrLockHeld)
+}
+
+func TestRedisCache_LockFencingToken(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    first, err := cache.AcquireLock(ctx, "fenced", "owner-a", 1*time.Minute)
+    require.NoError(t, err)
+    require.NoError(t, cache.ReleaseLock(ctx, "fenced", "owner-a"))
+
+    second, err := cache.AcquireLock(ctx, "fenced", "owner-b", 1*time.Minute)
+    require.NoError(t, err)
+    assert.Greater(t, second.Token, first.Token)
+
+    // La renovación conserva el token
+    renewed, err := cache.RenewLock(ctx, "fenced", "owner-b", 2*time.Minute)
+    require.NoError(t, err)
+    assert.Equal(t, second.Token, renewed.Token)
+}
+
+func TestRedisCache_ClearKeepsLocks(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    first, err := cache.AcquireLock(ctx, "job", "owner-a", 1*time.Minute)
+    require.NoError(t, err)
+    require.NoError(t, cache.Set(ctx, "job", "value", time.Minute))
+
+    // Clear borra las claves del caché pero conserva el lock y su contador de fencing
+    require.NoError(t, cache.Clear(ctx))
+    exists, err := cache.Exists(ctx, "job")
+    require.NoError(t, err)
+    assert.False(t, exists)
+
+    _, err = cache.AcquireLock(ctx, "job", "owner-b", 1*time.Minute)
+    assert.ErrorIs(t, err, ErrLockHeld)
+
+    require.NoError(t, cache.ReleaseLock(ctx, "job", "owner-a"))
+    second, err := cache.AcquireLock(ctx, "job", "owner-b", 1*time.Minute)
+    require.NoError(t, err)
+    assert.Greater(t, second.Token, first.Token)
+}
+
+func TestRedisCache_LockOwnership(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    _, err := cache.AcquireLock(ctx, "owned", "owner-a", 1*time.Minute)
+    require.NoError(t, err)
+
+    // Solo el propietario puede renovar o liberar
+    _, err = cache.RenewLock(ctx, "owned", "owner-b", 1*time.Minute)
+    assert.ErrorIs(t, err, ErrLockNotOwned)
+
+    err = cache.ReleaseLock(ctx, "owned", "owner-b")
+    assert.ErrorIs(t, err, ErrLockNotOwned)
+
+    err = cache.ReleaseLock(ctx, "owned", "owner-a")
+    assert.NoError(t, err)
+
+    err = cache.ReleaseLock(ctx, "owned", "owner-a")
+    assert.ErrorIs(t, err, ErrLockNotOwned)
+}
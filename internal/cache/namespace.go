@@ -0,0 +1,26 @@
+package cache
+
+import (
+    "strings"
+)
+
+// NamespaceSeparator separates the namespace from the rest of a key ("users:42")
+const NamespaceSeparator = ":"
+
+// InternalKeyPrefix prefixes the keys of the internal subsystems (locks,
+// sessions, idempotency records...). The generic key-value API refuses these
+// keys and never lists them, so clients cannot read or tamper with them.
+const InternalKeyPrefix = "_internal:"
+
+// IsInternalKey reports whether key belongs to an internal subsystem
+func IsInternalKey(key string) bool {
+    return strings.HasPrefix(key, InternalKeyPrefix)
+}
+
+// Namespace returns the namespace of a key, or an empty string if it has none
+func Namespace(key string) string {
+    if i := strings.Index(key, NamespaceSeparator); i > 0 {
+        return key[:i]
+    }
+    return ""
+}
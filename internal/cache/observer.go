@@ -0,0 +1,53 @@
+package cache
+
+import (
+    "errors"
+    "time"
+)
+
+// Key access kinds reported to observers
+const (
+    AccessHit    = "hit"
+    AccessMiss   = "miss"
+    AccessSet    = "set"
+    AccessDelete = "delete"
+    AccessEvict  = "evict" // Item found expired on read and removed
+)
+
+// KeyAccess describes how a cache operation touched a single key
+type KeyAccess struct {
+    Operation string
+    Key       string
+    Namespace string
+    Kind      string
+    Size      int // Encoded value size in bytes, zero when unknown
+}
+
+// Observer receives instrumentation events from cache operations.
+// Implementations must be safe for concurrent use.
+type Observer interface {
+    // ObserveOperation is called once per cache method call
+    ObserveOperation(operation, namespace string, failed bool, duration time.Duration)
+    // ObserveKeyAccess is called for every key read, written or deleted
+    ObserveKeyAccess(access KeyAccess)
+}
+
+// NopObserver ignores every event; embed it to implement part of Observer
+type NopObserver struct{}
+
+// ObserveOperation implements Observer
+func (NopObserver) ObserveOperation(operation, namespace string, failed bool, duration time.Duration) {}
+
+// ObserveKeyAccess implements Observer
+func (NopObserver) ObserveKeyAccess(access KeyAccess) {}
+
+// isOutcome reports whether err is an expected result rather than a failure
+func isOutcome(err error) bool {
+    var rejected *RejectedUpdateError
+    switch {
+    case err == ErrLockHeld, err == ErrLockNotOwned, err == ErrWrongType, err == ErrNotInteger, err == ErrUpdateConflict,
+        err == ErrIdempotencyNotOwned, err == ErrBloomExists, err == ErrBloomNotFound:
+        return true
+    }
+    return errors.As(err, &rejected) || errors.Is(err, ErrInvalidBloom)
+}
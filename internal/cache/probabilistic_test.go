@@ -0,0 +1,139 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRedisCache_HyperLogLog(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    var monday, tuesday []string
+    for i := 0; i < 1000; i++ {
+        monday = append(monday, fmt.Sprintf("visitor-%d", i))
+        tuesday = append(tuesday, fmt.Sprintf("visitor-%d", i+500))
+    }
+
+    changed, err := cache.PFAdd(ctx, "visitors:mon", monday, time.Hour)
+    require.NoError(t, err)
+    assert.True(t, changed)
+
+    _, err = cache.PFAdd(ctx, "visitors:tue", tuesday, 0)
+    require.NoError(t, err)
+
+    // Error estándar de 0.81%; se admite un 3%
+    count, err := cache.PFCount(ctx, "visitors:mon")
+    require.NoError(t, err)
+    assert.InDelta(t, 1000, count, 30)
+
+    count, err = cache.PFMerge(ctx, "visitors:week", []string{"visitors:mon", "visitors:tue"}, time.Hour)
+    require.NoError(t, err)
+    assert.InDelta(t, 1500, count, 45)
+
+    ttl, err := cache.client.TTL(ctx, "visitors:week").Result()
+    require.NoError(t, err)
+    assert.True(t, ttl > 0)
+
+    count, err = cache.PFCount(ctx, "visitors:missing")
+    require.NoError(t, err)
+    assert.Zero(t, count)
+
+    require.NoError(t, cache.client.RPush(ctx, "queue", "job").Err())
+    _, err = cache.PFAdd(ctx, "queue", []string{"a"}, 0)
+    assert.ErrorIs(t, err, ErrWrongType)
+}
+
+func TestRedisCache_BloomFilter(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    filter, err := cache.BloomReserve(ctx, "seen:orders", 1000, 0.01, time.Hour)
+    require.NoError(t, err)
+    assert.Equal(t, uint64(9586), filter.Bits)
+    assert.Equal(t, 7, filter.Hashes)
+
+    _, err = cache.BloomReserve(ctx, "seen:orders", 1000, 0.01, 0)
+    assert.ErrorIs(t, err, ErrBloomExists)
+    _, err = cache.BloomReserve(ctx, "seen:other", 1000, 1.5, 0)
+    assert.ErrorIs(t, err, ErrInvalidBloom)
+    _, err = cache.BloomReserve(ctx, "seen:other", 1<<40, 0.0001, 0)
+    assert.ErrorIs(t, err, ErrInvalidBloom)
+
+    var items []string
+    for i := 0; i < 1000; i++ {
+        items = append(items, fmt.Sprintf("order-%d", i))
+    }
+    added, err := cache.BloomAdd(ctx, "seen:orders", items)
+    require.NoError(t, err)
+    require.Len(t, added, 1000)
+    assert.True(t, added[0])
+
+    // Sin falsos negativos
+    found, err := cache.BloomExists(ctx, "seen:orders", items)
+    require.NoError(t, err)
+    for i, present := range found {
+        assert.True(t, present, items[i])
+    }
+
+    added, err = cache.BloomAdd(ctx, "seen:orders", items[:1])
+    require.NoError(t, err)
+    assert.Equal(t, []bool{false}, added)
+
+    // Las claves del filtro son internas y no se ven desde la API de caché
+    keys, err := cache.Keys(ctx, "*")
+    require.NoError(t, err)
+    assert.Empty(t, keys)
+
+    // Los scripts rechazan offsets calculados para otro tamaño del filtro
+    err = addBloomScript.Run(ctx, cache.client, bloomKeys("seen:orders"), bloomOffsets(items[:1], 4096, 7)...).Err()
+    assert.True(t, isStaleBloomSize(err), err)
+
+    // Falsos positivos cerca del 1% con el filtro lleno
+    var unseen []string
+    for i := 0; i < 10000; i++ {
+        unseen = append(unseen, fmt.Sprintf("other-%d", i))
+    }
+    found, err = cache.BloomExists(ctx, "seen:orders", unseen)
+    require.NoError(t, err)
+    falsePositives := 0
+    for _, present := range found {
+        if present {
+            falsePositives++
+        }
+    }
+    assert.Less(t, falsePositives, 200)
+
+    // El bitmap caduca con el filtro
+    ttl, err := cache.client.PTTL(ctx, bloomKeys("seen:orders")[1]).Result()
+    require.NoError(t, err)
+    assert.True(t, ttl > 0)
+
+    info, err := cache.BloomInfo(ctx, "seen:orders")
+    require.NoError(t, err)
+    require.NotNil(t, info)
+    assert.Equal(t, int64(1000), info.Capacity)
+    assert.Equal(t, 0.01, info.ErrorRate)
+    assert.InDelta(t, 1000, info.Items, 10)
+
+    deleted, err := cache.BloomDelete(ctx, "seen:orders")
+    require.NoError(t, err)
+    assert.True(t, deleted)
+
+    _, err = cache.BloomAdd(ctx, "seen:orders", items[:1])
+    assert.ErrorIs(t, err, ErrBloomNotFound)
+    _, err = cache.BloomExists(ctx, "seen:orders", items[:1])
+    assert.ErrorIs(t, err, ErrBloomNotFound)
+    info, err = cache.BloomInfo(ctx, "seen:orders")
+    require.NoError(t, err)
+    assert.Nil(t, info)
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+    "context"
+    "errors"
+
+    "distributed-cache/pkg/models"
+)
+
+// ErrSlowConsumer is returned by Subscription.Err when the subscriber did
+// not keep up and its buffer overflowed
+var ErrSlowConsumer = errors.New("subscriber too slow, messages dropped")
+
+// PubSub defines publish/subscribe messaging. Messages are fire-and-forget:
+// they reach the subscribers connected when they are published.
+type PubSub interface {
+    // Publish sends message to channel and returns the number of Redis
+    // subscriptions that received it
+    Publish(ctx context.Context, channel string, message interface{}) (int64, error)
+    // Subscribe listens on the channels and the glob-style patterns,
+    // buffering up to bufferSize messages not yet read
+    Subscribe(ctx context.Context, channels, patterns []string, bufferSize int) (Subscription, error)
+}
+
+// Subscription receives the messages of a Subscribe call
+type Subscription interface {
+    // Messages returns the received messages; it is closed when the
+    // subscription ends
+    Messages() <-chan *models.PubSubMessage
+    // Err returns why the subscription ended, nil if it was closed
+    Err() error
+    // Close ends the subscription
+    Close() error
+}
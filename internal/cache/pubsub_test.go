@@ -0,0 +1,94 @@
+package cache
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "distributed-cache/pkg/models"
+)
+
+// receive waits for the next message of sub
+func receive(t *testing.T, sub Subscription) *models.PubSubMessage {
+    t.Helper()
+    select {
+    case msg, ok := <-sub.Messages():
+        require.True(t, ok, "subscription ended: %v", sub.Err())
+        return msg
+    case <-time.After(2 * time.Second):
+        t.Fatal("no message received")
+        return nil
+    }
+}
+
+func TestRedisCache_PubSub(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    sub, err := cache.Subscribe(ctx, []string{"orders"}, []string{"news.*"}, 10)
+    require.NoError(t, err)
+    defer sub.Close()
+
+    receivers, err := cache.Publish(ctx, "orders", map[string]interface{}{"id": float64(42)})
+    require.NoError(t, err)
+    assert.Equal(t, int64(1), receivers)
+
+    msg := receive(t, sub)
+    assert.Equal(t, "orders", msg.Channel)
+    assert.Empty(t, msg.Pattern)
+    assert.Equal(t, map[string]interface{}{"id": float64(42)}, msg.Payload)
+
+    _, err = cache.Publish(ctx, "news.sports", "goal")
+    require.NoError(t, err)
+    msg = receive(t, sub)
+    assert.Equal(t, "news.sports", msg.Channel)
+    assert.Equal(t, "news.*", msg.Pattern)
+    assert.Equal(t, "goal", msg.Payload)
+
+    // Los mensajes que no son JSON se entregan como texto
+    require.NoError(t, cache.client.Publish(ctx, "orders", "plain text").Err())
+    msg = receive(t, sub)
+    assert.Equal(t, "plain text", msg.Payload)
+
+    receivers, err = cache.Publish(ctx, "nobody", "hello")
+    require.NoError(t, err)
+    assert.Zero(t, receivers)
+
+    require.NoError(t, sub.Close())
+    _, ok := <-sub.Messages()
+    assert.False(t, ok)
+    assert.NoError(t, sub.Err())
+
+    _, err = cache.Subscribe(ctx, nil, nil, 10)
+    assert.Error(t, err)
+}
+
+func TestRedisCache_PubSubSlowConsumer(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    sub, err := cache.Subscribe(ctx, []string{"events"}, nil, 2)
+    require.NoError(t, err)
+    defer sub.Close()
+
+    // Nadie lee: el tercer mensaje desborda el buffer
+    for i := 0; i < 3; i++ {
+        _, err := cache.Publish(ctx, "events", i)
+        require.NoError(t, err)
+    }
+    require.Eventually(t, func() bool { return sub.Err() != nil }, 2*time.Second, 10*time.Millisecond)
+
+    var received int
+    for range sub.Messages() {
+        received++
+    }
+    assert.Equal(t, 2, received)
+    assert.ErrorIs(t, sub.Err(), ErrSlowConsumer)
+}
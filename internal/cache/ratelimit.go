@@ -0,0 +1,40 @@
+package cache
+
+import (
+    "context"
+    "time"
+)
+
+// Supported rate limiting algorithms
+const (
+    AlgorithmTokenBucket   = "token_bucket"
+    AlgorithmSlidingWindow = "sliding_window"
+)
+
+// RateLimit describes a rate limit rule
+type RateLimit struct {
+    Algorithm string        `mapstructure:"algorithm"`
+    Limit     int64         `mapstructure:"limit"`  // Requests allowed per window
+    Window    time.Duration `mapstructure:"window"`
+    Burst     int64         `mapstructure:"burst"`  // Token bucket capacity, defaults to Limit
+}
+
+// Enabled reports whether the rule limits anything
+func (rl RateLimit) Enabled() bool {
+    return rl.Limit > 0 && rl.Window > 0
+}
+
+// RateLimitResult is the outcome of taking tokens from a bucket
+type RateLimitResult struct {
+    Allowed    bool
+    Limit      int64
+    Remaining  int64
+    RetryAfter time.Duration // Time until the request could succeed, zero if allowed
+    ResetAfter time.Duration // Time until the bucket is full again
+}
+
+// RateLimiter defines distributed rate limiting operations
+type RateLimiter interface {
+    // Take consumes n tokens from the bucket according to the rule
+    Take(ctx context.Context, bucket string, limit RateLimit, n int64) (*RateLimitResult, error)
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRedisCache_TakeTokenBucket(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+    limit := RateLimit{Algorithm: AlgorithmTokenBucket, Limit: 3, Window: 1 * time.Minute}
+
+    // Se permiten tantas peticiones como la capacidad del bucket
+    for i := 0; i < 3; i++ {
+        result, err := cache.Take(ctx, "bucket", limit, 1)
+        require.NoError(t, err)
+        assert.True(t, result.Allowed)
+        assert.Equal(t, int64(2-i), result.Remaining)
+    }
+
+    result, err := cache.Take(ctx, "bucket", limit, 1)
+    require.NoError(t, err)
+    assert.False(t, result.Allowed)
+    assert.True(t, result.RetryAfter > 0)
+}
+
+func TestRedisCache_TakeSlidingWindow(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+    limit := RateLimit{Algorithm: AlgorithmSlidingWindow, Limit: 2, Window: 1 * time.Minute}
+
+    result, err := cache.Take(ctx, "window", limit, 2)
+    require.NoError(t, err)
+    assert.True(t, result.Allowed)
+    assert.Equal(t, int64(0), result.Remaining)
+
+    result, err = cache.Take(ctx, "window", limit, 1)
+    require.NoError(t, err)
+    assert.False(t, result.Allowed)
+    // La ventana anterior pesa en la siguiente, así que la espera puede superar la ventana
+    assert.True(t, result.RetryAfter > 0 && result.RetryAfter <= 3*time.Minute/2)
+
+    // Los buckets son independientes
+    result, err = cache.Take(ctx, "other", limit, 1)
+    require.NoError(t, err)
+    assert.True(t, result.Allowed)
+}
+
+func TestRedisCache_TakeSlidingWindowManyTokens(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+    limit := RateLimit{Algorithm: AlgorithmSlidingWindow, Limit: 100000000, Window: 1 * time.Hour}
+
+    // Los contadores no crecen con el número de tokens
+    result, err := cache.Take(ctx, "bulk", limit, 99999500)
+    require.NoError(t, err)
+    assert.True(t, result.Allowed)
+    assert.Equal(t, int64(500), result.Remaining)
+
+    result, err = cache.Take(ctx, "bulk", limit, 501)
+    require.NoError(t, err)
+    assert.False(t, result.Allowed)
+}
+
+func TestRedisCache_TakeSlidingWindowWeightsPreviousWindow(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+    limit := RateLimit{Algorithm: AlgorithmSlidingWindow, Limit: 10, Window: 100 * time.Millisecond}
+
+    result, err := cache.Take(ctx, "window", limit, 10)
+    require.NoError(t, err)
+    require.True(t, result.Allowed)
+
+    // Dos ventanas después el contador anterior ya no cuenta
+    time.Sleep(200 * time.Millisecond)
+    result, err = cache.Take(ctx, "window", limit, 10)
+    require.NoError(t, err)
+    assert.True(t, result.Allowed)
+}
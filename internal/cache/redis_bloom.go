@@ -0,0 +1,310 @@
+package cache
+
+import (
+    "context"
+    "encoding/binary"
+    "fmt"
+    "hash/fnv"
+    "math"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+
+    "distributed-cache/pkg/models"
+)
+
+// maxBloomBits is the size of the largest Redis string, 512MB
+const maxBloomBits = 1 << 32
+
+// reserveBloomScript creates the filter metadata unless it exists. The
+// bitmap is created by the first add.
+// KEYS[1] = metadata hash, KEYS[2] = bitmap
+// ARGV[1] = capacity, ARGV[2] = error rate, ARGV[3] = bits, ARGV[4] = hashes,
+// ARGV[5] = now in milliseconds, ARGV[6] = ttl in milliseconds (0 = no expiration)
+var reserveBloomScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+    return 0
+end
+redis.call('DEL', KEYS[2])
+redis.call('HSET', KEYS[1], 'capacity', ARGV[1], 'error_rate', ARGV[2], 'bits', ARGV[3],
+    'hashes', ARGV[4], 'items', 0, 'created_at', ARGV[5])
+if tonumber(ARGV[6]) > 0 then
+    redis.call('PEXPIRE', KEYS[1], ARGV[6])
+end
+return 1
+`)
+
+// checkBloomSize is the prologue of the item scripts: it returns nil if the
+// filter does not exist, and an error if it was recreated with another size
+// after the offsets were computed
+const checkBloomSize = `
+local size = redis.call('HMGET', KEYS[1], 'bits', 'hashes')
+if not size[1] then
+    return false
+end
+if size[1] ~= ARGV[1] or size[2] ~= ARGV[2] then
+    return redis.error_reply('STALE bloom filter size changed')
+end
+local k = tonumber(ARGV[2])
+`
+
+// addBloomScript sets the bits of every item and returns, per item, 1 if
+// any of its bits was unset. The bitmap expires with the metadata.
+// KEYS[1] = metadata hash, KEYS[2] = bitmap
+// ARGV[1] = expected bits, ARGV[2] = hashes per item, followed by the bit
+// offsets of every item
+var addBloomScript = redis.NewScript(checkBloomSize + `
+local added = {}
+local items = 0
+for i = 3, #ARGV, k do
+    local new = 0
+    for j = i, i + k - 1 do
+        if redis.call('SETBIT', KEYS[2], ARGV[j], 1) == 0 then
+            new = 1
+        end
+    end
+    added[#added + 1] = new
+    items = items + new
+end
+redis.call('HINCRBY', KEYS[1], 'items', items)
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl > 0 then
+    redis.call('PEXPIRE', KEYS[2], ttl)
+end
+return added
+`)
+
+// existsBloomScript returns, per item, 1 if all of its bits are set
+// KEYS[1] = metadata hash, KEYS[2] = bitmap
+// ARGV[1] = expected bits, ARGV[2] = hashes per item, followed by the bit
+// offsets of every item
+var existsBloomScript = redis.NewScript(checkBloomSize + `
+local found = {}
+for i = 3, #ARGV, k do
+    local present = 1
+    for j = i, i + k - 1 do
+        if redis.call('GETBIT', KEYS[2], ARGV[j]) == 0 then
+            present = 0
+            break
+        end
+    end
+    found[#found + 1] = present
+end
+return found
+`)
+
+// bloomKeys returns the metadata and bitmap keys of a filter. They are
+// internal so the cache API cannot change the size the scripts rely on, and
+// the hash tag keeps both in the same cluster slot.
+func bloomKeys(key string) []string {
+    prefix := InternalKeyPrefix + "bloom:{" + key + "}"
+    return []string{prefix, prefix + ":bits"}
+}
+
+// BloomSize returns the optimal number of bits and hash functions for
+// capacity items at errorRate
+func BloomSize(capacity int64, errorRate float64) (uint64, int, error) {
+    if capacity <= 0 || errorRate <= 0 || errorRate >= 1 {
+        return 0, 0, fmt.Errorf("%w: capacity must be positive and error rate between 0 and 1", ErrInvalidBloom)
+    }
+
+    bits := math.Ceil(-float64(capacity) * math.Log(errorRate) / (math.Ln2 * math.Ln2))
+    if bits > maxBloomBits {
+        return 0, 0, fmt.Errorf("%w: filter needs %.0f bits, more than the %d a Redis string holds", ErrInvalidBloom, bits, uint64(maxBloomBits))
+    }
+    hashes := int(math.Max(1, math.Round(bits/float64(capacity)*math.Ln2)))
+    return uint64(bits), hashes, nil
+}
+
+// bloomOffsets returns the filter size followed by the bit offsets of every
+// item, derived from two halves of a 128-bit FNV-1a hash (Kirsch-Mitzenmacher
+// double hashing)
+func bloomOffsets(items []string, bits uint64, hashes int) []interface{} {
+    offsets := make([]interface{}, 0, 2+len(items)*hashes)
+    offsets = append(offsets, bits, hashes)
+    for _, item := range items {
+        hash := fnv.New128a()
+        hash.Write([]byte(item))
+        sum := hash.Sum(nil)
+        h1 := binary.BigEndian.Uint64(sum[:8])
+        h2 := binary.BigEndian.Uint64(sum[8:]) | 1
+        for i := 0; i < hashes; i++ {
+            offsets = append(offsets, (h1+uint64(i)*h2)%bits)
+        }
+    }
+    return offsets
+}
+
+// BloomReserve creates a filter sized for capacity items at errorRate
+func (rc *RedisCache) BloomReserve(ctx context.Context, key string, capacity int64, errorRate float64, ttl time.Duration) (_ *models.BloomFilter, err error) {
+    ctx, op := rc.startOperation(ctx, "bloom_reserve", key)
+    defer op.end(&err)
+
+    bits, hashes, err := BloomSize(capacity, errorRate)
+    if err != nil {
+        return nil, err
+    }
+
+    now := time.Now()
+    created, err := reserveBloomScript.Run(ctx, rc.client, bloomKeys(key),
+        capacity, errorRate, bits, hashes, now.UnixMilli(), ttl.Milliseconds()).Int64()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to reserve bloom filter", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to reserve bloom filter: %w", err)
+    }
+    if created == 0 {
+        return nil, ErrBloomExists
+    }
+
+    op.access(key, AccessSet, 0)
+    op.logger.Debug("bloom filter reserved",
+        zap.String("key", key),
+        zap.Uint64("bits", bits),
+        zap.Int("hashes", hashes))
+
+    return &models.BloomFilter{
+        Key:       key,
+        Capacity:  capacity,
+        ErrorRate: errorRate,
+        Bits:      bits,
+        Hashes:    hashes,
+        CreatedAt: time.UnixMilli(now.UnixMilli()),
+    }, nil
+}
+
+// BloomAdd adds items and reports, for each one, whether it was new
+func (rc *RedisCache) BloomAdd(ctx context.Context, key string, items []string) (_ []bool, err error) {
+    ctx, op := rc.startOperation(ctx, "bloom_add", key)
+    defer op.end(&err)
+    op.batch(len(items))
+
+    added, err := rc.runBloomScript(ctx, op, addBloomScript, key, items)
+    if err != nil {
+        return nil, err
+    }
+    op.access(key, AccessSet, 0)
+    return added, nil
+}
+
+// BloomExists reports, for each item, whether it may have been added
+func (rc *RedisCache) BloomExists(ctx context.Context, key string, items []string) (_ []bool, err error) {
+    ctx, op := rc.startOperation(ctx, "bloom_exists", key)
+    defer op.end(&err)
+    op.batch(len(items))
+
+    found, err := rc.runBloomScript(ctx, op, existsBloomScript, key, items)
+    if err != nil {
+        return nil, err
+    }
+    op.access(key, AccessHit, 0)
+    return found, nil
+}
+
+// runBloomScript runs a script over the bit offsets of items, which depend
+// on the size of the filter. The script checks the size it was computed for,
+// and the offsets are computed again if the filter was recreated meanwhile.
+func (rc *RedisCache) runBloomScript(ctx context.Context, op *operation, script *redis.Script, key string, items []string) ([]bool, error) {
+    keys := bloomKeys(key)
+
+    var result []int64
+    for attempt := 0; ; attempt++ {
+        size, err := rc.client.HMGet(ctx, keys[0], "bits", "hashes").Result()
+        if err = dataTypeError(err); err != nil {
+            if isOutcome(err) {
+                return nil, err
+            }
+            op.logger.Error("failed to read bloom filter", zap.Error(err), zap.String("key", key))
+            return nil, fmt.Errorf("failed to read bloom filter: %w", err)
+        }
+
+        bitsField, _ := size[0].(string)
+        hashesField, _ := size[1].(string)
+        bits, _ := strconv.ParseUint(bitsField, 10, 64)
+        hashes, _ := strconv.Atoi(hashesField)
+        if bits == 0 || hashes == 0 {
+            op.access(key, AccessMiss, 0)
+            return nil, ErrBloomNotFound
+        }
+
+        result, err = script.Run(ctx, rc.client, keys, bloomOffsets(items, bits, hashes)...).Int64Slice()
+        if err == redis.Nil {
+            // Deleted between both calls
+            return nil, ErrBloomNotFound
+        }
+        if isStaleBloomSize(err) && attempt < maxUpdateRetries {
+            continue
+        }
+        if err = dataTypeError(err); err != nil {
+            if isOutcome(err) {
+                return nil, err
+            }
+            op.logger.Error("failed to run bloom filter script", zap.Error(err), zap.String("key", key))
+            return nil, fmt.Errorf("failed to run bloom filter script: %w", err)
+        }
+        break
+    }
+
+    flags := make([]bool, len(result))
+    for i, flag := range result {
+        flags[i] = flag == 1
+    }
+    return flags, nil
+}
+
+// isStaleBloomSize reports whether a bloom script found the filter recreated
+// with another size
+func isStaleBloomSize(err error) bool {
+    return isRedisReply(err) && strings.HasPrefix(err.Error(), "STALE ")
+}
+
+// BloomInfo returns the filter, nil if it does not exist
+func (rc *RedisCache) BloomInfo(ctx context.Context, key string) (_ *models.BloomFilter, err error) {
+    ctx, op := rc.startOperation(ctx, "bloom_info", key)
+    defer op.end(&err)
+
+    fields, err := rc.client.HGetAll(ctx, bloomKeys(key)[0]).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to get bloom filter", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to get bloom filter: %w", err)
+    }
+    if len(fields) == 0 {
+        op.access(key, AccessMiss, 0)
+        return nil, nil
+    }
+
+    filter := &models.BloomFilter{Key: key}
+    filter.Capacity, _ = strconv.ParseInt(fields["capacity"], 10, 64)
+    filter.ErrorRate, _ = strconv.ParseFloat(fields["error_rate"], 64)
+    filter.Bits, _ = strconv.ParseUint(fields["bits"], 10, 64)
+    filter.Hashes, _ = strconv.Atoi(fields["hashes"])
+    filter.Items, _ = strconv.ParseInt(fields["items"], 10, 64)
+    createdAt, _ := strconv.ParseInt(fields["created_at"], 10, 64)
+    filter.CreatedAt = time.UnixMilli(createdAt)
+
+    op.access(key, AccessHit, 0)
+    return filter, nil
+}
+
+// BloomDelete removes the filter and reports whether it existed
+func (rc *RedisCache) BloomDelete(ctx context.Context, key string) (_ bool, err error) {
+    ctx, op := rc.startOperation(ctx, "bloom_delete", key)
+    defer op.end(&err)
+
+    deleted, err := rc.client.Del(ctx, bloomKeys(key)...).Result()
+    if err != nil {
+        op.logger.Error("failed to delete bloom filter", zap.Error(err), zap.String("key", key))
+        return false, fmt.Errorf("failed to delete bloom filter: %w", err)
+    }
+
+    op.access(key, AccessDelete, 0)
+    return deleted > 0, nil
+}
@@ -2,22 +2,44 @@ package cache
 
 import (
     "context"
-    "encoding/json"
+    "errors"
     "fmt"
-    "strings"
+    "sync"
+    "sync/atomic"
     "time"
 
     "github.com/go-redis/redis/v8"
     "go.uber.org/zap"
 
+    "distributed-cache/internal/logging"
+    "distributed-cache/internal/tlsutil"
     "distributed-cache/pkg/models"
 )
 
 // RedisCache implements the Cache interface using Redis
 type RedisCache struct {
-    client redis.UniversalClient
-    logger *zap.Logger
-    config *CacheConfig
+    client    redis.UniversalClient
+    logger    *zap.Logger
+    config    *CacheConfig
+    observers []Observer
+    stats     *statsCollector
+
+    breaker       *circuitBreaker // nil when disabled
+    writes        *writeBuffer
+    droppedWrites atomic.Uint64
+
+    local       *localStore // nil when the fallback is disabled
+    localHits   atomic.Uint64
+    reconciled  atomic.Uint64
+    conflicts   atomic.Uint64
+    reconciling sync.Mutex
+    done        chan struct{}
+
+    queue        *writeQueue // nil when write-behind is disabled
+    flushMu      sync.Mutex  // Serializes flushes with deletes of queued keys
+    flushed      atomic.Uint64
+    writeThrough atomic.Uint64
+    flushErrors  atomic.Uint64
 }
 
 // NewRedisCache creates a new instance of RedisCache
@@ -40,40 +62,145 @@ func NewRedisCache(config *CacheConfig, logger *zap.Logger) (*RedisCache, error)
         PoolTimeout:  config.PoolTimeout,
     }
 
+    switch config.Breaker.WritePolicy {
+    case "", WritePolicyFail, WritePolicyDrop, WritePolicyBuffer:
+    default:
+        return nil, fmt.Errorf("unknown circuit breaker write policy: %q", config.Breaker.WritePolicy)
+    }
+
+    if config.TLS.Enabled {
+        tlsConfig, err := tlsutil.ClientConfig(tlsutil.ClientOptions{
+            CAFile:             config.TLS.CAFile,
+            CertFile:           config.TLS.CertFile,
+            KeyFile:            config.TLS.KeyFile,
+            ServerName:         config.TLS.ServerName,
+            InsecureSkipVerify: config.TLS.InsecureSkipVerify,
+            ReloadInterval:     config.TLS.ReloadInterval,
+        }, logger)
+        if err != nil {
+            return nil, fmt.Errorf("failed to configure Redis TLS: %w", err)
+        }
+        options.TLSConfig = tlsConfig
+    }
+
     client := redis.NewUniversalClient(options)
 
+    // The breaker goes first so rejected commands never reach the other hooks
+    var breaker *circuitBreaker
+    if config.Breaker.Enabled {
+        breaker = newCircuitBreaker(config.Breaker, logger)
+        client.AddHook(breaker)
+    }
+    client.AddHook(tracingHook{})
+
     // Check connection
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
     defer cancel()
 
     if err := client.Ping(ctx).Err(); err != nil {
-        return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+        if !config.StartDegraded {
+            return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+        }
+        // go-redis dials on demand, so the connection is retried by later commands
+        logger.Warn("Redis unavailable, starting in degraded mode", zap.Error(err))
+        if breaker != nil {
+            breaker.trip()
+        }
     }
 
-    return &RedisCache{
-        client: client,
-        logger: logger,
-        config: config,
-    }, nil
+    stats := newStatsCollector(config.Stats)
+    rc := &RedisCache{
+        client:    client,
+        logger:    logger,
+        config:    config,
+        observers: []Observer{stats},
+        stats:     stats,
+        breaker:   breaker,
+        writes:    newWriteBuffer(config.Breaker.BufferSize),
+        done:      make(chan struct{}),
+    }
+    if config.Fallback.Enabled {
+        rc.local = newLocalStore(config.Fallback.MaxItems, config.Fallback.MaxValueSize)
+        interval := config.Fallback.ReconcileInterval
+        if interval <= 0 {
+            interval = 5 * time.Second
+        }
+        go rc.runReconciler(interval)
+    }
+    if config.WriteBehind.Enabled {
+        rc.queue = newWriteQueue(config.WriteBehind)
+        interval := config.WriteBehind.FlushInterval
+        if interval <= 0 {
+            interval = 50 * time.Millisecond
+        }
+        go rc.runFlusher(interval)
+    }
+    if breaker != nil {
+        if config.Breaker.WritePolicy == WritePolicyBuffer {
+            breaker.onRecover = rc.replayWrites
+        }
+        breaker.onClose = func() {
+            // Writes rejected just before the circuit closed may still land
+            // in the buffer after the recovery replay
+            if config.Breaker.WritePolicy == WritePolicyBuffer {
+                rc.replayWrites(context.Background())
+            }
+            if rc.local != nil {
+                rc.reconcile()
+            }
+        }
+    }
+    return rc, nil
+}
+
+// AddObserver registers an observer for cache instrumentation events.
+// It must be called before the cache is used concurrently.
+func (rc *RedisCache) AddObserver(observer Observer) {
+    rc.observers = append(rc.observers, observer)
 }
 
 // Set stores an item in the cache
-func (rc *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+func (rc *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) (err error) {
+    ctx, op := rc.startOperation(ctx, "set", key)
+    defer op.end(&err)
+
     cacheItem := models.NewCacheItem(key, value, ttl)
 
-    data, err := json.Marshal(cacheItem)
+    data, err := op.encode(cacheItem)
     if err != nil {
-        rc.logger.Error("failed to marshal cache item", zap.Error(err), zap.String("key", key))
+        op.logger.Error("failed to marshal cache item", zap.Error(err), zap.String("key", key))
         return fmt.Errorf("failed to marshal cache item: %w", err)
     }
 
+    if rc.queue != nil {
+        if rejected := rc.queue.push(newQueuedWrite(key, data, ttl, time.Now())); len(rejected) == 0 {
+            op.access(key, AccessSet, len(data))
+            return nil
+        }
+        // Queue full: write through so callers slow down instead of losing data
+        rc.writeThrough.Add(1)
+    }
+
     err = rc.client.Set(ctx, key, data, ttl).Err()
+    if rc.local != nil && unavailable(err) {
+        op.logger.Debug("redis unavailable, cache item kept locally", zap.String("key", key), zap.Error(err))
+        rc.localWrite(op, key, data, ttl)
+        return nil
+    }
+    if errors.Is(err, ErrCircuitOpen) {
+        return rc.degradeWrite(op, err, rc.bufferedSet(key, data, ttl))
+    }
     if err != nil {
-        rc.logger.Error("failed to set cache item", zap.Error(err), zap.String("key", key))
+        op.logger.Error("failed to set cache item", zap.Error(err), zap.String("key", key))
         return fmt.Errorf("failed to set cache item: %w", err)
     }
 
-    rc.logger.Debug("cache item set successfully", 
+    if rc.local != nil {
+        rc.local.remember(key, data, expiresAt(ttl, time.Now()))
+    }
+
+    op.access(key, AccessSet, len(data))
+    op.logger.Debug("cache item set successfully", 
         zap.String("key", key), 
         zap.Duration("ttl", ttl))
 
@@ -81,120 +208,443 @@ func (rc *RedisCache) Set(ctx context.Context, key string, value interface{}, tt
 }
 
 // Get retrieves an item from the cache
-func (rc *RedisCache) Get(ctx context.Context, key string) (*models.CacheItem, error) {
+func (rc *RedisCache) Get(ctx context.Context, key string) (_ *models.CacheItem, err error) {
+    ctx, op := rc.startOperation(ctx, "get", key)
+    defer op.end(&err)
+
+    // Queued writes and writes made locally during an outage are newer than Redis
+    if item, found := rc.queuedRead(op, key); found {
+        return item, nil
+    }
+    if item, found := rc.localRead(op, key, true); found {
+        return item, nil
+    }
+
     data, err := rc.client.Get(ctx, key).Result()
     if err != nil {
         if err == redis.Nil {
+            if rc.local != nil {
+                rc.local.forgetClean(key)
+            }
+            op.access(key, AccessMiss, 0)
             return nil, nil // Cache miss
         }
-        rc.logger.Error("failed to get cache item", zap.Error(err), zap.String("key", key))
+        if unavailable(err) {
+            if item, found := rc.localRead(op, key, false); found {
+                op.logger.Debug("redis unavailable, cache item served locally", zap.String("key", key))
+                return item, nil
+            }
+        }
+        if rc.missOnOpen(op, err) {
+            return nil, nil
+        }
+        op.logger.Error("failed to get cache item", zap.Error(err), zap.String("key", key))
         return nil, fmt.Errorf("failed to get cache item: %w", err)
     }
 
     var cacheItem models.CacheItem
-    if err := json.Unmarshal([]byte(data), &cacheItem); err != nil {
-        rc.logger.Error("failed to unmarshal cache item", zap.Error(err), zap.String("key", key))
+    if err := op.decode([]byte(data), &cacheItem); err != nil {
+        op.logger.Error("failed to unmarshal cache item", zap.Error(err), zap.String("key", key))
         return nil, fmt.Errorf("failed to unmarshal cache item: %w", err)
     }
 
     // Check if expired (double check)
     if cacheItem.IsExpired() {
-        rc.logger.Debug("cache item expired, removing", zap.String("key", key))
-    _ = rc.Delete(ctx, key) // Clean up expired item
+        op.logger.Debug("cache item expired, removing", zap.String("key", key))
+        _ = rc.client.Del(ctx, key).Err() // Clean up expired item
+        op.access(key, AccessEvict, 0)
+        op.access(key, AccessMiss, 0)
         return nil, nil
     }
 
-    rc.logger.Debug("cache item retrieved successfully", zap.String("key", key))
+    if rc.local != nil {
+        rc.local.refresh(key, []byte(data), cacheItem.ExpiresAt)
+    }
+    op.access(key, AccessHit, len(data))
+    op.logger.Debug("cache item retrieved successfully", zap.String("key", key))
     return &cacheItem, nil
 }
 
 // Delete removes an item from the cache
-func (rc *RedisCache) Delete(ctx context.Context, key string) error {
-    err := rc.client.Del(ctx, key).Err()
+func (rc *RedisCache) Delete(ctx context.Context, key string) (err error) {
+    ctx, op := rc.startOperation(ctx, "delete", key)
+    defer op.end(&err)
+
+    if rc.queue != nil {
+        // Holding flushMu keeps an in-flight flush from writing the key after the DEL
+        rc.flushMu.Lock()
+        defer rc.flushMu.Unlock()
+        rc.queue.remove(key)
+    }
+
+    err = rc.client.Del(ctx, key).Err()
+    if rc.local != nil && unavailable(err) {
+        op.logger.Debug("redis unavailable, delete kept locally", zap.String("key", key), zap.Error(err))
+        rc.localWrite(op, key, nil, 0)
+        return nil
+    }
+    if errors.Is(err, ErrCircuitOpen) {
+        return rc.degradeWrite(op, err, rc.bufferedDelete(key))
+    }
     if err != nil {
-        rc.logger.Error("failed to delete cache item", zap.Error(err), zap.String("key", key))
+        op.logger.Error("failed to delete cache item", zap.Error(err), zap.String("key", key))
         return fmt.Errorf("failed to delete cache item: %w", err)
     }
 
-    rc.logger.Debug("cache item deleted successfully", zap.String("key", key))
+    if rc.local != nil {
+        rc.local.forget(key)
+    }
+
+    op.access(key, AccessDelete, 0)
+    op.logger.Debug("cache item deleted successfully", zap.String("key", key))
     return nil
 }
 
 // Exists checks if a key exists in the cache
-func (rc *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
+func (rc *RedisCache) Exists(ctx context.Context, key string) (_ bool, err error) {
+    ctx, op := rc.startOperation(ctx, "exists", key)
+    defer op.end(&err)
+
+    if item, found := rc.queuedRead(op, key); found {
+        return item != nil, nil
+    }
+    if item, found := rc.localRead(op, key, true); found {
+        return item != nil, nil
+    }
+
     count, err := rc.client.Exists(ctx, key).Result()
+    if unavailable(err) {
+        if item, found := rc.localRead(op, key, false); found {
+            return item != nil, nil
+        }
+    }
+    if rc.missOnOpen(op, err) {
+        return false, nil
+    }
     if err != nil {
-        rc.logger.Error("failed to check cache item existence", zap.Error(err), zap.String("key", key))
+        op.logger.Error("failed to check cache item existence", zap.Error(err), zap.String("key", key))
         return false, fmt.Errorf("failed to check cache item existence: %w", err)
     }
 
     return count > 0, nil
 }
 
+// SetNX stores an item only if the key does not exist and reports whether it did
+func (rc *RedisCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+    return rc.setIf(ctx, "set_nx", key, value, ttl, rc.client.SetNX)
+}
+
+// SetXX stores an item only if the key exists and reports whether it did
+func (rc *RedisCache) SetXX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+    return rc.setIf(ctx, "set_xx", key, value, ttl, rc.client.SetXX)
+}
+
+// setIf runs a conditional SET. The condition is checked by Redis, so the
+// write is never queued, buffered or kept locally.
+func (rc *RedisCache) setIf(ctx context.Context, name, key string, value interface{}, ttl time.Duration,
+    set func(ctx context.Context, key string, value interface{}, ttl time.Duration) *redis.BoolCmd) (_ bool, err error) {
+    ctx, op := rc.startOperation(ctx, name, key)
+    defer op.end(&err)
+
+    data, err := op.encode(models.NewCacheItem(key, value, ttl))
+    if err != nil {
+        op.logger.Error("failed to marshal cache item", zap.Error(err), zap.String("key", key))
+        return false, fmt.Errorf("failed to marshal cache item: %w", err)
+    }
+
+    if err = rc.settleKey(ctx, key); err == nil {
+        var stored bool
+        if stored, err = set(ctx, key, data, ttl).Result(); err == nil && !stored {
+            op.logger.Debug("conditional set skipped", zap.String("key", key))
+            return false, nil
+        }
+    }
+    if err != nil {
+        op.logger.Error("failed to set cache item", zap.Error(err), zap.String("key", key))
+        return false, fmt.Errorf("failed to set cache item: %w", err)
+    }
+
+    if rc.local != nil {
+        rc.local.remember(key, data, expiresAt(ttl, time.Now()))
+    }
+    op.access(key, AccessSet, len(data))
+    op.logger.Debug("cache item set conditionally", zap.String("key", key), zap.Duration("ttl", ttl))
+    return true, nil
+}
+
+// GetSet stores an item and returns the one it replaced, nil if there was none
+func (rc *RedisCache) GetSet(ctx context.Context, key string, value interface{}, ttl time.Duration) (_ *models.CacheItem, err error) {
+    ctx, op := rc.startOperation(ctx, "get_set", key)
+    defer op.end(&err)
+
+    data, err := op.encode(models.NewCacheItem(key, value, ttl))
+    if err != nil {
+        op.logger.Error("failed to marshal cache item", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to marshal cache item: %w", err)
+    }
+
+    var previous *redis.StringCmd
+    if err = rc.settleKey(ctx, key); err == nil {
+        // MULTI so the new value is never visible without its TTL
+        _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+            previous = pipe.GetSet(ctx, key, data)
+            if ttl > 0 {
+                pipe.Expire(ctx, key, ttl)
+            }
+            return nil
+        })
+    }
+    if err == redis.Nil {
+        err = nil // No previous value
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to get and set cache item", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to get and set cache item: %w", err)
+    }
+
+    if rc.local != nil {
+        rc.local.remember(key, data, expiresAt(ttl, time.Now()))
+    }
+    op.access(key, AccessSet, len(data))
+    return rc.previousItem(op, key, previous)
+}
+
+// GetDel deletes an item and returns it, nil if the key did not exist
+func (rc *RedisCache) GetDel(ctx context.Context, key string) (_ *models.CacheItem, err error) {
+    ctx, op := rc.startOperation(ctx, "get_del", key)
+    defer op.end(&err)
+
+    var previous *redis.StringCmd
+    if err = rc.settleKey(ctx, key); err == nil {
+        previous = rc.client.GetDel(ctx, key)
+        err = previous.Err()
+    }
+    if err == redis.Nil {
+        err = nil
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to get and delete cache item", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to get and delete cache item: %w", err)
+    }
+
+    if rc.local != nil {
+        rc.local.forgetClean(key)
+    }
+    op.access(key, AccessDelete, 0)
+    return rc.previousItem(op, key, previous)
+}
+
+// previousItem decodes the value returned by GETSET or GETDEL
+func (rc *RedisCache) previousItem(op *operation, key string, previous *redis.StringCmd) (*models.CacheItem, error) {
+    data, err := previous.Bytes()
+    if err == redis.Nil {
+        op.access(key, AccessMiss, 0)
+        return nil, nil
+    }
+
+    var cacheItem models.CacheItem
+    if err := op.decode(data, &cacheItem); err != nil {
+        op.logger.Error("failed to unmarshal cache item", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to unmarshal cache item: %w", err)
+    }
+    if cacheItem.IsExpired() {
+        op.access(key, AccessMiss, 0)
+        return nil, nil
+    }
+    op.access(key, AccessHit, len(data))
+    return &cacheItem, nil
+}
+
+// settleKey sends the queued or locally pending write of key to Redis, so
+// operations decided by Redis see the latest value
+func (rc *RedisCache) settleKey(ctx context.Context, key string) error {
+    if err := rc.flushQueuedKeys(ctx, key); err != nil {
+        return err
+    }
+    if rc.local == nil {
+        return nil
+    }
+
+    entry, ok := rc.local.get(key, true)
+    if !ok {
+        return nil
+    }
+    if _, err := rc.reconcileEntry(ctx, entry); err != nil {
+        return err
+    }
+    rc.local.settle(entry.key, entry.writtenAt)
+    rc.reconciled.Add(1)
+    return nil
+}
+
 // SetMultiple stores multiple items
-func (rc *RedisCache) SetMultiple(ctx context.Context, items map[string]*models.CacheItem) error {
-    pipe := rc.client.Pipeline()
+func (rc *RedisCache) SetMultiple(ctx context.Context, items map[string]*models.CacheItem) (err error) {
+    ctx, op := rc.startOperation(ctx, "set_multiple", mapKeys(items)...)
+    defer op.end(&err)
+    op.batch(len(items))
 
+    encoded := make(map[string][]byte, len(items))
     for key, item := range items {
-        data, err := json.Marshal(item)
+        data, err := op.encode(item)
         if err != nil {
-            rc.logger.Error("failed to marshal cache item", zap.Error(err), zap.String("key", key))
+            op.logger.Error("failed to marshal cache item", zap.Error(err), zap.String("key", key))
             continue
         }
-        pipe.Set(ctx, key, data, item.TTL)
+        encoded[key] = data
     }
 
-    _, err := pipe.Exec(ctx)
+    if rc.queue != nil {
+        now := time.Now()
+        queued := make([]queuedWrite, 0, len(encoded))
+        for key, data := range encoded {
+            queued = append(queued, newQueuedWrite(key, data, items[key].TTL, now))
+        }
+        rejected := rc.queue.push(queued...)
+
+        // Only the writes that did not fit in the queue go straight to Redis
+        direct := make(map[string][]byte, len(rejected))
+        for _, w := range rejected {
+            direct[w.key] = w.data
+        }
+        for key, data := range encoded {
+            if _, ok := direct[key]; !ok {
+                op.access(key, AccessSet, len(data))
+            }
+        }
+        if len(direct) == 0 {
+            return nil
+        }
+        rc.writeThrough.Add(uint64(len(direct)))
+        encoded = direct
+    }
+
+    pipe := rc.client.Pipeline()
+    buffered := make([]bufferedWrite, 0, len(encoded))
+    for key, data := range encoded {
+        pipe.Set(ctx, key, data, items[key].TTL)
+        buffered = append(buffered, rc.bufferedSet(key, data, items[key].TTL))
+    }
+
+    _, err = pipe.Exec(ctx)
+    if rc.local != nil && unavailable(err) {
+        op.logger.Debug("redis unavailable, cache items kept locally", zap.Int("count", len(encoded)), zap.Error(err))
+        for key, data := range encoded {
+            rc.localWrite(op, key, data, items[key].TTL)
+        }
+        return nil
+    }
+    if errors.Is(err, ErrCircuitOpen) {
+        return rc.degradeWrite(op, err, buffered...)
+    }
     if err != nil {
-        rc.logger.Error("failed to set multiple cache items", zap.Error(err))
+        op.logger.Error("failed to set multiple cache items", zap.Error(err))
         return fmt.Errorf("failed to set multiple cache items: %w", err)
     }
 
-    rc.logger.Debug("multiple cache items set successfully", zap.Int("count", len(items)))
+    now := time.Now()
+    for key, data := range encoded {
+        if rc.local != nil {
+            rc.local.remember(key, data, expiresAt(items[key].TTL, now))
+        }
+        op.access(key, AccessSet, len(data))
+    }
+
+    op.logger.Debug("multiple cache items set successfully", zap.Int("count", len(items)))
     return nil
 }
 
 // GetMultiple retrieves multiple items
-func (rc *RedisCache) GetMultiple(ctx context.Context, keys []string) (map[string]*models.CacheItem, error) {
+func (rc *RedisCache) GetMultiple(ctx context.Context, keys []string) (_ map[string]*models.CacheItem, err error) {
+    ctx, op := rc.startOperation(ctx, "get_multiple", keys...)
+    defer op.end(&err)
+    op.batch(len(keys))
+
     if len(keys) == 0 {
         return make(map[string]*models.CacheItem), nil
     }
 
     results, err := rc.client.MGet(ctx, keys...).Result()
+    if rc.local != nil && unavailable(err) {
+        op.logger.Debug("redis unavailable, cache items served locally", zap.Int("requested", len(keys)))
+        items := make(map[string]*models.CacheItem)
+        for _, key := range keys {
+            item, found := rc.queuedRead(op, key)
+            if !found {
+                item, _ = rc.localRead(op, key, false)
+            }
+            if item != nil {
+                items[key] = item
+            }
+        }
+        return items, nil
+    }
+    if rc.missOnOpen(op, err) {
+        return make(map[string]*models.CacheItem), nil
+    }
     if err != nil {
-        rc.logger.Error("failed to get multiple cache items", zap.Error(err))
+        op.logger.Error("failed to get multiple cache items", zap.Error(err))
         return nil, fmt.Errorf("failed to get multiple cache items: %w", err)
     }
 
     items := make(map[string]*models.CacheItem)
     for i, result := range results {
+        // Queued writes and writes made locally during an outage are newer than Redis
+        if item, found := rc.queuedRead(op, keys[i]); found {
+            if item != nil {
+                items[keys[i]] = item
+            }
+            continue
+        }
+        if item, found := rc.localRead(op, keys[i], true); found {
+            if item != nil {
+                items[keys[i]] = item
+            }
+            continue
+        }
+
         if result == nil {
+            if rc.local != nil {
+                rc.local.forgetClean(keys[i])
+            }
+            op.access(keys[i], AccessMiss, 0)
             continue // Cache miss
         }
 
         var cacheItem models.CacheItem
         data, ok := result.(string)
         if !ok {
-            rc.logger.Warn("unexpected data type in cache", zap.String("key", keys[i]))
+            op.logger.Warn("unexpected data type in cache", zap.String("key", keys[i]))
             continue
         }
 
-        if err := json.Unmarshal([]byte(data), &cacheItem); err != nil {
-            rc.logger.Error("failed to unmarshal cache item", zap.Error(err), zap.String("key", keys[i]))
+        if err := op.decode([]byte(data), &cacheItem); err != nil {
+            op.logger.Error("failed to unmarshal cache item", zap.Error(err), zap.String("key", keys[i]))
             continue
         }
 
         if !cacheItem.IsExpired() {
             items[keys[i]] = &cacheItem
+            if rc.local != nil {
+                rc.local.refresh(keys[i], []byte(data), cacheItem.ExpiresAt)
+            }
+            op.access(keys[i], AccessHit, len(data))
         } else {
+            op.access(keys[i], AccessEvict, 0)
+            op.access(keys[i], AccessMiss, 0)
             // Clean up expired items asynchronously
             go func(key string) {
-                _ = rc.Delete(context.Background(), key)
+                _ = rc.client.Del(context.Background(), key).Err()
             }(keys[i])
         }
     }
 
-    rc.logger.Debug("multiple cache items retrieved", 
+    op.logger.Debug("multiple cache items retrieved", 
         zap.Int("requested", len(keys)), 
         zap.Int("found", len(items)))
 
@@ -202,38 +652,120 @@ func (rc *RedisCache) GetMultiple(ctx context.Context, keys []string) (map[strin
 }
 
 // DeleteMultiple removes multiple items
-func (rc *RedisCache) DeleteMultiple(ctx context.Context, keys []string) error {
+func (rc *RedisCache) DeleteMultiple(ctx context.Context, keys []string) (err error) {
+    ctx, op := rc.startOperation(ctx, "delete_multiple", keys...)
+    defer op.end(&err)
+    op.batch(len(keys))
+
     if len(keys) == 0 {
         return nil
     }
 
-    err := rc.client.Del(ctx, keys...).Err()
+    if rc.queue != nil {
+        rc.flushMu.Lock()
+        defer rc.flushMu.Unlock()
+        rc.queue.remove(keys...)
+    }
+
+    err = rc.client.Del(ctx, keys...).Err()
+    if rc.local != nil && unavailable(err) {
+        op.logger.Debug("redis unavailable, deletes kept locally", zap.Int("count", len(keys)), zap.Error(err))
+        for _, key := range keys {
+            rc.localWrite(op, key, nil, 0)
+        }
+        return nil
+    }
+    if errors.Is(err, ErrCircuitOpen) {
+        buffered := make([]bufferedWrite, len(keys))
+        for i, key := range keys {
+            buffered[i] = rc.bufferedDelete(key)
+        }
+        return rc.degradeWrite(op, err, buffered...)
+    }
     if err != nil {
-        rc.logger.Error("failed to delete multiple cache items", zap.Error(err))
+        op.logger.Error("failed to delete multiple cache items", zap.Error(err))
         return fmt.Errorf("failed to delete multiple cache items: %w", err)
     }
 
-    rc.logger.Debug("multiple cache items deleted successfully", zap.Int("count", len(keys)))
+    for _, key := range keys {
+        if rc.local != nil {
+            rc.local.forget(key)
+        }
+        op.access(key, AccessDelete, 0)
+    }
+    op.logger.Debug("multiple cache items deleted successfully", zap.Int("count", len(keys)))
     return nil
 }
 
-// Clear wipes the entire cache
-func (rc *RedisCache) Clear(ctx context.Context) error {
-    err := rc.client.FlushDB(ctx).Err()
-    if err != nil {
-        rc.logger.Error("failed to clear cache", zap.Error(err))
-        return fmt.Errorf("failed to clear cache: %w", err)
+// clearBatch is the number of keys scanned and unlinked at a time by Clear
+const clearBatch = 1000
+
+// Clear removes every cache key. The internal subsystem keys are kept, so
+// held locks, fencing counters, sessions and scheduled jobs survive it.
+func (rc *RedisCache) Clear(ctx context.Context) (err error) {
+    ctx, op := rc.startOperation(ctx, "clear")
+    defer op.end(&err)
+
+    if rc.queue != nil {
+        rc.flushMu.Lock()
+        defer rc.flushMu.Unlock()
+        rc.queue.clear()
+    }
+    // Writes buffered by the circuit breaker predate the clear
+    rc.writes.take()
+
+    var cursor uint64
+    for {
+        var scanned []string
+        scanned, cursor, err = rc.client.Scan(ctx, cursor, "*", clearBatch).Result()
+        if err != nil {
+            op.logger.Error("failed to clear cache", zap.Error(err))
+            return fmt.Errorf("failed to clear cache: %w", err)
+        }
+
+        keys := make([]string, 0, len(scanned))
+        for _, key := range scanned {
+            if !IsInternalKey(key) {
+                keys = append(keys, key)
+            }
+        }
+        if len(keys) > 0 {
+            // UNLINK frees the values in the background instead of blocking Redis
+            if err = rc.client.Unlink(ctx, keys...).Err(); err != nil {
+                op.logger.Error("failed to clear cache", zap.Error(err))
+                return fmt.Errorf("failed to clear cache: %w", err)
+            }
+        }
+
+        if cursor == 0 {
+            break
+        }
     }
 
-    rc.logger.Info("cache cleared successfully")
+    if rc.local != nil {
+        rc.local.clear()
+    }
+    op.logger.Info("cache cleared successfully")
     return nil
 }
 
 // Expire sets a new TTL for a key
-func (rc *RedisCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+func (rc *RedisCache) Expire(ctx context.Context, key string, ttl time.Duration) (err error) {
+    ctx, op := rc.startOperation(ctx, "expire", key)
+    defer op.end(&err)
+
+    // The key must reach Redis before its TTL can be read or changed
+    if err := rc.flushQueuedKeys(ctx, key); err != nil {
+        op.logger.Error("failed to flush queued write", zap.Error(err), zap.String("key", key))
+        return fmt.Errorf("failed to flush queued write: %w", err)
+    }
+
     success, err := rc.client.Expire(ctx, key, ttl).Result()
+    if errors.Is(err, ErrCircuitOpen) {
+        return rc.degradeWrite(op, err, rc.bufferedExpire(key, ttl))
+    }
     if err != nil {
-        rc.logger.Error("failed to set expiration", zap.Error(err), zap.String("key", key))
+        op.logger.Error("failed to set expiration", zap.Error(err), zap.String("key", key))
         return fmt.Errorf("failed to set expiration: %w", err)
     }
 
@@ -241,15 +773,23 @@ func (rc *RedisCache) Expire(ctx context.Context, key string, ttl time.Duration)
         return fmt.Errorf("key does not exist: %s", key)
     }
 
-    rc.logger.Debug("expiration set successfully", zap.String("key", key), zap.Duration("ttl", ttl))
+    op.logger.Debug("expiration set successfully", zap.String("key", key), zap.Duration("ttl", ttl))
     return nil
 }
 
 // TTL gets the remaining lifetime of a key
-func (rc *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+func (rc *RedisCache) TTL(ctx context.Context, key string) (_ time.Duration, err error) {
+    ctx, op := rc.startOperation(ctx, "ttl", key)
+    defer op.end(&err)
+
+    if err := rc.flushQueuedKeys(ctx, key); err != nil {
+        op.logger.Error("failed to flush queued write", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to flush queued write: %w", err)
+    }
+
     ttl, err := rc.client.TTL(ctx, key).Result()
     if err != nil {
-        rc.logger.Error("failed to get TTL", zap.Error(err), zap.String("key", key))
+        op.logger.Error("failed to get TTL", zap.Error(err), zap.String("key", key))
         return 0, fmt.Errorf("failed to get TTL: %w", err)
     }
 
@@ -257,70 +797,148 @@ func (rc *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error
 }
 
 // Keys returns keys matching a pattern
-func (rc *RedisCache) Keys(ctx context.Context, pattern string) ([]string, error) {
-    keys, err := rc.client.Keys(ctx, pattern).Result()
+func (rc *RedisCache) Keys(ctx context.Context, pattern string) (_ []string, err error) {
+    ctx, op := rc.startOperation(ctx, "keys")
+    defer op.end(&err)
+
+    if err := rc.flushQueuedKeys(ctx); err != nil {
+        op.logger.Error("failed to flush queued writes", zap.Error(err))
+        return nil, fmt.Errorf("failed to flush queued writes: %w", err)
+    }
+
+    matched, err := rc.client.Keys(ctx, pattern).Result()
     if err != nil {
-        rc.logger.Error("failed to get keys", zap.Error(err), zap.String("pattern", pattern))
+        op.logger.Error("failed to get keys", zap.Error(err), zap.String("pattern", pattern))
         return nil, fmt.Errorf("failed to get keys: %w", err)
     }
 
+    // Internal subsystem keys are not part of the cache contents
+    keys := make([]string, 0, len(matched))
+    for _, key := range matched {
+        if !IsInternalKey(key) {
+            keys = append(keys, key)
+        }
+    }
     return keys, nil
 }
 
 // FlushExpired removes expired items (in Redis this is done automatically)
 func (rc *RedisCache) FlushExpired(ctx context.Context) error {
-    rc.logger.Debug("flush expired called (Redis handles expiration automatically)")
+    logging.FromContext(ctx, rc.logger).Debug("flush expired called (Redis handles expiration automatically)")
     return nil
 }
 
 // Size devuelve el número de claves en el caché
-func (rc *RedisCache) Size(ctx context.Context) (int64, error) {
+func (rc *RedisCache) Size(ctx context.Context) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "size")
+    defer op.end(&err)
+
+    if err := rc.flushQueuedKeys(ctx); err != nil {
+        op.logger.Error("failed to flush queued writes", zap.Error(err))
+        return 0, fmt.Errorf("failed to flush queued writes: %w", err)
+    }
+
     size, err := rc.client.DBSize(ctx).Result()
     if err != nil {
-        rc.logger.Error("failed to get cache size", zap.Error(err))
+        op.logger.Error("failed to get cache size", zap.Error(err))
         return 0, fmt.Errorf("failed to get cache size: %w", err)
     }
 
     return size, nil
 }
 
-// Info devuelve información del caché
-func (rc *RedisCache) Info(ctx context.Context) (map[string]interface{}, error) {
-    info, err := rc.client.Info(ctx).Result()
+// Info devuelve la información de Redis, opcionalmente limitada a ciertas secciones
+func (rc *RedisCache) Info(ctx context.Context, sections ...string) (_ *ServerInfo, err error) {
+    ctx, op := rc.startOperation(ctx, "info")
+    defer op.end(&err)
+
+    info, err := rc.client.Info(ctx, sections...).Result()
     if err != nil {
-        rc.logger.Error("failed to get cache info", zap.Error(err))
+        op.logger.Error("failed to get cache info", zap.Error(err))
         return nil, fmt.Errorf("failed to get cache info: %w", err)
     }
 
-    // Parsear información básica
-    result := make(map[string]interface{})
-    lines := strings.Split(info, "\n")
-
-    for _, line := range lines {
-        if strings.Contains(line, ":") {
-            parts := strings.SplitN(line, ":", 2)
-            if len(parts) == 2 {
-                result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-            }
-        }
-    }
-
-    return result, nil
+    return ParseInfo(info), nil
 }
 
 // Ping verifica la conexión con Redis
-func (rc *RedisCache) Ping(ctx context.Context) error {
-    err := rc.client.Ping(ctx).Err()
+func (rc *RedisCache) Ping(ctx context.Context) (err error) {
+    ctx, op := rc.startOperation(ctx, "ping")
+    defer op.end(&err)
+
+    err = rc.client.Ping(ctx).Err()
     if err != nil {
-        rc.logger.Error("ping failed", zap.Error(err))
+        op.logger.Error("ping failed", zap.Error(err))
         return fmt.Errorf("ping failed: %w", err)
     }
 
     return nil
 }
 
+// Status devuelve el estado en tiempo de ejecución del backend
+func (rc *RedisCache) Status() Status {
+    stats := rc.client.PoolStats()
+    status := Status{
+        Pool: PoolStats{
+            Hits:       stats.Hits,
+            Misses:     stats.Misses,
+            Timeouts:   stats.Timeouts,
+            TotalConns: stats.TotalConns,
+            IdleConns:  stats.IdleConns,
+            StaleConns: stats.StaleConns,
+            PoolSize:   rc.config.PoolSize,
+        },
+    }
+
+    if rc.breaker != nil {
+        breaker := rc.breaker.status()
+        breaker.DroppedWrites = rc.droppedWrites.Load()
+        breaker.BufferedWrites = rc.writes.len()
+        status.Breaker = &breaker
+    }
+    if rc.queue != nil {
+        status.WriteBehind = rc.writeBehindStatus()
+    }
+    if rc.local != nil {
+        items, dirty, lost := rc.local.counts()
+        status.Fallback = &FallbackStatus{
+            Items:         items,
+            PendingWrites: dirty,
+            Hits:          rc.localHits.Load(),
+            Reconciled:    rc.reconciled.Load(),
+            Conflicts:     rc.conflicts.Load(),
+            LostWrites:    lost,
+        }
+    }
+    return status
+}
+
+// Stats devuelve las estadísticas de acceso por namespace y prefijo
+func (rc *RedisCache) Stats() StatsSnapshot {
+    return rc.stats.snapshot()
+}
+
+// mapKeys returns the keys of a batch of items
+func mapKeys(items map[string]*models.CacheItem) []string {
+    keys := make([]string, 0, len(items))
+    for key := range items {
+        keys = append(keys, key)
+    }
+    return keys
+}
+
 // Close cierra la conexión con Redis
 func (rc *RedisCache) Close() error {
+    close(rc.done)
+
+    if rc.queue != nil {
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        if err := rc.flushQueue(ctx); err != nil {
+            rc.logger.Error("queued writes lost on close", zap.Int("count", rc.queue.depth()), zap.Error(err))
+        }
+        cancel()
+    }
+
     err := rc.client.Close()
     if err != nil {
         rc.logger.Error("failed to close Redis connection", zap.Error(err))
@@ -0,0 +1,221 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+)
+
+// HSet writes the fields and returns how many were new
+func (rc *RedisCache) HSet(ctx context.Context, key string, fields map[string]interface{}, ttl time.Duration) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "hset", key)
+    defer op.end(&err)
+    op.batch(len(fields))
+
+    values := make([]interface{}, 0, len(fields)*2)
+    for field, value := range fields {
+        data, err := op.encode(value)
+        if err != nil {
+            return 0, fmt.Errorf("failed to marshal field %s: %w", field, err)
+        }
+        values = append(values, field, data)
+    }
+
+    // Pending write-behind or fallback writes of key go first. MULTI so
+    // the fields are never visible without their TTL.
+    var added *redis.IntCmd
+    if err = rc.settleKey(ctx, key); err == nil {
+        _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+            added = pipe.HSet(ctx, key, values...)
+            if ttl > 0 {
+                pipe.Expire(ctx, key, ttl)
+            }
+            return nil
+        })
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to set hash fields", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to set hash fields: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    op.logger.Debug("hash fields set", zap.String("key", key), zap.Int("fields", len(fields)))
+    return added.Val(), nil
+}
+
+// HGet returns the value of field
+func (rc *RedisCache) HGet(ctx context.Context, key, field string) (_ interface{}, _ bool, err error) {
+    ctx, op := rc.startOperation(ctx, "hget", key)
+    defer op.end(&err)
+
+    data, err := rc.client.HGet(ctx, key, field).Bytes()
+    if err == redis.Nil {
+        op.access(key, AccessMiss, 0)
+        return nil, false, nil
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, false, err
+        }
+        op.logger.Error("failed to get hash field", zap.Error(err), zap.String("key", key), zap.String("field", field))
+        return nil, false, fmt.Errorf("failed to get hash field: %w", err)
+    }
+
+    var value interface{}
+    if err := op.decode(data, &value); err != nil {
+        return nil, false, fmt.Errorf("failed to unmarshal field %s: %w", field, err)
+    }
+    op.access(key, AccessHit, len(data))
+    return value, true, nil
+}
+
+// HMGet returns the values of the fields that exist
+func (rc *RedisCache) HMGet(ctx context.Context, key string, fields ...string) (_ map[string]interface{}, err error) {
+    ctx, op := rc.startOperation(ctx, "hmget", key)
+    defer op.end(&err)
+    op.batch(len(fields))
+
+    values := make(map[string]interface{}, len(fields))
+    if len(fields) == 0 {
+        return values, nil
+    }
+
+    results, err := rc.client.HMGet(ctx, key, fields...).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to get hash fields", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to get hash fields: %w", err)
+    }
+
+    for i, result := range results {
+        data, ok := result.(string)
+        if !ok {
+            continue
+        }
+        var value interface{}
+        if err := op.decode([]byte(data), &value); err != nil {
+            op.logger.Error("failed to unmarshal hash field", zap.Error(err), zap.String("key", key), zap.String("field", fields[i]))
+            continue
+        }
+        values[fields[i]] = value
+    }
+
+    if len(values) > 0 {
+        op.access(key, AccessHit, 0)
+    } else {
+        op.access(key, AccessMiss, 0)
+    }
+    return values, nil
+}
+
+// HDel removes the fields and returns how many existed
+func (rc *RedisCache) HDel(ctx context.Context, key string, fields ...string) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "hdel", key)
+    defer op.end(&err)
+    op.batch(len(fields))
+
+    if len(fields) == 0 {
+        return 0, nil
+    }
+
+    var removed int64
+    if err = rc.settleKey(ctx, key); err == nil {
+        removed, err = rc.client.HDel(ctx, key, fields...).Result()
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to delete hash fields", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to delete hash fields: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    op.logger.Debug("hash fields deleted", zap.String("key", key), zap.Int64("removed", removed))
+    return removed, nil
+}
+
+// HGetAll returns every field of the hash
+func (rc *RedisCache) HGetAll(ctx context.Context, key string) (_ map[string]interface{}, err error) {
+    ctx, op := rc.startOperation(ctx, "hgetall", key)
+    defer op.end(&err)
+
+    results, err := rc.client.HGetAll(ctx, key).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to get hash", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to get hash: %w", err)
+    }
+
+    values := make(map[string]interface{}, len(results))
+    size := 0
+    for field, data := range results {
+        var value interface{}
+        if err := op.decode([]byte(data), &value); err != nil {
+            op.logger.Error("failed to unmarshal hash field", zap.Error(err), zap.String("key", key), zap.String("field", field))
+            continue
+        }
+        values[field] = value
+        size += len(field) + len(data)
+    }
+
+    if len(results) == 0 {
+        op.access(key, AccessMiss, 0)
+    } else {
+        op.access(key, AccessHit, size)
+    }
+    return values, nil
+}
+
+// HIncrBy adds delta to an integer field and returns the new value
+func (rc *RedisCache) HIncrBy(ctx context.Context, key, field string, delta int64, ttl time.Duration) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "hincrby", key)
+    defer op.end(&err)
+
+    var value *redis.IntCmd
+    if err = rc.settleKey(ctx, key); err == nil {
+        _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+            value = pipe.HIncrBy(ctx, key, field, delta)
+            if ttl > 0 {
+                pipe.Expire(ctx, key, ttl)
+            }
+            return nil
+        })
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to increment hash field", zap.Error(err), zap.String("key", key), zap.String("field", field))
+        return 0, fmt.Errorf("failed to increment hash field: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    return value.Val(), nil
+}
+
+// HLen returns the number of fields in the hash
+func (rc *RedisCache) HLen(ctx context.Context, key string) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "hlen", key)
+    defer op.end(&err)
+
+    count, err := rc.client.HLen(ctx, key).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to count hash fields", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to count hash fields: %w", err)
+    }
+    return count, nil
+}
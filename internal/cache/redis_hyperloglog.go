@@ -0,0 +1,102 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+)
+
+// PFAdd adds elements and reports whether the estimate changed
+func (rc *RedisCache) PFAdd(ctx context.Context, key string, elements []string, ttl time.Duration) (_ bool, err error) {
+    ctx, op := rc.startOperation(ctx, "pfadd", key)
+    defer op.end(&err)
+    op.batch(len(elements))
+
+    values := make([]interface{}, len(elements))
+    for i, element := range elements {
+        values[i] = element
+    }
+
+    var changed *redis.IntCmd
+    _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+        changed = pipe.PFAdd(ctx, key, values...)
+        if ttl > 0 {
+            pipe.Expire(ctx, key, ttl)
+        }
+        return nil
+    })
+    if err = hyperLogLogError(err); err != nil {
+        if isOutcome(err) {
+            return false, err
+        }
+        op.logger.Error("failed to add hyperloglog elements", zap.Error(err), zap.String("key", key))
+        return false, fmt.Errorf("failed to add hyperloglog elements: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    return changed.Val() == 1, nil
+}
+
+// PFCount returns the estimated number of distinct elements in the union of
+// the keys. In a cluster the keys must share a hash slot.
+func (rc *RedisCache) PFCount(ctx context.Context, keys ...string) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "pfcount", keys...)
+    defer op.end(&err)
+    op.batch(len(keys))
+
+    count, err := rc.client.PFCount(ctx, keys...).Result()
+    if err = hyperLogLogError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to count hyperloglog", zap.Error(err), zap.Strings("keys", keys))
+        return 0, fmt.Errorf("failed to count hyperloglog: %w", err)
+    }
+
+    for _, key := range keys {
+        op.access(key, AccessHit, 0)
+    }
+    return count, nil
+}
+
+// PFMerge merges the sources into dest and returns the estimate of dest. In
+// a cluster every key must share a hash slot.
+func (rc *RedisCache) PFMerge(ctx context.Context, dest string, sources []string, ttl time.Duration) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "pfmerge", append([]string{dest}, sources...)...)
+    defer op.end(&err)
+    op.batch(len(sources))
+
+    var count *redis.IntCmd
+    _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+        pipe.PFMerge(ctx, dest, sources...)
+        if ttl > 0 {
+            pipe.Expire(ctx, dest, ttl)
+        }
+        count = pipe.PFCount(ctx, dest)
+        return nil
+    })
+    if err = hyperLogLogError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to merge hyperloglogs", zap.Error(err), zap.String("key", dest), zap.Strings("sources", sources))
+        return 0, fmt.Errorf("failed to merge hyperloglogs: %w", err)
+    }
+
+    op.access(dest, AccessSet, 0)
+    return count.Val(), nil
+}
+
+// hyperLogLogError also reports strings that are not HyperLogLogs, which
+// Redis rejects with INVALIDOBJ or WRONGTYPE depending on the version, as
+// ErrWrongType
+func hyperLogLogError(err error) error {
+    if isRedisReply(err) && strings.HasPrefix(err.Error(), "INVALIDOBJ") {
+        return ErrWrongType
+    }
+    return dataTypeError(err)
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+
+    "distributed-cache/pkg/models"
+)
+
+// claimIdempotencyScript claims the key if it is free, otherwise returns the
+// fingerprint, creation time, response and remaining TTL of the record.
+// KEYS[1] = record key
+// ARGV[1] = owner, ARGV[2] = fingerprint, ARGV[3] = now in milliseconds, ARGV[4] = lock ttl in milliseconds
+var claimIdempotencyScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+    local record = redis.call('HMGET', KEYS[1], 'fingerprint', 'created_at', 'response')
+    record[4] = redis.call('PTTL', KEYS[1])
+    return record
+end
+redis.call('HSET', KEYS[1], 'owner', ARGV[1], 'fingerprint', ARGV[2], 'created_at', ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return false
+`)
+
+// completeIdempotencyScript stores the response if the key is claimed by the
+// owner; the owner is dropped so the record can no longer change.
+// KEYS[1] = record key
+// ARGV[1] = owner, ARGV[2] = encoded response, ARGV[3] = ttl in milliseconds
+var completeIdempotencyScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+    return false
+end
+redis.call('HDEL', KEYS[1], 'owner')
+redis.call('HSET', KEYS[1], 'response', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return redis.call('HMGET', KEYS[1], 'fingerprint', 'created_at', 'response')
+`)
+
+// idempotencyKey returns the Redis key of an idempotency record
+func idempotencyKey(key string) string {
+    return InternalKeyPrefix + "idempotency:" + key
+}
+
+// ClaimIdempotencyKey claims key for owner or returns the existing record
+func (rc *RedisCache) ClaimIdempotencyKey(ctx context.Context, key, owner, fingerprint string, lockTTL time.Duration) (_ *models.IdempotencyRecord, claimed bool, err error) {
+    ctx, op := rc.startOperation(ctx, "idempotency_claim")
+    defer op.end(&err)
+
+    now := time.Now()
+    fields, err := claimIdempotencyScript.Run(ctx, rc.client, []string{idempotencyKey(key)},
+        owner, fingerprint, now.UnixMilli(), lockTTL.Milliseconds()).Slice()
+    if err == redis.Nil {
+        op.logger.Debug("idempotency key claimed", zap.String("idempotency_key", key), zap.String("owner", owner))
+        return &models.IdempotencyRecord{
+            Key:         key,
+            Status:      models.IdempotencyInProgress,
+            Fingerprint: fingerprint,
+            Owner:       owner,
+            CreatedAt:   now,
+            ExpiresAt:   now.Add(lockTTL),
+        }, true, nil
+    }
+    if err != nil {
+        op.logger.Error("failed to claim idempotency key", zap.Error(err), zap.String("idempotency_key", key))
+        return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+    }
+
+    pttl, _ := fields[3].(int64)
+    record, err := idempotencyRecord(op, key, fields[:3], time.Duration(pttl)*time.Millisecond)
+    if err != nil {
+        return nil, false, err
+    }
+    return record, false, nil
+}
+
+// CompleteIdempotencyKey stores the final response of a claimed key
+func (rc *RedisCache) CompleteIdempotencyKey(ctx context.Context, key, owner string, response *models.IdempotentResponse, ttl time.Duration) (_ *models.IdempotencyRecord, err error) {
+    ctx, op := rc.startOperation(ctx, "idempotency_complete")
+    defer op.end(&err)
+
+    data, err := op.encode(response)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal idempotent response: %w", err)
+    }
+
+    fields, err := completeIdempotencyScript.Run(ctx, rc.client, []string{idempotencyKey(key)},
+        owner, data, ttl.Milliseconds()).Slice()
+    if err == redis.Nil {
+        return nil, ErrIdempotencyNotOwned
+    }
+    if err != nil {
+        op.logger.Error("failed to complete idempotency key", zap.Error(err), zap.String("idempotency_key", key))
+        return nil, fmt.Errorf("failed to complete idempotency key: %w", err)
+    }
+
+    op.logger.Debug("idempotency key completed",
+        zap.String("idempotency_key", key),
+        zap.Int("status_code", response.StatusCode))
+
+    return idempotencyRecord(op, key, fields, ttl)
+}
+
+// ReleaseIdempotencyKey drops an in-progress claim held by owner
+func (rc *RedisCache) ReleaseIdempotencyKey(ctx context.Context, key, owner string) (err error) {
+    ctx, op := rc.startOperation(ctx, "idempotency_release")
+    defer op.end(&err)
+
+    // Completed records have no owner, so only claims are released
+    deleted, err := releaseLockScript.Run(ctx, rc.client, []string{idempotencyKey(key)}, owner).Int64()
+    if err != nil {
+        op.logger.Error("failed to release idempotency key", zap.Error(err), zap.String("idempotency_key", key))
+        return fmt.Errorf("failed to release idempotency key: %w", err)
+    }
+
+    if deleted == 0 {
+        return ErrIdempotencyNotOwned
+    }
+
+    op.logger.Debug("idempotency key released", zap.String("idempotency_key", key))
+    return nil
+}
+
+// GetIdempotencyKey returns the record of key
+func (rc *RedisCache) GetIdempotencyKey(ctx context.Context, key string) (_ *models.IdempotencyRecord, err error) {
+    ctx, op := rc.startOperation(ctx, "idempotency_get")
+    defer op.end(&err)
+
+    var (
+        fields *redis.SliceCmd
+        pttl   *redis.DurationCmd
+    )
+    _, err = rc.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+        fields = pipe.HMGet(ctx, idempotencyKey(key), "fingerprint", "created_at", "response")
+        pttl = pipe.PTTL(ctx, idempotencyKey(key))
+        return nil
+    })
+    if err != nil {
+        op.logger.Error("failed to get idempotency key", zap.Error(err), zap.String("idempotency_key", key))
+        return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+    }
+
+    // A record always has a creation time
+    if fields.Val()[1] == nil {
+        return nil, nil
+    }
+    return idempotencyRecord(op, key, fields.Val(), pttl.Val())
+}
+
+// idempotencyRecord builds a record from its fingerprint, created_at and
+// response fields
+func idempotencyRecord(op *operation, key string, fields []interface{}, ttl time.Duration) (*models.IdempotencyRecord, error) {
+    record := &models.IdempotencyRecord{
+        Key:    key,
+        Status: models.IdempotencyInProgress,
+    }
+    if fingerprint, ok := fields[0].(string); ok {
+        record.Fingerprint = fingerprint
+    }
+    if createdAt, ok := fields[1].(string); ok {
+        ms, _ := strconv.ParseInt(createdAt, 10, 64)
+        record.CreatedAt = time.UnixMilli(ms)
+    }
+    if data, ok := fields[2].(string); ok {
+        var response models.IdempotentResponse
+        if err := op.decode([]byte(data), &response); err != nil {
+            op.logger.Error("failed to unmarshal idempotent response", zap.Error(err), zap.String("idempotency_key", key))
+            return nil, fmt.Errorf("failed to unmarshal idempotent response: %w", err)
+        }
+        record.Status = models.IdempotencyCompleted
+        record.Response = &response
+    }
+    if ttl > 0 {
+        record.ExpiresAt = time.Now().Add(ttl)
+    }
+    return record, nil
+}
@@ -0,0 +1,227 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+)
+
+// blockingPollInterval longest single blocking pop. go-redis does not abort a
+// blocked read when the context is canceled, so long waits are split into
+// polls that check the context in between. Lower read timeouts shorten it.
+const blockingPollInterval = time.Second
+
+// ListPush adds values at end and returns the new length
+func (rc *RedisCache) ListPush(ctx context.Context, key string, end ListEnd, values []interface{}, ttl time.Duration, maxLen int64) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "list_push", key)
+    defer op.end(&err)
+    op.batch(len(values))
+
+    if len(values) == 0 {
+        return 0, fmt.Errorf("no values to push")
+    }
+
+    encoded := make([]interface{}, len(values))
+    for i, value := range values {
+        data, err := op.encode(value)
+        if err != nil {
+            return 0, fmt.Errorf("failed to marshal list value: %w", err)
+        }
+        encoded[i] = data
+    }
+
+    // Pending write-behind or fallback writes of key go first. MULTI so
+    // readers never see the list past maxLen or without its TTL.
+    var length *redis.IntCmd
+    if err = rc.settleKey(ctx, key); err == nil {
+        _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+            if end == ListLeft {
+                length = pipe.LPush(ctx, key, encoded...)
+                if maxLen > 0 {
+                    pipe.LTrim(ctx, key, 0, maxLen-1)
+                }
+            } else {
+                length = pipe.RPush(ctx, key, encoded...)
+                if maxLen > 0 {
+                    pipe.LTrim(ctx, key, -maxLen, -1)
+                }
+            }
+            if ttl > 0 {
+                pipe.Expire(ctx, key, ttl)
+            }
+            return nil
+        })
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to push list values", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to push list values: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    n := length.Val()
+    if maxLen > 0 && n > maxLen {
+        n = maxLen
+    }
+    return n, nil
+}
+
+// ListPop removes an element from end, waiting up to timeout if the list is empty
+func (rc *RedisCache) ListPop(ctx context.Context, key string, end ListEnd, timeout time.Duration) (_ interface{}, _ bool, err error) {
+    ctx, op := rc.startOperation(ctx, "list_pop", key)
+    defer op.end(&err)
+
+    // Pending write-behind or fallback writes of key go first
+    var data string
+    if err = rc.settleKey(ctx, key); err == nil {
+        if timeout > 0 {
+            data, err = rc.blockingPop(ctx, key, end, timeout)
+        } else if end == ListLeft {
+            data, err = rc.client.LPop(ctx, key).Result()
+        } else {
+            data, err = rc.client.RPop(ctx, key).Result()
+        }
+    }
+
+    if err == redis.Nil {
+        op.access(key, AccessMiss, 0)
+        return nil, false, nil
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) || ctx.Err() != nil {
+            return nil, false, err
+        }
+        op.logger.Error("failed to pop list value", zap.Error(err), zap.String("key", key))
+        return nil, false, fmt.Errorf("failed to pop list value: %w", err)
+    }
+
+    var value interface{}
+    if err := op.decode([]byte(data), &value); err != nil {
+        return nil, false, fmt.Errorf("failed to unmarshal list value: %w", err)
+    }
+    op.access(key, AccessHit, len(data))
+    return value, true, nil
+}
+
+// blockingPop waits for an element with BLPOP/BRPOP, polling so that a
+// canceled ctx ends the wait. It returns redis.Nil if the timeout elapses.
+func (rc *RedisCache) blockingPop(ctx context.Context, key string, end ListEnd, timeout time.Duration) (string, error) {
+    interval := blockingPollInterval
+    if rt := rc.config.ReadTimeout; rt > 0 && rt/2 < interval {
+        interval = rt / 2
+    }
+
+    deadline := time.Now().Add(timeout)
+    for {
+        if err := ctx.Err(); err != nil {
+            return "", err
+        }
+
+        wait := time.Until(deadline)
+        if wait <= 0 {
+            return "", redis.Nil
+        }
+        if wait > interval {
+            wait = interval
+        }
+        if d, ok := ctx.Deadline(); ok && time.Until(d) < wait {
+            wait = time.Until(d)
+        }
+        if wait < time.Millisecond {
+            wait = time.Millisecond
+        }
+
+        // The go-redis helpers round waits below a second up to one second;
+        // Redis accepts fractional seconds
+        command := "BRPOP"
+        if end == ListLeft {
+            command = "BLPOP"
+        }
+        seconds := strconv.FormatFloat(wait.Seconds(), 'f', 3, 64)
+        result, err := rc.client.Do(ctx, command, key, seconds).StringSlice()
+        if err == redis.Nil {
+            continue
+        }
+        if err != nil {
+            return "", err
+        }
+        // Reply is [key, value]
+        return result[1], nil
+    }
+}
+
+// ListRange returns the elements between start and stop
+func (rc *RedisCache) ListRange(ctx context.Context, key string, start, stop int64) (_ []interface{}, err error) {
+    ctx, op := rc.startOperation(ctx, "list_range", key)
+    defer op.end(&err)
+
+    results, err := rc.client.LRange(ctx, key, start, stop).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to get list range", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to get list range: %w", err)
+    }
+
+    values := make([]interface{}, 0, len(results))
+    size := 0
+    for _, data := range results {
+        var value interface{}
+        if err := op.decode([]byte(data), &value); err != nil {
+            op.logger.Error("failed to unmarshal list value", zap.Error(err), zap.String("key", key))
+            continue
+        }
+        values = append(values, value)
+        size += len(data)
+    }
+
+    if len(results) == 0 {
+        op.access(key, AccessMiss, 0)
+    } else {
+        op.access(key, AccessHit, size)
+    }
+    return values, nil
+}
+
+// ListTrim keeps only the elements between start and stop
+func (rc *RedisCache) ListTrim(ctx context.Context, key string, start, stop int64) (err error) {
+    ctx, op := rc.startOperation(ctx, "list_trim", key)
+    defer op.end(&err)
+
+    if err = rc.settleKey(ctx, key); err == nil {
+        err = rc.client.LTrim(ctx, key, start, stop).Err()
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return err
+        }
+        op.logger.Error("failed to trim list", zap.Error(err), zap.String("key", key))
+        return fmt.Errorf("failed to trim list: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    return nil
+}
+
+// ListLen returns the number of elements in the list
+func (rc *RedisCache) ListLen(ctx context.Context, key string) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "list_len", key)
+    defer op.end(&err)
+
+    length, err := rc.client.LLen(ctx, key).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to get list length", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to get list length: %w", err)
+    }
+    return length, nil
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+
+    "distributed-cache/pkg/models"
+)
+
+// acquireLockScript sets the lock if free and bumps the fencing counter.
+// KEYS[1] = lock key, KEYS[2] = fencing counter key
+// ARGV[1] = owner, ARGV[2] = ttl in milliseconds
+var acquireLockScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+    return false
+end
+local token = redis.call('INCR', KEYS[2])
+redis.call('HSET', KEYS[1], 'owner', ARGV[1], 'token', token)
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return token
+`)
+
+// renewLockScript extends the lock TTL if it is held by the owner.
+// KEYS[1] = lock key
+// ARGV[1] = owner, ARGV[2] = ttl in milliseconds
+var renewLockScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+    return false
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return tonumber(redis.call('HGET', KEYS[1], 'token'))
+`)
+
+// releaseLockScript deletes the lock if it is held by the owner.
+// KEYS[1] = lock key
+// ARGV[1] = owner
+var releaseLockScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+    return 0
+end
+return redis.call('DEL', KEYS[1])
+`)
+
+// lockKeys returns the lock and fencing counter keys for a lock name.
+// The hash tag keeps both keys in the same cluster slot.
+func lockKeys(name string) []string {
+    prefix := InternalKeyPrefix + "lock:{" + name + "}"
+    return []string{prefix, prefix + ":fence"}
+}
+
+// AcquireLock takes the lock for owner and returns a new fencing token
+func (rc *RedisCache) AcquireLock(ctx context.Context, name, owner string, ttl time.Duration) (_ *models.Lock, err error) {
+    ctx, op := rc.startOperation(ctx, "lock_acquire")
+    defer op.end(&err)
+
+    now := time.Now()
+    token, err := acquireLockScript.Run(ctx, rc.client, lockKeys(name), owner, ttl.Milliseconds()).Int64()
+    if err != nil {
+        if err == redis.Nil {
+            return nil, ErrLockHeld
+        }
+        op.logger.Error("failed to acquire lock", zap.Error(err), zap.String("lock", name))
+        return nil, fmt.Errorf("failed to acquire lock: %w", err)
+    }
+
+    op.logger.Debug("lock acquired",
+        zap.String("lock", name),
+        zap.String("owner", owner),
+        zap.Int64("token", token))
+
+    return &models.Lock{
+        Name:       name,
+        Owner:      owner,
+        Token:      token,
+        AcquiredAt: now,
+        ExpiresAt:  now.Add(ttl),
+    }, nil
+}
+
+// RenewLock extends the lock TTL, keeping its fencing token
+func (rc *RedisCache) RenewLock(ctx context.Context, name, owner string, ttl time.Duration) (_ *models.Lock, err error) {
+    ctx, op := rc.startOperation(ctx, "lock_renew")
+    defer op.end(&err)
+
+    now := time.Now()
+    token, err := renewLockScript.Run(ctx, rc.client, lockKeys(name)[:1], owner, ttl.Milliseconds()).Int64()
+    if err != nil {
+        if err == redis.Nil {
+            return nil, ErrLockNotOwned
+        }
+        op.logger.Error("failed to renew lock", zap.Error(err), zap.String("lock", name))
+        return nil, fmt.Errorf("failed to renew lock: %w", err)
+    }
+
+    op.logger.Debug("lock renewed", zap.String("lock", name), zap.Duration("ttl", ttl))
+
+    return &models.Lock{
+        Name:      name,
+        Owner:     owner,
+        Token:     token,
+        ExpiresAt: now.Add(ttl),
+    }, nil
+}
+
+// ReleaseLock frees the lock only if it is held by owner
+func (rc *RedisCache) ReleaseLock(ctx context.Context, name, owner string) (err error) {
+    ctx, op := rc.startOperation(ctx, "lock_release")
+    defer op.end(&err)
+
+    deleted, err := releaseLockScript.Run(ctx, rc.client, lockKeys(name)[:1], owner).Int64()
+    if err != nil {
+        op.logger.Error("failed to release lock", zap.Error(err), zap.String("lock", name))
+        return fmt.Errorf("failed to release lock: %w", err)
+    }
+
+    if deleted == 0 {
+        return ErrLockNotOwned
+    }
+
+    op.logger.Debug("lock released", zap.String("lock", name), zap.String("owner", owner))
+    return nil
+}
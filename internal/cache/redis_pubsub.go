@@ -0,0 +1,154 @@
+package cache
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "sync"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+
+    "distributed-cache/pkg/models"
+)
+
+// redisSubscription forwards the messages of a Redis subscription to a
+// bounded buffer. A full buffer ends the subscription instead of blocking
+// the connection or dropping messages silently.
+type redisSubscription struct {
+    pubsub   *redis.PubSub
+    messages chan *models.PubSubMessage
+
+    mu     sync.Mutex
+    closed bool
+    err    error
+}
+
+// Publish sends message to channel and returns the number of Redis
+// subscriptions that received it
+func (rc *RedisCache) Publish(ctx context.Context, channel string, message interface{}) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "publish")
+    defer op.end(&err)
+
+    data, err := op.encode(message)
+    if err != nil {
+        return 0, fmt.Errorf("failed to marshal message: %w", err)
+    }
+
+    receivers, err := rc.client.Publish(ctx, channel, data).Result()
+    if err != nil {
+        op.logger.Error("failed to publish message", zap.Error(err), zap.String("channel", channel))
+        return 0, fmt.Errorf("failed to publish message: %w", err)
+    }
+
+    op.logger.Debug("message published",
+        zap.String("channel", channel),
+        zap.Int64("receivers", receivers))
+    return receivers, nil
+}
+
+// Subscribe listens on the channels and the glob-style patterns, buffering up
+// to bufferSize messages not yet read. Every subscription holds its own Redis
+// connection until it is closed.
+func (rc *RedisCache) Subscribe(ctx context.Context, channels, patterns []string, bufferSize int) (_ Subscription, err error) {
+    ctx, op := rc.startOperation(ctx, "subscribe")
+    defer op.end(&err)
+    op.batch(len(channels) + len(patterns))
+
+    if len(channels) == 0 && len(patterns) == 0 {
+        return nil, errors.New("at least one channel or pattern is required")
+    }
+    if bufferSize <= 0 {
+        return nil, fmt.Errorf("buffer size must be positive, got %d", bufferSize)
+    }
+
+    pubsub := rc.client.Subscribe(ctx, channels...)
+    if len(patterns) > 0 {
+        err = pubsub.PSubscribe(ctx, patterns...)
+    }
+    if err == nil {
+        // The first confirmation surfaces connection errors here rather than
+        // as an empty subscription
+        _, err = pubsub.Receive(ctx)
+    }
+    if err != nil {
+        pubsub.Close()
+        op.logger.Error("failed to subscribe", zap.Error(err),
+            zap.Strings("channels", channels),
+            zap.Strings("patterns", patterns))
+        return nil, fmt.Errorf("failed to subscribe: %w", err)
+    }
+
+    sub := &redisSubscription{
+        pubsub:   pubsub,
+        messages: make(chan *models.PubSubMessage, bufferSize),
+    }
+    go sub.run()
+    return sub, nil
+}
+
+// run receives messages until the subscription is closed, the connection
+// fails or the buffer overflows
+func (s *redisSubscription) run() {
+    defer close(s.messages)
+
+    for {
+        msg, err := s.pubsub.ReceiveMessage(context.Background())
+        if err != nil {
+            s.finish(err)
+            return
+        }
+
+        message := &models.PubSubMessage{
+            Channel: msg.Channel,
+            Pattern: msg.Pattern,
+        }
+        if err := json.Unmarshal([]byte(msg.Payload), &message.Payload); err != nil {
+            // Published straight to Redis by another client
+            message.Payload = msg.Payload
+        }
+
+        select {
+        case s.messages <- message:
+        default:
+            s.finish(ErrSlowConsumer)
+            return
+        }
+    }
+}
+
+// finish ends the subscription, recording err unless it was closed
+func (s *redisSubscription) finish(err error) {
+    s.mu.Lock()
+    if !s.closed {
+        s.closed = true
+        s.err = err
+    }
+    s.mu.Unlock()
+    s.pubsub.Close()
+}
+
+// Messages returns the received messages; it is closed when the subscription ends
+func (s *redisSubscription) Messages() <-chan *models.PubSubMessage {
+    return s.messages
+}
+
+// Err returns why the subscription ended, nil if it was closed
+func (s *redisSubscription) Err() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.err
+}
+
+// Close ends the subscription
+func (s *redisSubscription) Close() error {
+    s.mu.Lock()
+    if s.closed {
+        s.mu.Unlock()
+        return nil
+    }
+    s.closed = true
+    s.mu.Unlock()
+    return s.pubsub.Close()
+}
@@ -0,0 +1,160 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+)
+
+// tokenBucketScript refills the bucket based on elapsed time and takes tokens.
+// KEYS[1] = bucket key
+// ARGV[1] = capacity, ARGV[2] = refill rate in tokens per millisecond,
+// ARGV[3] = requested tokens
+// Returns {allowed, remaining, retry_after_ms, reset_after_ms}
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local requested = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= requested then
+    tokens = tokens - requested
+    allowed = 1
+else
+    retry = math.ceil((requested - tokens) / rate)
+end
+
+local reset = math.ceil((capacity - tokens) / rate)
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.max(reset, 1000))
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+// slidingWindowScript approximates a sliding window with the counters of the
+// current and previous fixed windows, weighting the previous one by how much
+// of it still overlaps the sliding window. Its cost does not depend on the
+// number of tokens.
+// KEYS[1] = window key
+// ARGV[1] = limit, ARGV[2] = window in milliseconds, ARGV[3] = requested tokens
+// Returns {allowed, remaining, retry_after_ms, reset_after_ms}
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local requested = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local current = math.floor(now / window)
+local elapsed = now - current * window
+local left = window - elapsed
+
+local state = redis.call('HMGET', KEYS[1], 'window', 'count', 'previous')
+local stored = tonumber(state[1])
+local count = 0
+local previous = 0
+if stored == current then
+    count = tonumber(state[2]) or 0
+    previous = tonumber(state[3]) or 0
+elseif stored == current - 1 then
+    previous = tonumber(state[2]) or 0
+end
+
+local used = previous * left / window + count
+if used + requested <= limit then
+    count = count + requested
+    redis.call('HSET', KEYS[1], 'window', current, 'count', count, 'previous', previous)
+    -- The count still weighs on the next window
+    redis.call('PEXPIRE', KEYS[1], left + window)
+    return {1, math.floor(limit - used - requested), 0, left + window}
+end
+
+-- Wait until the previous window has slid out enough, or else for the
+-- current count to slide out of the next window
+local retry = left + window
+if count + requested <= limit then
+    retry = math.ceil(left - (limit - count - requested) * window / previous)
+elseif count > 0 then
+    retry = math.ceil(left + (count + requested - limit) * window / count)
+end
+
+local reset = left
+if count > 0 then
+    reset = left + window
+end
+return {0, math.max(0, math.floor(limit - used)), math.max(retry, 1), reset}
+`)
+
+// Take consumes n tokens from the bucket according to the rule
+func (rc *RedisCache) Take(ctx context.Context, bucket string, limit RateLimit, n int64) (_ *RateLimitResult, err error) {
+    ctx, op := rc.startOperation(ctx, "ratelimit_take")
+    defer op.end(&err)
+
+    if !limit.Enabled() {
+        return &RateLimitResult{Allowed: true, Limit: limit.Limit, Remaining: limit.Limit}, nil
+    }
+    if n <= 0 {
+        n = 1
+    }
+
+    key := InternalKeyPrefix + "ratelimit:{" + bucket + "}"
+    windowMs := limit.Window.Milliseconds()
+
+    var values []interface{}
+    switch limit.Algorithm {
+    case AlgorithmSlidingWindow:
+        values, err = slidingWindowScript.Run(ctx, rc.client, []string{key}, limit.Limit, windowMs, n).Slice()
+    case AlgorithmTokenBucket, "":
+        capacity := limit.Burst
+        if capacity <= 0 {
+            capacity = limit.Limit
+        }
+        rate := float64(limit.Limit) / float64(windowMs)
+        values, err = tokenBucketScript.Run(ctx, rc.client, []string{key}, capacity, rate, n).Slice()
+    default:
+        return nil, fmt.Errorf("unknown rate limit algorithm: %s", limit.Algorithm)
+    }
+
+    if err != nil {
+        op.logger.Error("failed to take rate limit tokens", zap.Error(err), zap.String("bucket", bucket))
+        return nil, fmt.Errorf("failed to take rate limit tokens: %w", err)
+    }
+
+    nums := make([]int64, len(values))
+    for i, value := range values {
+        num, ok := value.(int64)
+        if !ok {
+            return nil, fmt.Errorf("unexpected rate limit script result: %v", values)
+        }
+        nums[i] = num
+    }
+    if len(nums) != 4 {
+        return nil, fmt.Errorf("unexpected rate limit script result: %v", values)
+    }
+
+    result := &RateLimitResult{
+        Allowed:    nums[0] == 1,
+        Limit:      limit.Limit,
+        Remaining:  nums[1],
+        RetryAfter: time.Duration(nums[2]) * time.Millisecond,
+        ResetAfter: time.Duration(nums[3]) * time.Millisecond,
+    }
+
+    if !result.Allowed {
+        op.logger.Debug("rate limit exceeded",
+            zap.String("bucket", bucket),
+            zap.Duration("retry_after", result.RetryAfter))
+    }
+
+    return result, nil
+}
@@ -0,0 +1,253 @@
+package cache
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "fmt"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+
+    "distributed-cache/pkg/models"
+)
+
+// claimJobsScript takes the due jobs of a topic and moves them to the end of
+// their lease, returning id, record and attempts of each one. Entries whose
+// record is gone are dropped.
+// KEYS[1] = due sorted set, KEYS[2] = job records, KEYS[3] = attempts
+// ARGV[1] = now in milliseconds, ARGV[2] = lease deadline in milliseconds, ARGV[3] = max jobs
+var claimJobsScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+local claimed = {}
+for _, id in ipairs(ids) do
+    local record = redis.call('HGET', KEYS[2], id)
+    if record then
+        redis.call('ZADD', KEYS[1], ARGV[2], id)
+        claimed[#claimed + 1] = id
+        claimed[#claimed + 1] = record
+        claimed[#claimed + 1] = redis.call('HINCRBY', KEYS[3], id, 1)
+    else
+        redis.call('ZREM', KEYS[1], id)
+    end
+end
+return claimed
+`)
+
+// retryJobScript reschedules a job unless it was acknowledged or canceled
+// KEYS[1] = due sorted set, KEYS[2] = job records
+// ARGV[1] = job id, ARGV[2] = due time in milliseconds
+var retryJobScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[2], ARGV[1]) == 0 then
+    return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1
+`)
+
+// scheduledJobRecord is the stored form of a job. The due time and attempts
+// live in their own keys so claims do not rewrite the payload.
+type scheduledJobRecord struct {
+    Payload   interface{} `json:"payload"`
+    CreatedAt int64       `json:"created_at"` // Milliseconds
+}
+
+// scheduleKeys returns the due sorted set, the job records hash and the
+// attempts hash of a topic. They are internal so the cache API cannot inject
+// or reschedule jobs, and the hash tag keeps them in the same cluster slot.
+func scheduleKeys(topic string) []string {
+    prefix := InternalKeyPrefix + "schedule:{" + topic + "}"
+    return []string{prefix + ":due", prefix + ":jobs", prefix + ":attempts"}
+}
+
+// newJobID returns a random job identifier
+func newJobID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
+
+// ScheduleJob registers payload to be delivered to topic at dueAt
+func (rc *RedisCache) ScheduleJob(ctx context.Context, topic string, payload interface{}, dueAt time.Time) (_ *models.ScheduledJob, err error) {
+    ctx, op := rc.startOperation(ctx, "schedule_job")
+    defer op.end(&err)
+
+    id, err := newJobID()
+    if err != nil {
+        return nil, fmt.Errorf("failed to generate job id: %w", err)
+    }
+
+    now := time.Now()
+    data, err := op.encode(scheduledJobRecord{Payload: payload, CreatedAt: now.UnixMilli()})
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal job: %w", err)
+    }
+
+    keys := scheduleKeys(topic)
+    // MULTI so a consumer never finds the job without its record
+    _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+        pipe.HSet(ctx, keys[1], id, data)
+        pipe.ZAdd(ctx, keys[0], &redis.Z{Score: float64(dueAt.UnixMilli()), Member: id})
+        return nil
+    })
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to schedule job", zap.Error(err), zap.String("topic", topic))
+        return nil, fmt.Errorf("failed to schedule job: %w", err)
+    }
+
+    op.logger.Debug("job scheduled",
+        zap.String("topic", topic),
+        zap.String("id", id),
+        zap.Time("due_at", dueAt))
+
+    return &models.ScheduledJob{
+        ID:        id,
+        Topic:     topic,
+        Payload:   payload,
+        DueAt:     time.UnixMilli(dueAt.UnixMilli()),
+        CreatedAt: time.UnixMilli(now.UnixMilli()),
+    }, nil
+}
+
+// ClaimJobs returns up to max due jobs of topic and hides them from other
+// consumers for lease
+func (rc *RedisCache) ClaimJobs(ctx context.Context, topic string, max int, lease time.Duration) (_ []*models.ScheduledJob, err error) {
+    ctx, op := rc.startOperation(ctx, "claim_jobs")
+    defer op.end(&err)
+
+    if max <= 0 || lease <= 0 {
+        return nil, fmt.Errorf("max and lease must be positive, got %d and %s", max, lease)
+    }
+
+    now := time.Now()
+    deadline := now.Add(lease)
+    result, err := claimJobsScript.Run(ctx, rc.client, scheduleKeys(topic),
+        now.UnixMilli(), deadline.UnixMilli(), max).Slice()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to claim jobs", zap.Error(err), zap.String("topic", topic))
+        return nil, fmt.Errorf("failed to claim jobs: %w", err)
+    }
+    op.batch(len(result) / 3)
+
+    jobs := make([]*models.ScheduledJob, 0, len(result)/3)
+    for i := 0; i+2 < len(result); i += 3 {
+        id, _ := result[i].(string)
+        data, _ := result[i+1].(string)
+        attempts, _ := result[i+2].(int64)
+
+        var record scheduledJobRecord
+        if err := op.decode([]byte(data), &record); err != nil {
+            return nil, fmt.Errorf("failed to unmarshal job %s: %w", id, err)
+        }
+        jobs = append(jobs, &models.ScheduledJob{
+            ID:        id,
+            Topic:     topic,
+            Payload:   record.Payload,
+            DueAt:     time.UnixMilli(deadline.UnixMilli()),
+            CreatedAt: time.UnixMilli(record.CreatedAt),
+            Attempts:  attempts,
+        })
+    }
+    return jobs, nil
+}
+
+// RetryJob makes the job due again after delay; false if it does not exist
+func (rc *RedisCache) RetryJob(ctx context.Context, topic, id string, delay time.Duration) (_ bool, err error) {
+    ctx, op := rc.startOperation(ctx, "retry_job")
+    defer op.end(&err)
+
+    dueAt := time.Now().Add(delay)
+    retried, err := retryJobScript.Run(ctx, rc.client, scheduleKeys(topic), id, dueAt.UnixMilli()).Int64()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return false, err
+        }
+        op.logger.Error("failed to retry job", zap.Error(err), zap.String("topic", topic), zap.String("id", id))
+        return false, fmt.Errorf("failed to retry job: %w", err)
+    }
+
+    if retried == 0 {
+        return false, nil
+    }
+    return true, nil
+}
+
+// GetJob returns the job, nil if it does not exist
+func (rc *RedisCache) GetJob(ctx context.Context, topic, id string) (_ *models.ScheduledJob, err error) {
+    ctx, op := rc.startOperation(ctx, "get_job")
+    defer op.end(&err)
+
+    keys := scheduleKeys(topic)
+    var record *redis.StringCmd
+    var dueAt *redis.FloatCmd
+    var attempts *redis.StringCmd
+    _, err = rc.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+        record = pipe.HGet(ctx, keys[1], id)
+        dueAt = pipe.ZScore(ctx, keys[0], id)
+        attempts = pipe.HGet(ctx, keys[2], id)
+        return nil
+    })
+    if err == redis.Nil {
+        err = nil
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to get job", zap.Error(err), zap.String("topic", topic), zap.String("id", id))
+        return nil, fmt.Errorf("failed to get job: %w", err)
+    }
+
+    data, err := record.Result()
+    if err == redis.Nil {
+        return nil, nil
+    }
+
+    var stored scheduledJobRecord
+    if err := op.decode([]byte(data), &stored); err != nil {
+        return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+    }
+    job := &models.ScheduledJob{
+        ID:        id,
+        Topic:     topic,
+        Payload:   stored.Payload,
+        DueAt:     time.UnixMilli(int64(dueAt.Val())),
+        CreatedAt: time.UnixMilli(stored.CreatedAt),
+    }
+    job.Attempts, _ = attempts.Int64()
+
+    return job, nil
+}
+
+// DeleteJob acknowledges or cancels the job and reports whether it existed
+func (rc *RedisCache) DeleteJob(ctx context.Context, topic, id string) (_ bool, err error) {
+    ctx, op := rc.startOperation(ctx, "delete_job")
+    defer op.end(&err)
+
+    keys := scheduleKeys(topic)
+    var deleted *redis.IntCmd
+    _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+        pipe.ZRem(ctx, keys[0], id)
+        deleted = pipe.HDel(ctx, keys[1], id)
+        pipe.HDel(ctx, keys[2], id)
+        return nil
+    })
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return false, err
+        }
+        op.logger.Error("failed to delete job", zap.Error(err), zap.String("topic", topic), zap.String("id", id))
+        return false, fmt.Errorf("failed to delete job: %w", err)
+    }
+
+    return deleted.Val() > 0, nil
+}
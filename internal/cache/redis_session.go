@@ -0,0 +1,396 @@
+package cache
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/base64"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+
+    "distributed-cache/pkg/models"
+)
+
+// Each session is a hash with its metadata fields and one "attr:<name>"
+// field per attribute, so attributes are updated without read-modify-write
+const sessionAttributePrefix = "attr:"
+
+// touchSessionScript sets and removes attributes and extends the session by
+// its TTL, returning the whole hash, or nil if the session does not exist.
+// KEYS[1] = session key
+// ARGV[1] = now in milliseconds, ARGV[2] = number of fields to set, followed
+// by the field/value pairs and then the fields to remove
+var touchSessionScript = redis.NewScript(`
+local ttl = redis.call('HGET', KEYS[1], 'ttl')
+if not ttl then
+    return false
+end
+local n = tonumber(ARGV[2])
+for i = 3, 2 + n * 2, 2 do
+    redis.call('HSET', KEYS[1], ARGV[i], ARGV[i + 1])
+end
+for i = 3 + n * 2, #ARGV do
+    redis.call('HDEL', KEYS[1], ARGV[i])
+end
+redis.call('HSET', KEYS[1], 'accessed_at', ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ttl)
+return redis.call('HGETALL', KEYS[1])
+`)
+
+// indexSessionScript records when a session of the user expires, drops the
+// expired ones and keeps the index alive as long as its last session.
+// KEYS[1] = user index
+// ARGV[1] = session id, ARGV[2] = expiry in milliseconds, ARGV[3] = now in milliseconds
+var indexSessionScript = redis.NewScript(`
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[3])
+local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+redis.call('PEXPIREAT', KEYS[1], string.format('%d', tonumber(last[2])))
+return 1
+`)
+
+// deleteSessionScript deletes a session and returns its user
+// KEYS[1] = session key
+var deleteSessionScript = redis.NewScript(`
+local user = redis.call('HGET', KEYS[1], 'user_id')
+if not user then
+    return false
+end
+redis.call('DEL', KEYS[1])
+return user
+`)
+
+// popUserSessionsScript deletes the index of a user and returns its sessions,
+// so sessions created afterwards go to a new index
+// KEYS[1] = user index
+var popUserSessionsScript = redis.NewScript(`
+local ids = redis.call('ZRANGE', KEYS[1], 0, -1)
+redis.call('DEL', KEYS[1])
+return ids
+`)
+
+// sessionKey returns the Redis key of a session
+func sessionKey(id string) string {
+    return InternalKeyPrefix + "session:" + id
+}
+
+// sessionIndexKey returns the Redis key of the sessions index of a user
+func sessionIndexKey(userID string) string {
+    return InternalKeyPrefix + "sessions:user:" + userID
+}
+
+// newSessionID returns 256 random bits encoded for use in URLs and cookies
+func newSessionID() (string, error) {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreateSession starts a session for userID with a random ID
+func (rc *RedisCache) CreateSession(ctx context.Context, userID string, attributes map[string]interface{}, ttl time.Duration) (_ *models.Session, err error) {
+    ctx, op := rc.startOperation(ctx, "session_create")
+    defer op.end(&err)
+
+    if ttl <= 0 {
+        return nil, fmt.Errorf("session ttl must be positive, got %s", ttl)
+    }
+
+    id, err := newSessionID()
+    if err != nil {
+        return nil, fmt.Errorf("failed to generate session id: %w", err)
+    }
+
+    now := time.Now()
+    nowMillis := strconv.FormatInt(now.UnixMilli(), 10)
+    values := []interface{}{
+        "user_id", userID,
+        "created_at", nowMillis,
+        "accessed_at", nowMillis,
+        "ttl", ttl.Milliseconds(),
+    }
+    for name, value := range attributes {
+        if value == nil {
+            continue
+        }
+        data, err := op.encode(value)
+        if err != nil {
+            return nil, fmt.Errorf("failed to marshal attribute %s: %w", name, err)
+        }
+        values = append(values, sessionAttributePrefix+name, data)
+    }
+
+    // MULTI so the session is never visible without its TTL
+    _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+        pipe.HSet(ctx, sessionKey(id), values...)
+        pipe.PExpire(ctx, sessionKey(id), ttl)
+        return nil
+    })
+    if err != nil {
+        op.logger.Error("failed to create session", zap.Error(err), zap.String("user_id", userID))
+        return nil, fmt.Errorf("failed to create session: %w", err)
+    }
+
+    session := &models.Session{
+        ID:             id,
+        UserID:         userID,
+        Attributes:     make(map[string]interface{}, len(attributes)),
+        TTL:            ttl,
+        CreatedAt:      time.UnixMilli(now.UnixMilli()),
+        LastAccessedAt: time.UnixMilli(now.UnixMilli()),
+    }
+    session.ExpiresAt = session.LastAccessedAt.Add(ttl)
+    for name, value := range attributes {
+        if value != nil {
+            session.Attributes[name] = value
+        }
+    }
+
+    if err := rc.indexSession(ctx, session); err != nil {
+        op.logger.Error("failed to index session", zap.Error(err), zap.String("user_id", userID))
+        return nil, err
+    }
+
+    op.logger.Debug("session created", zap.String("user_id", userID))
+    return session, nil
+}
+
+// GetSession returns the session, nil if it does not exist or expired
+func (rc *RedisCache) GetSession(ctx context.Context, id string) (_ *models.Session, err error) {
+    ctx, op := rc.startOperation(ctx, "session_get")
+    defer op.end(&err)
+
+    fields, err := rc.client.HGetAll(ctx, sessionKey(id)).Result()
+    if err != nil {
+        op.logger.Error("failed to get session", zap.Error(err))
+        return nil, fmt.Errorf("failed to get session: %w", err)
+    }
+    if len(fields) == 0 {
+        return nil, nil
+    }
+    return parseSession(op, id, fields)
+}
+
+// UpdateSession merges attributes into the session and extends it
+func (rc *RedisCache) UpdateSession(ctx context.Context, id string, attributes map[string]interface{}) (_ *models.Session, err error) {
+    ctx, op := rc.startOperation(ctx, "session_update")
+    defer op.end(&err)
+
+    var set, removed []interface{}
+    for name, value := range attributes {
+        if value == nil {
+            removed = append(removed, sessionAttributePrefix+name)
+            continue
+        }
+        data, err := op.encode(value)
+        if err != nil {
+            return nil, fmt.Errorf("failed to marshal attribute %s: %w", name, err)
+        }
+        set = append(set, sessionAttributePrefix+name, data)
+    }
+
+    args := append([]interface{}{time.Now().UnixMilli(), len(set) / 2}, set...)
+    return rc.touchSession(ctx, op, id, append(args, removed...))
+}
+
+// TouchSession extends the session by its TTL
+func (rc *RedisCache) TouchSession(ctx context.Context, id string) (_ *models.Session, err error) {
+    ctx, op := rc.startOperation(ctx, "session_touch")
+    defer op.end(&err)
+
+    return rc.touchSession(ctx, op, id, []interface{}{time.Now().UnixMilli(), 0})
+}
+
+// touchSession runs touchSessionScript and moves the session expiry in the index
+func (rc *RedisCache) touchSession(ctx context.Context, op *operation, id string, args []interface{}) (*models.Session, error) {
+    result, err := touchSessionScript.Run(ctx, rc.client, []string{sessionKey(id)}, args...).StringSlice()
+    if err == redis.Nil {
+        return nil, nil
+    }
+    if err != nil {
+        op.logger.Error("failed to update session", zap.Error(err))
+        return nil, fmt.Errorf("failed to update session: %w", err)
+    }
+
+    fields := make(map[string]string, len(result)/2)
+    for i := 0; i+1 < len(result); i += 2 {
+        fields[result[i]] = result[i+1]
+    }
+    session, err := parseSession(op, id, fields)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := rc.indexSession(ctx, session); err != nil {
+        op.logger.Error("failed to index session", zap.Error(err), zap.String("user_id", session.UserID))
+        return nil, err
+    }
+    return session, nil
+}
+
+// DeleteSession destroys the session and reports whether it existed
+func (rc *RedisCache) DeleteSession(ctx context.Context, id string) (_ bool, err error) {
+    ctx, op := rc.startOperation(ctx, "session_delete")
+    defer op.end(&err)
+
+    userID, err := deleteSessionScript.Run(ctx, rc.client, []string{sessionKey(id)}).Text()
+    if err == redis.Nil {
+        return false, nil
+    }
+    if err != nil {
+        op.logger.Error("failed to delete session", zap.Error(err))
+        return false, fmt.Errorf("failed to delete session: %w", err)
+    }
+
+    // A stale index entry is harmless, listing skips it
+    if err := rc.client.ZRem(ctx, sessionIndexKey(userID), id).Err(); err != nil {
+        op.logger.Warn("failed to remove session from index", zap.Error(err), zap.String("user_id", userID))
+    }
+
+    op.logger.Debug("session deleted", zap.String("user_id", userID))
+    return true, nil
+}
+
+// ListUserSessions returns the live sessions of userID, oldest first
+func (rc *RedisCache) ListUserSessions(ctx context.Context, userID string) (_ []*models.Session, err error) {
+    ctx, op := rc.startOperation(ctx, "session_list")
+    defer op.end(&err)
+
+    index := sessionIndexKey(userID)
+    now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+    var ids *redis.StringSliceCmd
+    _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+        pipe.ZRemRangeByScore(ctx, index, "-inf", now)
+        ids = pipe.ZRange(ctx, index, 0, -1)
+        return nil
+    })
+    if err != nil {
+        op.logger.Error("failed to list sessions", zap.Error(err), zap.String("user_id", userID))
+        return nil, fmt.Errorf("failed to list sessions: %w", err)
+    }
+    if len(ids.Val()) == 0 {
+        return []*models.Session{}, nil
+    }
+
+    cmds := make([]*redis.StringStringMapCmd, len(ids.Val()))
+    _, err = rc.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+        for i, id := range ids.Val() {
+            cmds[i] = pipe.HGetAll(ctx, sessionKey(id))
+        }
+        return nil
+    })
+    if err != nil {
+        op.logger.Error("failed to get sessions", zap.Error(err), zap.String("user_id", userID))
+        return nil, fmt.Errorf("failed to get sessions: %w", err)
+    }
+
+    sessions := make([]*models.Session, 0, len(cmds))
+    var stale []interface{}
+    for i, cmd := range cmds {
+        if len(cmd.Val()) == 0 {
+            stale = append(stale, ids.Val()[i])
+            continue
+        }
+        session, err := parseSession(op, ids.Val()[i], cmd.Val())
+        if err != nil {
+            return nil, err
+        }
+        sessions = append(sessions, session)
+    }
+
+    if len(stale) > 0 {
+        if err := rc.client.ZRem(ctx, index, stale...).Err(); err != nil {
+            op.logger.Warn("failed to remove stale sessions from index", zap.Error(err), zap.String("user_id", userID))
+        }
+    }
+
+    sort.Slice(sessions, func(i, j int) bool {
+        return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+    })
+    return sessions, nil
+}
+
+// DeleteUserSessions destroys every session of userID
+func (rc *RedisCache) DeleteUserSessions(ctx context.Context, userID string) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "session_delete_user")
+    defer op.end(&err)
+
+    ids, err := popUserSessionsScript.Run(ctx, rc.client, []string{sessionIndexKey(userID)}).StringSlice()
+    if err != nil {
+        op.logger.Error("failed to delete user sessions", zap.Error(err), zap.String("user_id", userID))
+        return 0, fmt.Errorf("failed to delete user sessions: %w", err)
+    }
+    if len(ids) == 0 {
+        return 0, nil
+    }
+
+    // One DEL per session, they may live in different cluster slots
+    cmds := make([]*redis.IntCmd, len(ids))
+    _, err = rc.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+        for i, id := range ids {
+            cmds[i] = pipe.Del(ctx, sessionKey(id))
+        }
+        return nil
+    })
+    if err != nil {
+        op.logger.Error("failed to delete user sessions", zap.Error(err), zap.String("user_id", userID))
+        return 0, fmt.Errorf("failed to delete user sessions: %w", err)
+    }
+
+    var deleted int64
+    for _, cmd := range cmds {
+        deleted += cmd.Val()
+    }
+
+    op.logger.Debug("user sessions deleted", zap.String("user_id", userID), zap.Int64("sessions", deleted))
+    return deleted, nil
+}
+
+// indexSession records the session expiry in the index of its user
+func (rc *RedisCache) indexSession(ctx context.Context, session *models.Session) error {
+    err := indexSessionScript.Run(ctx, rc.client, []string{sessionIndexKey(session.UserID)},
+        session.ID, session.ExpiresAt.UnixMilli(), time.Now().UnixMilli()).Err()
+    if err != nil {
+        return fmt.Errorf("failed to index session: %w", err)
+    }
+    return nil
+}
+
+// parseSession builds a session from the fields of its hash
+func parseSession(op *operation, id string, fields map[string]string) (*models.Session, error) {
+    session := &models.Session{
+        ID:         id,
+        UserID:     fields["user_id"],
+        Attributes: make(map[string]interface{}),
+    }
+
+    millis := func(name string) int64 {
+        value, _ := strconv.ParseInt(fields[name], 10, 64)
+        return value
+    }
+    session.TTL = time.Duration(millis("ttl")) * time.Millisecond
+    session.CreatedAt = time.UnixMilli(millis("created_at"))
+    session.LastAccessedAt = time.UnixMilli(millis("accessed_at"))
+    session.ExpiresAt = session.LastAccessedAt.Add(session.TTL)
+
+    for field, data := range fields {
+        name, ok := strings.CutPrefix(field, sessionAttributePrefix)
+        if !ok {
+            continue
+        }
+        var value interface{}
+        if err := op.decode([]byte(data), &value); err != nil {
+            op.logger.Error("failed to unmarshal session attribute", zap.Error(err), zap.String("attribute", name))
+            return nil, fmt.Errorf("failed to unmarshal attribute %s: %w", name, err)
+        }
+        session.Attributes[name] = value
+    }
+    return session, nil
+}
@@ -0,0 +1,165 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+)
+
+// SAdd adds members and returns how many were new
+func (rc *RedisCache) SAdd(ctx context.Context, key string, members []string, ttl time.Duration) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "sadd", key)
+    defer op.end(&err)
+    op.batch(len(members))
+
+    values := make([]interface{}, len(members))
+    for i, member := range members {
+        values[i] = member
+    }
+
+    // Pending write-behind or fallback writes of key go first
+    var added *redis.IntCmd
+    if err = rc.settleKey(ctx, key); err == nil {
+        _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+            added = pipe.SAdd(ctx, key, values...)
+            if ttl > 0 {
+                pipe.Expire(ctx, key, ttl)
+            }
+            return nil
+        })
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to add set members", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to add set members: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    return added.Val(), nil
+}
+
+// SRem removes members and returns how many existed
+func (rc *RedisCache) SRem(ctx context.Context, key string, members ...string) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "srem", key)
+    defer op.end(&err)
+    op.batch(len(members))
+
+    values := make([]interface{}, len(members))
+    for i, member := range members {
+        values[i] = member
+    }
+
+    var removed int64
+    if err = rc.settleKey(ctx, key); err == nil {
+        removed, err = rc.client.SRem(ctx, key, values...).Result()
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to remove set members", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to remove set members: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    return removed, nil
+}
+
+// SIsMember reports whether member belongs to the set
+func (rc *RedisCache) SIsMember(ctx context.Context, key, member string) (_ bool, err error) {
+    ctx, op := rc.startOperation(ctx, "sismember", key)
+    defer op.end(&err)
+
+    isMember, err := rc.client.SIsMember(ctx, key, member).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return false, err
+        }
+        op.logger.Error("failed to check set membership", zap.Error(err), zap.String("key", key))
+        return false, fmt.Errorf("failed to check set membership: %w", err)
+    }
+
+    if isMember {
+        op.access(key, AccessHit, 0)
+    } else {
+        op.access(key, AccessMiss, 0)
+    }
+    return isMember, nil
+}
+
+// SScan returns a page of members matching match, starting at cursor
+func (rc *RedisCache) SScan(ctx context.Context, key string, cursor uint64, match string, count int64) (_ []string, _ uint64, err error) {
+    ctx, op := rc.startOperation(ctx, "sscan", key)
+    defer op.end(&err)
+
+    members, next, err := rc.client.SScan(ctx, key, cursor, match, count).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, 0, err
+        }
+        op.logger.Error("failed to scan set members", zap.Error(err), zap.String("key", key))
+        return nil, 0, fmt.Errorf("failed to scan set members: %w", err)
+    }
+
+    if len(members) > 0 {
+        op.access(key, AccessHit, 0)
+    }
+    return members, next, nil
+}
+
+// SCard returns the number of members
+func (rc *RedisCache) SCard(ctx context.Context, key string) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "scard", key)
+    defer op.end(&err)
+
+    count, err := rc.client.SCard(ctx, key).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to count set members", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to count set members: %w", err)
+    }
+    return count, nil
+}
+
+// SUnion returns the members of any of the sets. In a cluster the keys must
+// share a hash slot.
+func (rc *RedisCache) SUnion(ctx context.Context, keys ...string) (_ []string, err error) {
+    ctx, op := rc.startOperation(ctx, "sunion", keys...)
+    defer op.end(&err)
+    op.batch(len(keys))
+
+    members, err := rc.client.SUnion(ctx, keys...).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to compute set union", zap.Error(err), zap.Strings("keys", keys))
+        return nil, fmt.Errorf("failed to compute set union: %w", err)
+    }
+    return members, nil
+}
+
+// SInter returns the members common to every set. In a cluster the keys
+// must share a hash slot.
+func (rc *RedisCache) SInter(ctx context.Context, keys ...string) (_ []string, err error) {
+    ctx, op := rc.startOperation(ctx, "sinter", keys...)
+    defer op.end(&err)
+    op.batch(len(keys))
+
+    members, err := rc.client.SInter(ctx, keys...).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to compute set intersection", zap.Error(err), zap.Strings("keys", keys))
+        return nil, fmt.Errorf("failed to compute set intersection: %w", err)
+    }
+    return members, nil
+}
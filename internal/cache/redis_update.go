@@ -0,0 +1,100 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+
+    "distributed-cache/pkg/models"
+)
+
+// maxUpdateRetries WATCH attempts of an update before giving up
+const maxUpdateRetries = 10
+
+// Update replaces the value stored at key by the result of fn under WATCH,
+// retrying when the key is written concurrently
+func (rc *RedisCache) Update(ctx context.Context, key string, fn UpdateFunc) (_ *models.CacheItem, err error) {
+    ctx, op := rc.startOperation(ctx, "update", key)
+    defer op.end(&err)
+
+    // The update starts from Redis, so newer queued or local writes go first
+    if err = rc.settleKey(ctx, key); err != nil {
+        op.logger.Error("failed to write pending value before update", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to update cache item: %w", err)
+    }
+
+    var (
+        item *models.CacheItem
+        data []byte
+    )
+    for attempt := 0; attempt < maxUpdateRetries; attempt++ {
+        item = nil
+        err = rc.client.Watch(ctx, func(tx *redis.Tx) error {
+            current, err := tx.Get(ctx, key).Bytes()
+            if err == redis.Nil {
+                return nil
+            }
+            if err != nil {
+                return err
+            }
+
+            var cacheItem models.CacheItem
+            if err := op.decode(current, &cacheItem); err != nil {
+                return fmt.Errorf("failed to unmarshal cache item: %w", err)
+            }
+            if cacheItem.IsExpired() {
+                return nil
+            }
+
+            value, err := fn(cacheItem.Value)
+            if err != nil {
+                return &RejectedUpdateError{Err: err}
+            }
+            cacheItem.Value = value
+            updated, err := op.encode(&cacheItem)
+            if err != nil {
+                return fmt.Errorf("failed to marshal cache item: %w", err)
+            }
+
+            // KEEPTTL leaves the expiration in Redis as it is
+            _, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+                pipe.Set(ctx, key, updated, redis.KeepTTL)
+                return nil
+            })
+            if err == nil {
+                item, data = &cacheItem, updated
+            }
+            return err
+        }, key)
+
+        if err != redis.TxFailedErr {
+            break
+        }
+    }
+
+    switch err = dataTypeError(err); {
+    case err == redis.TxFailedErr:
+        err = ErrUpdateConflict
+        op.logger.Warn("cache item update kept conflicting", zap.String("key", key), zap.Int("attempts", maxUpdateRetries))
+        return nil, err
+    case isOutcome(err):
+        return nil, err
+    case err != nil:
+        op.logger.Error("failed to update cache item", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to update cache item: %w", err)
+    }
+
+    if item == nil {
+        op.access(key, AccessMiss, 0)
+        return nil, nil
+    }
+
+    if rc.local != nil {
+        rc.local.remember(key, data, item.ExpiresAt)
+    }
+    op.access(key, AccessSet, len(data))
+    op.logger.Debug("cache item updated", zap.String("key", key))
+    return item, nil
+}
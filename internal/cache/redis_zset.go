@@ -0,0 +1,217 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "go.uber.org/zap"
+)
+
+// ZAdd adds or updates members and returns how many were new
+func (rc *RedisCache) ZAdd(ctx context.Context, key string, members []ScoredMember, ttl time.Duration) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "zadd", key)
+    defer op.end(&err)
+    op.batch(len(members))
+
+    values := make([]*redis.Z, len(members))
+    for i, m := range members {
+        values[i] = &redis.Z{Score: m.Score, Member: m.Member}
+    }
+
+    // Pending write-behind or fallback writes of key go first
+    var added *redis.IntCmd
+    if err = rc.settleKey(ctx, key); err == nil {
+        _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+            added = pipe.ZAdd(ctx, key, values...)
+            if ttl > 0 {
+                pipe.Expire(ctx, key, ttl)
+            }
+            return nil
+        })
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to add sorted set members", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to add sorted set members: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    return added.Val(), nil
+}
+
+// ZIncrBy adds delta to the score of member and returns the new score
+func (rc *RedisCache) ZIncrBy(ctx context.Context, key, member string, delta float64, ttl time.Duration) (_ float64, err error) {
+    ctx, op := rc.startOperation(ctx, "zincrby", key)
+    defer op.end(&err)
+
+    var score *redis.FloatCmd
+    if err = rc.settleKey(ctx, key); err == nil {
+        _, err = rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+            score = pipe.ZIncrBy(ctx, key, delta, member)
+            if ttl > 0 {
+                pipe.Expire(ctx, key, ttl)
+            }
+            return nil
+        })
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to increment sorted set score", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to increment sorted set score: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    return score.Val(), nil
+}
+
+// ZRem removes members and returns how many existed
+func (rc *RedisCache) ZRem(ctx context.Context, key string, members ...string) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "zrem", key)
+    defer op.end(&err)
+    op.batch(len(members))
+
+    values := make([]interface{}, len(members))
+    for i, member := range members {
+        values[i] = member
+    }
+
+    var removed int64
+    if err = rc.settleKey(ctx, key); err == nil {
+        removed, err = rc.client.ZRem(ctx, key, values...).Result()
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to remove sorted set members", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to remove sorted set members: %w", err)
+    }
+
+    op.access(key, AccessSet, 0)
+    return removed, nil
+}
+
+// ZRank returns the rank and score of member
+func (rc *RedisCache) ZRank(ctx context.Context, key, member string, reverse bool) (_ int64, _ float64, _ bool, err error) {
+    ctx, op := rc.startOperation(ctx, "zrank", key)
+    defer op.end(&err)
+
+    var rank *redis.IntCmd
+    var score *redis.FloatCmd
+    _, err = rc.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+        if reverse {
+            rank = pipe.ZRevRank(ctx, key, member)
+        } else {
+            rank = pipe.ZRank(ctx, key, member)
+        }
+        score = pipe.ZScore(ctx, key, member)
+        return nil
+    })
+    if err == redis.Nil {
+        op.access(key, AccessMiss, 0)
+        return 0, 0, false, nil
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, 0, false, err
+        }
+        op.logger.Error("failed to get sorted set rank", zap.Error(err), zap.String("key", key))
+        return 0, 0, false, fmt.Errorf("failed to get sorted set rank: %w", err)
+    }
+
+    op.access(key, AccessHit, 0)
+    return rank.Val(), score.Val(), true, nil
+}
+
+// ZRange returns the members between ranks start and stop
+func (rc *RedisCache) ZRange(ctx context.Context, key string, start, stop int64, reverse bool) (_ []ScoredMember, err error) {
+    ctx, op := rc.startOperation(ctx, "zrange", key)
+    defer op.end(&err)
+
+    var results []redis.Z
+    if reverse {
+        results, err = rc.client.ZRevRangeWithScores(ctx, key, start, stop).Result()
+    } else {
+        results, err = rc.client.ZRangeWithScores(ctx, key, start, stop).Result()
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to get sorted set range", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to get sorted set range: %w", err)
+    }
+
+    return scoredMembers(op, key, results), nil
+}
+
+// ZRangeByScore returns the members whose score is inside the range
+func (rc *RedisCache) ZRangeByScore(ctx context.Context, key string, scores ScoreRange, reverse bool) (_ []ScoredMember, err error) {
+    ctx, op := rc.startOperation(ctx, "zrangebyscore", key)
+    defer op.end(&err)
+
+    by := &redis.ZRangeBy{Min: scores.Min, Max: scores.Max, Offset: scores.Offset, Count: scores.Count}
+    if by.Min == "" {
+        by.Min = "-inf"
+    }
+    if by.Max == "" {
+        by.Max = "+inf"
+    }
+    if by.Count == 0 && by.Offset > 0 {
+        by.Count = -1 // LIMIT needs a count; negative means all
+    }
+
+    var results []redis.Z
+    if reverse {
+        results, err = rc.client.ZRevRangeByScoreWithScores(ctx, key, by).Result()
+    } else {
+        results, err = rc.client.ZRangeByScoreWithScores(ctx, key, by).Result()
+    }
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return nil, err
+        }
+        op.logger.Error("failed to get sorted set range by score", zap.Error(err), zap.String("key", key))
+        return nil, fmt.Errorf("failed to get sorted set range by score: %w", err)
+    }
+
+    return scoredMembers(op, key, results), nil
+}
+
+// ZCard returns the number of members
+func (rc *RedisCache) ZCard(ctx context.Context, key string) (_ int64, err error) {
+    ctx, op := rc.startOperation(ctx, "zcard", key)
+    defer op.end(&err)
+
+    count, err := rc.client.ZCard(ctx, key).Result()
+    if err = dataTypeError(err); err != nil {
+        if isOutcome(err) {
+            return 0, err
+        }
+        op.logger.Error("failed to count sorted set members", zap.Error(err), zap.String("key", key))
+        return 0, fmt.Errorf("failed to count sorted set members: %w", err)
+    }
+    return count, nil
+}
+
+// scoredMembers converts a range reply and reports the read
+func scoredMembers(op *operation, key string, results []redis.Z) []ScoredMember {
+    members := make([]ScoredMember, len(results))
+    for i, z := range results {
+        member, _ := z.Member.(string)
+        members[i] = ScoredMember{Member: member, Score: z.Score}
+    }
+
+    if len(members) == 0 {
+        op.access(key, AccessMiss, 0)
+    } else {
+        op.access(key, AccessHit, 0)
+    }
+    return members
+}
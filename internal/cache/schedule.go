@@ -0,0 +1,25 @@
+package cache
+
+import (
+    "context"
+    "time"
+
+    "distributed-cache/pkg/models"
+)
+
+// JobScheduler defines delayed job operations. Jobs become due at a point in
+// time and are delivered at least once: a claimed job is redelivered when
+// its lease expires unless it is acknowledged with DeleteJob first.
+type JobScheduler interface {
+    // ScheduleJob registers payload to be delivered to topic at dueAt
+    ScheduleJob(ctx context.Context, topic string, payload interface{}, dueAt time.Time) (*models.ScheduledJob, error)
+    // ClaimJobs returns up to max due jobs of topic and hides them from other
+    // consumers for lease
+    ClaimJobs(ctx context.Context, topic string, max int, lease time.Duration) ([]*models.ScheduledJob, error)
+    // RetryJob makes the job due again after delay; false if it does not exist
+    RetryJob(ctx context.Context, topic, id string, delay time.Duration) (bool, error)
+    // GetJob returns the job, nil if it does not exist
+    GetJob(ctx context.Context, topic, id string) (*models.ScheduledJob, error)
+    // DeleteJob acknowledges or cancels the job and reports whether it existed
+    DeleteJob(ctx context.Context, topic, id string) (bool, error)
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRedisCache_ScheduledJobs(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    due, err := cache.ScheduleJob(ctx, "emails", map[string]interface{}{"to": "ana@example.com"}, time.Now().Add(-time.Second))
+    require.NoError(t, err)
+    later, err := cache.ScheduleJob(ctx, "emails", "reminder", time.Now().Add(time.Hour))
+    require.NoError(t, err)
+    assert.NotEqual(t, due.ID, later.ID)
+
+    // Las claves de los trabajos son internas y no se ven desde la API de caché
+    keys, err := cache.Keys(ctx, "*")
+    require.NoError(t, err)
+    assert.Empty(t, keys)
+
+    // Solo se reparten los trabajos vencidos
+    jobs, err := cache.ClaimJobs(ctx, "emails", 10, time.Minute)
+    require.NoError(t, err)
+    require.Len(t, jobs, 1)
+    assert.Equal(t, due.ID, jobs[0].ID)
+    assert.Equal(t, map[string]interface{}{"to": "ana@example.com"}, jobs[0].Payload)
+    assert.Equal(t, int64(1), jobs[0].Attempts)
+    assert.True(t, jobs[0].DueAt.After(time.Now()))
+
+    // Durante el lease nadie más lo recibe
+    jobs, err = cache.ClaimJobs(ctx, "emails", 10, time.Minute)
+    require.NoError(t, err)
+    assert.Empty(t, jobs)
+
+    // Sin ack se vuelve a entregar
+    retried, err := cache.RetryJob(ctx, "emails", due.ID, 0)
+    require.NoError(t, err)
+    assert.True(t, retried)
+    jobs, err = cache.ClaimJobs(ctx, "emails", 10, time.Minute)
+    require.NoError(t, err)
+    require.Len(t, jobs, 1)
+    assert.Equal(t, int64(2), jobs[0].Attempts)
+
+    job, err := cache.GetJob(ctx, "emails", later.ID)
+    require.NoError(t, err)
+    require.NotNil(t, job)
+    assert.Equal(t, "reminder", job.Payload)
+    assert.Equal(t, later.DueAt, job.DueAt)
+    assert.Zero(t, job.Attempts)
+
+    deleted, err := cache.DeleteJob(ctx, "emails", due.ID)
+    require.NoError(t, err)
+    assert.True(t, deleted)
+    deleted, err = cache.DeleteJob(ctx, "emails", due.ID)
+    require.NoError(t, err)
+    assert.False(t, deleted)
+
+    retried, err = cache.RetryJob(ctx, "emails", due.ID, 0)
+    require.NoError(t, err)
+    assert.False(t, retried)
+    job, err = cache.GetJob(ctx, "emails", due.ID)
+    require.NoError(t, err)
+    assert.Nil(t, job)
+
+    // Los temas están aislados
+    _, err = cache.ScheduleJob(ctx, "sms", "hello", time.Now())
+    require.NoError(t, err)
+    jobs, err = cache.ClaimJobs(ctx, "emails", 10, time.Minute)
+    require.NoError(t, err)
+    assert.Empty(t, jobs)
+}
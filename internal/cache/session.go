@@ -0,0 +1,29 @@
+package cache
+
+import (
+    "context"
+    "time"
+
+    "distributed-cache/pkg/models"
+)
+
+// SessionStore defines session operations. Sessions expire after their TTL
+// without activity; updating or touching a session extends it. Every session
+// is indexed by user so all of them can be listed or destroyed at once.
+type SessionStore interface {
+    // CreateSession starts a session for userID with a random ID
+    CreateSession(ctx context.Context, userID string, attributes map[string]interface{}, ttl time.Duration) (*models.Session, error)
+    // GetSession returns the session, nil if it does not exist or expired
+    GetSession(ctx context.Context, id string) (*models.Session, error)
+    // UpdateSession merges attributes into the session and extends it; a nil
+    // value removes the attribute. Returns nil if the session does not exist.
+    UpdateSession(ctx context.Context, id string, attributes map[string]interface{}) (*models.Session, error)
+    // TouchSession extends the session by its TTL, nil if it does not exist
+    TouchSession(ctx context.Context, id string) (*models.Session, error)
+    // DeleteSession destroys the session and reports whether it existed
+    DeleteSession(ctx context.Context, id string) (bool, error)
+    // ListUserSessions returns the live sessions of userID
+    ListUserSessions(ctx context.Context, userID string) ([]*models.Session, error)
+    // DeleteUserSessions destroys every session of userID and returns how many existed
+    DeleteUserSessions(ctx context.Context, userID string) (int64, error)
+}
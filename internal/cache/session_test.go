@@ -0,0 +1,128 @@
+package cache
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRedisCache_Session(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    session, err := cache.CreateSession(ctx, "ada", map[string]interface{}{"theme": "dark", "cart": []interface{}{"book"}}, time.Minute)
+    require.NoError(t, err)
+    assert.Len(t, session.ID, 43)
+    assert.Equal(t, "ada", session.UserID)
+    assert.Equal(t, time.Minute, session.TTL)
+
+    stored, err := cache.GetSession(ctx, session.ID)
+    require.NoError(t, err)
+    require.NotNil(t, stored)
+    assert.Equal(t, session.Attributes, stored.Attributes)
+    assert.True(t, session.CreatedAt.Equal(stored.CreatedAt))
+    assert.True(t, session.ExpiresAt.Equal(stored.ExpiresAt))
+
+    // Actualizar atributos; null elimina el atributo
+    time.Sleep(5 * time.Millisecond)
+    updated, err := cache.UpdateSession(ctx, session.ID, map[string]interface{}{"theme": nil, "lang": "es"})
+    require.NoError(t, err)
+    require.NotNil(t, updated)
+    assert.Equal(t, map[string]interface{}{"lang": "es", "cart": []interface{}{"book"}}, updated.Attributes)
+    assert.True(t, updated.LastAccessedAt.After(session.LastAccessedAt))
+    assert.True(t, updated.ExpiresAt.After(session.ExpiresAt))
+
+    // Touch extiende la sesión
+    ttl, err := cache.client.PTTL(ctx, sessionKey(session.ID)).Result()
+    require.NoError(t, err)
+    assert.InDelta(t, time.Minute.Seconds(), ttl.Seconds(), 1)
+
+    touched, err := cache.TouchSession(ctx, session.ID)
+    require.NoError(t, err)
+    require.NotNil(t, touched)
+    assert.Equal(t, updated.Attributes, touched.Attributes)
+
+    deleted, err := cache.DeleteSession(ctx, session.ID)
+    require.NoError(t, err)
+    assert.True(t, deleted)
+
+    // Sesiones inexistentes
+    missing, err := cache.GetSession(ctx, session.ID)
+    require.NoError(t, err)
+    assert.Nil(t, missing)
+    missing, err = cache.TouchSession(ctx, session.ID)
+    require.NoError(t, err)
+    assert.Nil(t, missing)
+    missing, err = cache.UpdateSession(ctx, session.ID, map[string]interface{}{"lang": "en"})
+    require.NoError(t, err)
+    assert.Nil(t, missing)
+    deleted, err = cache.DeleteSession(ctx, session.ID)
+    require.NoError(t, err)
+    assert.False(t, deleted)
+
+    _, err = cache.CreateSession(ctx, "ada", nil, 0)
+    assert.Error(t, err)
+}
+
+func TestRedisCache_UserSessions(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    var ids []string
+    for i := 0; i < 3; i++ {
+        session, err := cache.CreateSession(ctx, "grace", nil, time.Minute)
+        require.NoError(t, err)
+        ids = append(ids, session.ID)
+    }
+    other, err := cache.CreateSession(ctx, "alan", nil, time.Minute)
+    require.NoError(t, err)
+
+    sessions, err := cache.ListUserSessions(ctx, "grace")
+    require.NoError(t, err)
+    require.Len(t, sessions, 3)
+    for _, session := range sessions {
+        assert.Contains(t, ids, session.ID)
+    }
+
+    // El índice caduca con la última sesión
+    ttl, err := cache.client.PTTL(ctx, sessionIndexKey("grace")).Result()
+    require.NoError(t, err)
+    assert.True(t, ttl > 0 && ttl <= time.Minute, ttl)
+
+    // Las sesiones borradas desaparecen del listado
+    _, err = cache.DeleteSession(ctx, ids[0])
+    require.NoError(t, err)
+    sessions, err = cache.ListUserSessions(ctx, "grace")
+    require.NoError(t, err)
+    assert.Len(t, sessions, 2)
+
+    // Cerrar todas las sesiones del usuario
+    deleted, err := cache.DeleteUserSessions(ctx, "grace")
+    require.NoError(t, err)
+    assert.Equal(t, int64(2), deleted)
+
+    sessions, err = cache.ListUserSessions(ctx, "grace")
+    require.NoError(t, err)
+    assert.Empty(t, sessions)
+    for _, id := range ids {
+        session, err := cache.GetSession(ctx, id)
+        require.NoError(t, err)
+        assert.Nil(t, session)
+    }
+
+    // Las sesiones de otros usuarios no se tocan
+    session, err := cache.GetSession(ctx, other.ID)
+    require.NoError(t, err)
+    assert.NotNil(t, session)
+
+    deleted, err = cache.DeleteUserSessions(ctx, "nobody")
+    require.NoError(t, err)
+    assert.Zero(t, deleted)
+}
@@ -0,0 +1,27 @@
+package cache
+
+import (
+    "context"
+    "time"
+)
+
+// SetStore defines operations on Redis sets. Members are plain strings,
+// compared byte by byte.
+type SetStore interface {
+    // SAdd adds members and returns how many were new. A positive ttl sets
+    // the key expiration; zero keeps the current one.
+    SAdd(ctx context.Context, key string, members []string, ttl time.Duration) (int64, error)
+    // SRem removes members and returns how many existed
+    SRem(ctx context.Context, key string, members ...string) (int64, error)
+    // SIsMember reports whether member belongs to the set
+    SIsMember(ctx context.Context, key, member string) (bool, error)
+    // SScan returns a page of members matching match, starting at cursor.
+    // The next cursor is zero when the iteration is complete.
+    SScan(ctx context.Context, key string, cursor uint64, match string, count int64) (members []string, next uint64, err error)
+    // SCard returns the number of members
+    SCard(ctx context.Context, key string) (int64, error)
+    // SUnion returns the members of any of the sets
+    SUnion(ctx context.Context, keys ...string) ([]string, error)
+    // SInter returns the members common to every set
+    SInter(ctx context.Context, keys ...string) ([]string, error)
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRedisCache_SetMembership(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    added, err := cache.SAdd(ctx, "online", []string{"ana", "bob", "eve"}, time.Minute)
+    require.NoError(t, err)
+    assert.Equal(t, int64(3), added)
+
+    isMember, err := cache.SIsMember(ctx, "online", "bob")
+    require.NoError(t, err)
+    assert.True(t, isMember)
+
+    removed, err := cache.SRem(ctx, "online", "bob", "nobody")
+    require.NoError(t, err)
+    assert.Equal(t, int64(1), removed)
+
+    count, err := cache.SCard(ctx, "online")
+    require.NoError(t, err)
+    assert.Equal(t, int64(2), count)
+
+    // Recorrer todos los miembros con SSCAN
+    var members []string
+    var cursor uint64
+    for {
+        page, next, err := cache.SScan(ctx, "online", cursor, "", 1)
+        require.NoError(t, err)
+        members = append(members, page...)
+        if cursor = next; cursor == 0 {
+            break
+        }
+    }
+    assert.ElementsMatch(t, []string{"ana", "eve"}, members)
+
+    _, err = cache.SAdd(ctx, "admins", []string{"eve", "root"}, 0)
+    require.NoError(t, err)
+
+    union, err := cache.SUnion(ctx, "online", "admins")
+    require.NoError(t, err)
+    assert.ElementsMatch(t, []string{"ana", "eve", "root"}, union)
+
+    inter, err := cache.SInter(ctx, "online", "admins")
+    require.NoError(t, err)
+    assert.Equal(t, []string{"eve"}, inter)
+}
+
+func TestRedisCache_Leaderboard(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    added, err := cache.ZAdd(ctx, "leaderboard", []ScoredMember{
+        {Member: "ana", Score: 120},
+        {Member: "bob", Score: 90},
+        {Member: "eve", Score: 150},
+    }, time.Hour)
+    require.NoError(t, err)
+    assert.Equal(t, int64(3), added)
+
+    score, err := cache.ZIncrBy(ctx, "leaderboard", "bob", 100, 0)
+    require.NoError(t, err)
+    assert.Equal(t, float64(190), score)
+
+    // Los primeros puestos se cuentan desde la puntuación más alta
+    top, err := cache.ZRange(ctx, "leaderboard", 0, 1, true)
+    require.NoError(t, err)
+    assert.Equal(t, []ScoredMember{{Member: "bob", Score: 190}, {Member: "eve", Score: 150}}, top)
+
+    rank, score, found, err := cache.ZRank(ctx, "leaderboard", "ana", true)
+    require.NoError(t, err)
+    assert.True(t, found)
+    assert.Equal(t, int64(2), rank)
+    assert.Equal(t, float64(120), score)
+
+    _, _, found, err = cache.ZRank(ctx, "leaderboard", "nobody", false)
+    require.NoError(t, err)
+    assert.False(t, found)
+
+    inRange, err := cache.ZRangeByScore(ctx, "leaderboard", ScoreRange{Min: "100", Max: "(190"}, false)
+    require.NoError(t, err)
+    assert.Equal(t, []ScoredMember{{Member: "ana", Score: 120}, {Member: "eve", Score: 150}}, inRange)
+
+    page, err := cache.ZRangeByScore(ctx, "leaderboard", ScoreRange{Offset: 1, Count: 1}, true)
+    require.NoError(t, err)
+    assert.Equal(t, []ScoredMember{{Member: "eve", Score: 150}}, page)
+
+    removed, err := cache.ZRem(ctx, "leaderboard", "eve")
+    require.NoError(t, err)
+    assert.Equal(t, int64(1), removed)
+
+    count, err := cache.ZCard(ctx, "leaderboard")
+    require.NoError(t, err)
+    assert.Equal(t, int64(2), count)
+
+    ttl, err := cache.TTL(ctx, "leaderboard")
+    require.NoError(t, err)
+    assert.Greater(t, ttl, time.Duration(0))
+}
+
+func TestRedisCache_SetWriteBehind(t *testing.T) {
+    rc := setupWriteBehindCache(t, WriteBehindConfig{QueueSize: 100, BatchSize: 100, FlushInterval: time.Hour})
+    defer rc.Close()
+    ctx := context.Background()
+
+    // La escritura encolada llega a Redis antes que los miembros
+    require.NoError(t, rc.Set(ctx, "wb:set", "queued", time.Minute))
+    _, err := rc.SAdd(ctx, "wb:set", []string{"a"}, 0)
+    assert.ErrorIs(t, err, ErrWrongType)
+    require.NoError(t, rc.Set(ctx, "wb:zset", "queued", time.Minute))
+    _, err = rc.ZIncrBy(ctx, "wb:zset", "a", 1, 0)
+    assert.ErrorIs(t, err, ErrWrongType)
+
+    // Un borrado encolado no elimina después los conjuntos escritos
+    require.NoError(t, rc.DeleteMultiple(ctx, []string{"wb:set", "wb:zset"}))
+    _, err = rc.SAdd(ctx, "wb:set", []string{"a", "b"}, 0)
+    require.NoError(t, err)
+    _, err = rc.ZAdd(ctx, "wb:zset", []ScoredMember{{Member: "a", Score: 1}}, 0)
+    require.NoError(t, err)
+    require.NoError(t, rc.Flush(ctx))
+
+    card, err := rc.SCard(ctx, "wb:set")
+    require.NoError(t, err)
+    assert.Equal(t, int64(2), card)
+    card, err = rc.ZCard(ctx, "wb:zset")
+    require.NoError(t, err)
+    assert.Equal(t, int64(1), card)
+}
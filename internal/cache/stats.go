@@ -0,0 +1,263 @@
+package cache
+
+import (
+    "math"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    // OtherNamespace groups namespaces beyond StatsConfig.MaxNamespaces
+    OtherNamespace = "other"
+    // NoNamespace groups keys without a namespace prefix
+    NoNamespace = "none"
+
+    // rateTickInterval how often the rolling rates are updated
+    rateTickInterval = 5 * time.Second
+)
+
+// StatsConfig configuration of the per-namespace statistics
+type StatsConfig struct {
+    Prefixes      []string `mapstructure:"prefixes"`       // Key prefixes tracked in addition to namespaces
+    MaxNamespaces int      `mapstructure:"max_namespaces"` // Distinct namespaces tracked, the rest count as "other"
+}
+
+// KeyStats access statistics for a group of keys
+type KeyStats struct {
+    Hits         uint64               `json:"hits"`
+    Misses       uint64               `json:"misses"`
+    HitRatio     float64              `json:"hit_ratio"`
+    Sets         uint64               `json:"sets"`
+    Deletes      uint64               `json:"deletes"`
+    Evictions    uint64               `json:"evictions"` // Items found expired on read and removed
+    BytesWritten uint64               `json:"bytes_written"`
+    AvgValueSize float64              `json:"avg_value_size"`
+    Rates        map[string]RateStats `json:"rates"` // Per second, keyed by hits, misses, sets, deletes
+}
+
+// RateStats exponentially weighted moving averages, in events per second
+type RateStats struct {
+    M1  float64 `json:"1m"`
+    M5  float64 `json:"5m"`
+    M15 float64 `json:"15m"`
+}
+
+// StatsSnapshot point-in-time copy of the statistics
+type StatsSnapshot struct {
+    Since      time.Time           `json:"since"`
+    Namespaces map[string]KeyStats `json:"namespaces"`
+    Prefixes   map[string]KeyStats `json:"prefixes"`
+}
+
+// statsCollector aggregates key accesses per namespace and configured prefix.
+// It is registered as an observer of every RedisCache.
+type statsCollector struct {
+    NopObserver
+
+    mu            sync.Mutex
+    since         time.Time
+    prefixes      []string
+    maxNamespaces int
+    namespaces    map[string]*keyCounters
+    byPrefix      map[string]*keyCounters
+}
+
+func newStatsCollector(cfg StatsConfig) *statsCollector {
+    now := time.Now()
+    c := &statsCollector{
+        since:         now,
+        prefixes:      cfg.Prefixes,
+        maxNamespaces: cfg.MaxNamespaces,
+        namespaces:    make(map[string]*keyCounters),
+        byPrefix:      make(map[string]*keyCounters, len(cfg.Prefixes)),
+    }
+    for _, prefix := range cfg.Prefixes {
+        c.byPrefix[prefix] = newKeyCounters(now)
+    }
+    return c
+}
+
+// ObserveKeyAccess implements Observer
+func (c *statsCollector) ObserveKeyAccess(access KeyAccess) {
+    now := time.Now()
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    c.namespaceCounters(access.Namespace, now).record(access, now)
+    for _, prefix := range c.prefixes {
+        if strings.HasPrefix(access.Key, prefix) {
+            c.byPrefix[prefix].record(access, now)
+        }
+    }
+}
+
+// namespaceCounters returns the counters for ns, creating them if there is room;
+// callers hold c.mu
+func (c *statsCollector) namespaceCounters(ns string, now time.Time) *keyCounters {
+    if ns == "" {
+        ns = NoNamespace
+    }
+    if counters, ok := c.namespaces[ns]; ok {
+        return counters
+    }
+    if c.maxNamespaces > 0 && len(c.namespaces) >= c.maxNamespaces {
+        ns = OtherNamespace
+        if counters, ok := c.namespaces[ns]; ok {
+            return counters
+        }
+    }
+
+    counters := newKeyCounters(now)
+    c.namespaces[ns] = counters
+    return counters
+}
+
+// snapshot copies the current statistics
+func (c *statsCollector) snapshot() StatsSnapshot {
+    now := time.Now()
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    snapshot := StatsSnapshot{
+        Since:      c.since,
+        Namespaces: make(map[string]KeyStats, len(c.namespaces)),
+        Prefixes:   make(map[string]KeyStats, len(c.byPrefix)),
+    }
+    for ns, counters := range c.namespaces {
+        snapshot.Namespaces[ns] = counters.stats(now)
+    }
+    for prefix, counters := range c.byPrefix {
+        snapshot.Prefixes[prefix] = counters.stats(now)
+    }
+    return snapshot
+}
+
+// keyCounters raw counters behind KeyStats
+type keyCounters struct {
+    hits, misses, sets, deletes, evictions uint64
+    bytesWritten, sizedWrites              uint64
+
+    hitRate, missRate, setRate, deleteRate *ewma
+}
+
+func newKeyCounters(now time.Time) *keyCounters {
+    return &keyCounters{
+        hitRate:    newEWMA(now),
+        missRate:   newEWMA(now),
+        setRate:    newEWMA(now),
+        deleteRate: newEWMA(now),
+    }
+}
+
+func (k *keyCounters) record(access KeyAccess, now time.Time) {
+    switch access.Kind {
+    case AccessHit:
+        k.hits++
+        k.hitRate.mark(now)
+    case AccessMiss:
+        k.misses++
+        k.missRate.mark(now)
+    case AccessSet:
+        k.sets++
+        k.setRate.mark(now)
+        if access.Size > 0 {
+            k.bytesWritten += uint64(access.Size)
+            k.sizedWrites++
+        }
+    case AccessDelete:
+        k.deletes++
+        k.deleteRate.mark(now)
+    case AccessEvict:
+        k.evictions++
+    }
+}
+
+func (k *keyCounters) stats(now time.Time) KeyStats {
+    stats := KeyStats{
+        Hits:         k.hits,
+        Misses:       k.misses,
+        Sets:         k.sets,
+        Deletes:      k.deletes,
+        Evictions:    k.evictions,
+        BytesWritten: k.bytesWritten,
+        Rates: map[string]RateStats{
+            "hits":    k.hitRate.rates(now),
+            "misses":  k.missRate.rates(now),
+            "sets":    k.setRate.rates(now),
+            "deletes": k.deleteRate.rates(now),
+        },
+    }
+    if reads := k.hits + k.misses; reads > 0 {
+        stats.HitRatio = float64(k.hits) / float64(reads)
+    }
+    if k.sizedWrites > 0 {
+        stats.AvgValueSize = float64(k.bytesWritten) / float64(k.sizedWrites)
+    }
+    return stats
+}
+
+// Smoothing factors for 1, 5 and 15 minute averages ticked every 5 seconds,
+// the same scheme as the Unix load average
+var (
+    alpha1  = 1 - math.Exp(-rateTickInterval.Minutes()/1)
+    alpha5  = 1 - math.Exp(-rateTickInterval.Minutes()/5)
+    alpha15 = 1 - math.Exp(-rateTickInterval.Minutes()/15)
+)
+
+// maxCatchUpTicks bounds the work done after a long idle period; past it the
+// averages have decayed to zero anyway
+const maxCatchUpTicks = 720
+
+// ewma rolling event rates, ticked lazily when marked or read
+type ewma struct {
+    pending     uint64
+    m1, m5, m15 float64
+    initialized bool
+    lastTick    time.Time
+}
+
+func newEWMA(now time.Time) *ewma {
+    return &ewma{lastTick: now}
+}
+
+func (e *ewma) mark(now time.Time) {
+    e.tick(now)
+    e.pending++
+}
+
+func (e *ewma) rates(now time.Time) RateStats {
+    e.tick(now)
+    return RateStats{M1: e.m1, M5: e.m5, M15: e.m15}
+}
+
+// tick applies every interval elapsed since the last tick
+func (e *ewma) tick(now time.Time) {
+    ticks := int(now.Sub(e.lastTick) / rateTickInterval)
+    if ticks <= 0 {
+        return
+    }
+    e.lastTick = e.lastTick.Add(time.Duration(ticks) * rateTickInterval)
+
+    if ticks > maxCatchUpTicks {
+        e.m1, e.m5, e.m15 = 0, 0, 0
+        e.pending = 0
+        e.initialized = true
+        return
+    }
+
+    for i := 0; i < ticks; i++ {
+        instant := float64(e.pending) / rateTickInterval.Seconds()
+        e.pending = 0
+        if !e.initialized {
+            e.m1, e.m5, e.m15 = instant, instant, instant
+            e.initialized = true
+            continue
+        }
+        e.m1 += alpha1 * (instant - e.m1)
+        e.m5 += alpha5 * (instant - e.m5)
+        e.m15 += alpha15 * (instant - e.m15)
+    }
+}
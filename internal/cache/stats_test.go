@@ -0,0 +1,71 @@
+package cache
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestStatsCollector_Namespaces(t *testing.T) {
+    collector := newStatsCollector(StatsConfig{Prefixes: []string{"users:profile:"}, MaxNamespaces: 2})
+
+    record := func(key, kind string, size int) {
+        collector.ObserveKeyAccess(KeyAccess{Operation: "test", Key: key, Namespace: Namespace(key), Kind: kind, Size: size})
+    }
+    record("users:profile:1", AccessSet, 100)
+    record("users:profile:1", AccessHit, 100)
+    record("users:2", AccessSet, 300)
+    record("users:3", AccessMiss, 0)
+    record("users:2", AccessDelete, 0)
+    record("plain", AccessMiss, 0)
+    record("orders:1", AccessEvict, 0)
+    record("orders:1", AccessMiss, 0)
+
+    snapshot := collector.snapshot()
+
+    users := snapshot.Namespaces["users"]
+    assert.Equal(t, uint64(1), users.Hits)
+    assert.Equal(t, uint64(1), users.Misses)
+    assert.Equal(t, 0.5, users.HitRatio)
+    assert.Equal(t, uint64(2), users.Sets)
+    assert.Equal(t, uint64(1), users.Deletes)
+    assert.Equal(t, uint64(400), users.BytesWritten)
+    assert.Equal(t, 200.0, users.AvgValueSize)
+    assert.Contains(t, users.Rates, "hits")
+
+    assert.Equal(t, uint64(1), snapshot.Namespaces[NoNamespace].Misses)
+
+    // "orders" exceeds MaxNamespaces
+    require.Contains(t, snapshot.Namespaces, OtherNamespace)
+    assert.NotContains(t, snapshot.Namespaces, "orders")
+    assert.Equal(t, uint64(1), snapshot.Namespaces[OtherNamespace].Evictions)
+
+    profile := snapshot.Prefixes["users:profile:"]
+    assert.Equal(t, uint64(1), profile.Sets)
+    assert.Equal(t, uint64(1), profile.Hits)
+}
+
+func TestEWMA_Rates(t *testing.T) {
+    start := time.Now()
+    rate := newEWMA(start)
+
+    // 10 events per second during the first interval
+    for i := 0; i < 50; i++ {
+        rate.mark(start)
+    }
+    rates := rate.rates(start.Add(rateTickInterval))
+    assert.InDelta(t, 10, rates.M1, 0.001)
+    assert.InDelta(t, 10, rates.M15, 0.001)
+
+    // After a minute without traffic the 1m average decays faster than the 15m one
+    rates = rate.rates(start.Add(rateTickInterval + time.Minute))
+    assert.InDelta(t, 10*0.3679, rates.M1, 0.05)
+    assert.Greater(t, rates.M15, rates.M5)
+    assert.Greater(t, rates.M5, rates.M1)
+
+    // Long idle periods reset the averages
+    rates = rate.rates(start.Add(2 * time.Hour))
+    assert.Zero(t, rates.M15)
+}
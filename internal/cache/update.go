@@ -0,0 +1,37 @@
+package cache
+
+import (
+    "context"
+    "errors"
+
+    "distributed-cache/pkg/models"
+)
+
+// ErrUpdateConflict is returned when an update keeps losing the race against
+// concurrent writes to the same key
+var ErrUpdateConflict = errors.New("too many concurrent updates")
+
+// UpdateFunc computes the new value of an item from its current value. It may
+// run more than once, so it must not modify value nor have side effects.
+type UpdateFunc func(value interface{}) (interface{}, error)
+
+// RejectedUpdateError wraps the error returned by an UpdateFunc
+type RejectedUpdateError struct {
+    Err error
+}
+
+func (e *RejectedUpdateError) Error() string {
+    return "update rejected: " + e.Err.Error()
+}
+
+func (e *RejectedUpdateError) Unwrap() error {
+    return e.Err
+}
+
+// ItemUpdater defines read-modify-write operations on cache items
+type ItemUpdater interface {
+    // Update replaces the value stored at key by the result of fn atomically,
+    // keeping the item's creation time and remaining TTL. It returns nil if
+    // the key does not exist.
+    Update(ctx context.Context, key string, fn UpdateFunc) (*models.CacheItem, error)
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRedisCache_Update(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+
+    require.NoError(t, cache.Set(ctx, "profile", map[string]interface{}{"name": "Ada"}, time.Hour))
+    original, err := cache.Get(ctx, "profile")
+    require.NoError(t, err)
+
+    item, err := cache.Update(ctx, "profile", func(value interface{}) (interface{}, error) {
+        updated := map[string]interface{}{"visits": float64(1)}
+        for name, field := range value.(map[string]interface{}) {
+            updated[name] = field
+        }
+        return updated, nil
+    })
+    require.NoError(t, err)
+    require.NotNil(t, item)
+    assert.Equal(t, map[string]interface{}{"name": "Ada", "visits": float64(1)}, item.Value)
+
+    // Se conservan la fecha de creación y el TTL
+    stored, err := cache.Get(ctx, "profile")
+    require.NoError(t, err)
+    assert.Equal(t, item.Value, stored.Value)
+    assert.True(t, original.CreatedAt.Equal(stored.CreatedAt))
+    assert.True(t, original.ExpiresAt.Equal(stored.ExpiresAt))
+
+    ttl, err := cache.TTL(ctx, "profile")
+    require.NoError(t, err)
+    assert.Greater(t, ttl, 59*time.Minute)
+
+    // Una clave inexistente no se crea
+    item, err = cache.Update(ctx, "missing", func(value interface{}) (interface{}, error) {
+        return value, nil
+    })
+    require.NoError(t, err)
+    assert.Nil(t, item)
+
+    // Los errores de la función cancelan la escritura
+    errRejected := errors.New("rejected")
+    _, err = cache.Update(ctx, "profile", func(value interface{}) (interface{}, error) {
+        return nil, errRejected
+    })
+    assert.ErrorIs(t, err, errRejected)
+
+    stored, err = cache.Get(ctx, "profile")
+    require.NoError(t, err)
+    assert.Equal(t, map[string]interface{}{"name": "Ada", "visits": float64(1)}, stored.Value)
+}
+
+func TestRedisCache_UpdateConcurrent(t *testing.T) {
+    cache := setupTestCache(t).(*RedisCache)
+    defer cache.Close()
+
+    ctx := context.Background()
+    require.NoError(t, cache.Set(ctx, "counter", float64(0), time.Hour))
+
+    // Ninguna actualización confirmada se pierde
+    var wg sync.WaitGroup
+    var mu sync.Mutex
+    applied := 0
+    for i := 0; i < 5; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for j := 0; j < 10; j++ {
+                _, err := cache.Update(ctx, "counter", func(value interface{}) (interface{}, error) {
+                    return value.(float64) + 1, nil
+                })
+                if errors.Is(err, ErrUpdateConflict) || !assert.NoError(t, err) {
+                    continue
+                }
+                mu.Lock()
+                applied++
+                mu.Unlock()
+            }
+        }()
+    }
+    wg.Wait()
+
+    item, err := cache.Get(ctx, "counter")
+    require.NoError(t, err)
+    assert.Equal(t, float64(applied), item.Value)
+    assert.Greater(t, applied, 0)
+}
+
+func TestRedisCache_UpdateWriteBehind(t *testing.T) {
+    rc := setupWriteBehindCache(t, WriteBehindConfig{QueueSize: 100, BatchSize: 100, FlushInterval: time.Hour})
+    defer rc.Close()
+    ctx := context.Background()
+
+    // La escritura encolada se envía antes de leer el valor a modificar
+    require.NoError(t, rc.Set(ctx, "wb:update", "queued", time.Minute))
+    item, err := rc.Update(ctx, "wb:update", func(value interface{}) (interface{}, error) {
+        return value.(string) + " and updated", nil
+    })
+    require.NoError(t, err)
+    require.NotNil(t, item)
+    assert.Equal(t, "queued and updated", item.Value)
+}
@@ -0,0 +1,353 @@
+package cache
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+
+    "go.uber.org/zap"
+
+    "distributed-cache/pkg/models"
+)
+
+// WriteBehindConfig configuration of the write-behind queue. When enabled,
+// Set and SetMultiple return once the write is queued and a background
+// flusher sends the queue to Redis in pipelined batches.
+type WriteBehindConfig struct {
+    Enabled       bool          `mapstructure:"enabled"`
+    QueueSize     int           `mapstructure:"queue_size"`     // Distinct keys queued; beyond it writes go straight to Redis
+    BatchSize     int           `mapstructure:"batch_size"`     // Writes per pipeline
+    FlushInterval time.Duration `mapstructure:"flush_interval"` // Maximum time a write waits in the queue
+}
+
+// WriteBehindStatus state of the write-behind queue
+type WriteBehindStatus struct {
+    QueueDepth   int    `json:"queue_depth"`
+    Capacity     int    `json:"capacity"`
+    Flushed      uint64 `json:"flushed"`
+    Coalesced    uint64 `json:"coalesced"` // Writes replaced by a newer write to the same key before flushing
+    WriteThrough uint64 `json:"write_through"` // Writes sent directly because the queue was full
+    FlushErrors  uint64 `json:"flush_errors"`
+}
+
+// queuedWrite a SET waiting in the write-behind queue
+type queuedWrite struct {
+    key       string
+    data      []byte
+    ttl       time.Duration
+    expiresAt time.Time // Zero if the value does not expire
+}
+
+// writeQueue bounded queue of pending SETs, coalesced by key so only the
+// latest value of each key is flushed
+type writeQueue struct {
+    mu        sync.Mutex
+    size      int
+    batchSize int
+    pending   map[string]queuedWrite
+    flushing  map[string]queuedWrite // Taken by a flush that has not finished yet
+    order     []string               // Flush order; may hold keys already flushed or removed
+    coalesced uint64
+    ready     chan struct{} // Signalled when a full batch is queued
+}
+
+func newWriteQueue(cfg WriteBehindConfig) *writeQueue {
+    if cfg.QueueSize <= 0 {
+        cfg.QueueSize = 10000
+    }
+    if cfg.BatchSize <= 0 {
+        cfg.BatchSize = 500
+    }
+    return &writeQueue{
+        size:      cfg.QueueSize,
+        batchSize: cfg.BatchSize,
+        pending:   make(map[string]queuedWrite),
+        flushing:  make(map[string]queuedWrite),
+        ready:     make(chan struct{}, 1),
+    }
+}
+
+// push queues the writes that fit and returns the ones that did not
+func (q *writeQueue) push(writes ...queuedWrite) (rejected []queuedWrite) {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+
+    for _, w := range writes {
+        if _, ok := q.pending[w.key]; ok {
+            q.coalesced++
+        } else if len(q.pending) >= q.size {
+            rejected = append(rejected, w)
+            continue
+        } else {
+            q.order = append(q.order, w.key)
+        }
+        q.pending[w.key] = w
+    }
+
+    if len(q.pending) >= q.batchSize {
+        select {
+        case q.ready <- struct{}{}:
+        default:
+        }
+    }
+    return rejected
+}
+
+// get returns the queued write for key, including a write being flushed so
+// it stays readable until Redis has it
+func (q *writeQueue) get(key string) (queuedWrite, bool) {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+
+    if w, ok := q.pending[key]; ok {
+        return w, true
+    }
+    w, ok := q.flushing[key]
+    return w, ok
+}
+
+// take removes up to n writes in queue order, keeping them readable until
+// the flush is done
+func (q *writeQueue) take(n int) []queuedWrite {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+
+    batch := make([]queuedWrite, 0, n)
+    consumed := 0
+    for _, key := range q.order {
+        if len(batch) == n {
+            break
+        }
+        consumed++
+        if w, ok := q.pending[key]; ok {
+            batch = append(batch, w)
+            delete(q.pending, key)
+            q.flushing[key] = w
+        }
+    }
+    q.order = q.order[consumed:]
+    return batch
+}
+
+// takeKeys removes the queued writes of the given keys, keeping them
+// readable until the flush is done
+func (q *writeQueue) takeKeys(keys []string) []queuedWrite {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+
+    var batch []queuedWrite
+    for _, key := range keys {
+        if w, ok := q.pending[key]; ok {
+            batch = append(batch, w)
+            delete(q.pending, key)
+            q.flushing[key] = w
+        }
+    }
+    return batch
+}
+
+// flushed forgets the writes of a finished flush, after they reached Redis or
+// were requeued
+func (q *writeQueue) flushed(writes []queuedWrite) {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+
+    for _, w := range writes {
+        delete(q.flushing, w.key)
+    }
+}
+
+// requeue puts back writes that failed to flush unless a newer write replaced them
+func (q *writeQueue) requeue(writes []queuedWrite) {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+
+    keys := make([]string, 0, len(writes))
+    for _, w := range writes {
+        if _, ok := q.pending[w.key]; ok {
+            continue
+        }
+        q.pending[w.key] = w
+        keys = append(keys, w.key)
+    }
+    q.order = append(keys, q.order...)
+}
+
+// remove discards the queued writes of the given keys
+func (q *writeQueue) remove(keys ...string) {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+
+    for _, key := range keys {
+        delete(q.pending, key)
+        delete(q.flushing, key)
+    }
+}
+
+// clear discards every queued write
+func (q *writeQueue) clear() {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+
+    q.pending = make(map[string]queuedWrite)
+    q.flushing = make(map[string]queuedWrite)
+    q.order = nil
+}
+
+// depth returns the number of queued writes
+func (q *writeQueue) depth() int {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+    return len(q.pending)
+}
+
+// newQueuedWrite prepares a SET for the write-behind queue
+func newQueuedWrite(key string, data []byte, ttl time.Duration, now time.Time) queuedWrite {
+    return queuedWrite{key: key, data: data, ttl: ttl, expiresAt: expiresAt(ttl, now)}
+}
+
+// queuedRead serves key from the write-behind queue so callers read their own
+// writes before they are flushed. A nil item with found set means the queued
+// value already expired.
+func (rc *RedisCache) queuedRead(op *operation, key string) (_ *models.CacheItem, found bool) {
+    if rc.queue == nil {
+        return nil, false
+    }
+    w, ok := rc.queue.get(key)
+    if !ok {
+        return nil, false
+    }
+
+    var item models.CacheItem
+    if err := op.decode(w.data, &item); err != nil || item.IsExpired() {
+        op.access(key, AccessMiss, 0)
+        return nil, true
+    }
+    op.access(key, AccessHit, len(w.data))
+    return &item, true
+}
+
+// runFlusher sends queued writes to Redis every interval, or as soon as a
+// full batch is waiting, until the cache is closed
+func (rc *RedisCache) runFlusher(interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-rc.done:
+            return
+        case <-ticker.C:
+        case <-rc.queue.ready:
+        }
+        err := rc.flushQueue(context.Background())
+        switch {
+        case unavailable(err):
+            // Retried on the next tick; the breaker already reports the outage
+            rc.logger.Debug("write-behind flush postponed, Redis unavailable",
+                zap.Int("queue_depth", rc.queue.depth()),
+                zap.Error(err))
+        case err != nil:
+            rc.logger.Warn("failed to flush write-behind queue",
+                zap.Int("queue_depth", rc.queue.depth()),
+                zap.Error(err))
+        }
+    }
+}
+
+// Flush sends every write queued by the write-behind mode to Redis. It is a
+// no-op when write-behind is disabled.
+func (rc *RedisCache) Flush(ctx context.Context) error {
+    return rc.flushQueuedKeys(ctx)
+}
+
+// flushQueuedKeys flushes the queued writes of keys (all if none are given)
+// before commands that need them in Redis
+func (rc *RedisCache) flushQueuedKeys(ctx context.Context, keys ...string) error {
+    if rc.queue == nil {
+        return nil
+    }
+    return rc.flushQueue(ctx, keys...)
+}
+
+// flushQueue sends the queued writes of the given keys, or the whole queue if
+// none are given. Failed batches go back to the queue.
+func (rc *RedisCache) flushQueue(ctx context.Context, keys ...string) error {
+    rc.flushMu.Lock()
+    defer rc.flushMu.Unlock()
+
+    for {
+        var batch []queuedWrite
+        if len(keys) > 0 {
+            batch = rc.queue.takeKeys(keys)
+        } else {
+            batch = rc.queue.take(rc.queue.batchSize)
+        }
+        if len(batch) == 0 {
+            return nil
+        }
+
+        if err := rc.writeBatch(ctx, batch); err != nil {
+            rc.queue.requeue(batch)
+            rc.queue.flushed(batch)
+            rc.flushErrors.Add(1)
+            return err
+        }
+        rc.queue.flushed(batch)
+        if len(keys) > 0 {
+            return nil
+        }
+    }
+}
+
+// writeBatch pipelines a batch of queued writes
+func (rc *RedisCache) writeBatch(ctx context.Context, batch []queuedWrite) (err error) {
+    ctx, op := rc.startOperation(ctx, "write_behind_flush")
+    defer op.end(&err)
+    op.batch(len(batch))
+
+    now := time.Now()
+    pipe := rc.client.Pipeline()
+    for _, w := range batch {
+        ttl := w.ttl
+        if !w.expiresAt.IsZero() {
+            ttl = w.expiresAt.Sub(now)
+            if ttl <= 0 {
+                // Expired while queued; the write would have replaced any older value
+                pipe.Del(ctx, w.key)
+                continue
+            }
+        }
+        pipe.Set(ctx, w.key, w.data, ttl)
+    }
+
+    if _, err = pipe.Exec(ctx); err != nil {
+        return fmt.Errorf("failed to flush %d queued writes: %w", len(batch), err)
+    }
+
+    rc.flushed.Add(uint64(len(batch)))
+    if rc.local != nil {
+        for _, w := range batch {
+            rc.local.remember(w.key, w.data, w.expiresAt)
+        }
+    }
+    op.logger.Debug("write-behind batch flushed", zap.Int("count", len(batch)))
+    return nil
+}
+
+// writeBehindStatus returns the queue counters
+func (rc *RedisCache) writeBehindStatus() *WriteBehindStatus {
+    rc.queue.mu.Lock()
+    coalesced := rc.queue.coalesced
+    rc.queue.mu.Unlock()
+
+    return &WriteBehindStatus{
+        QueueDepth:   rc.queue.depth(),
+        Capacity:     rc.queue.size,
+        Flushed:      rc.flushed.Load(),
+        Coalesced:    coalesced,
+        WriteThrough: rc.writeThrough.Load(),
+        FlushErrors:  rc.flushErrors.Load(),
+    }
+}
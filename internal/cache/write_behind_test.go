@@ -0,0 +1,202 @@
+package cache
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap/zaptest"
+
+    "distributed-cache/pkg/models"
+)
+
+func TestWriteQueue_CoalesceAndReject(t *testing.T) {
+    q := newWriteQueue(WriteBehindConfig{QueueSize: 2, BatchSize: 10})
+    now := time.Now()
+
+    rejected := q.push(
+        newQueuedWrite("a", []byte("1"), 0, now),
+        newQueuedWrite("b", []byte("2"), 0, now),
+        newQueuedWrite("a", []byte("3"), 0, now), // Same key, replaces the queued value
+        newQueuedWrite("c", []byte("4"), 0, now),
+    )
+    require.Len(t, rejected, 1)
+    assert.Equal(t, "c", rejected[0].key)
+    assert.Equal(t, 2, q.depth())
+    assert.Equal(t, uint64(1), q.coalesced)
+
+    w, ok := q.get("a")
+    require.True(t, ok)
+    assert.Equal(t, "3", string(w.data))
+
+    // Writes are taken in the order their keys were first queued
+    batch := q.take(1)
+    require.Len(t, batch, 1)
+    assert.Equal(t, "a", batch[0].key)
+
+    // A failed batch goes back unless the key was written again meanwhile
+    q.push(newQueuedWrite("a", []byte("5"), 0, now))
+    q.requeue(batch)
+    w, _ = q.get("a")
+    assert.Equal(t, "5", string(w.data))
+
+    q.remove("a")
+    batch = q.take(10)
+    require.Len(t, batch, 1)
+    assert.Equal(t, "b", batch[0].key)
+    assert.Equal(t, 0, q.depth())
+}
+
+func TestWriteQueue_ReadableWhileFlushing(t *testing.T) {
+    q := newWriteQueue(WriteBehindConfig{QueueSize: 10, BatchSize: 10})
+    now := time.Now()
+
+    q.push(newQueuedWrite("a", []byte("1"), 0, now))
+    batch := q.take(10)
+    require.Len(t, batch, 1)
+    assert.Equal(t, 0, q.depth())
+
+    // Hasta que termina el flush la escritura se sigue leyendo de la cola
+    w, ok := q.get("a")
+    require.True(t, ok)
+    assert.Equal(t, "1", string(w.data))
+
+    // Una escritura nueva durante el flush tiene prioridad
+    q.push(newQueuedWrite("a", []byte("2"), 0, now))
+    w, _ = q.get("a")
+    assert.Equal(t, "2", string(w.data))
+
+    q.flushed(batch)
+    w, ok = q.get("a")
+    require.True(t, ok)
+    assert.Equal(t, "2", string(w.data))
+
+    q.take(10)
+    q.flushed([]queuedWrite{newQueuedWrite("a", nil, 0, now)})
+    _, ok = q.get("a")
+    assert.False(t, ok)
+}
+
+func setupWriteBehindCache(t *testing.T, cfg WriteBehindConfig) *RedisCache {
+    config := DefaultCacheConfig()
+    config.WriteBehind = cfg
+    config.WriteBehind.Enabled = true
+
+    rc, err := NewRedisCache(config, zaptest.NewLogger(t))
+    require.NoError(t, err)
+    require.NoError(t, rc.Clear(context.Background()))
+    return rc
+}
+
+func TestRedisCache_WriteBehindReadYourWrites(t *testing.T) {
+    rc := setupWriteBehindCache(t, WriteBehindConfig{QueueSize: 100, BatchSize: 100, FlushInterval: time.Hour})
+    defer rc.Close()
+    other := setupTestCache(t)
+    defer other.Close()
+    ctx := context.Background()
+
+    require.NoError(t, rc.Set(ctx, "wb:key", "queued", time.Minute))
+    require.NoError(t, rc.SetMultiple(ctx, map[string]*models.CacheItem{
+        "wb:multi": models.NewCacheItem("wb:multi", "queued", time.Minute),
+    }))
+
+    // Not in Redis yet, but visible through the cache that queued it
+    exists, err := other.Exists(ctx, "wb:key")
+    require.NoError(t, err)
+    assert.False(t, exists)
+
+    item, err := rc.Get(ctx, "wb:key")
+    require.NoError(t, err)
+    require.NotNil(t, item)
+    assert.Equal(t, "queued", item.Value)
+
+    items, err := rc.GetMultiple(ctx, []string{"wb:key", "wb:multi"})
+    require.NoError(t, err)
+    assert.Len(t, items, 2)
+
+    require.NoError(t, rc.Flush(ctx))
+    item, err = other.Get(ctx, "wb:multi")
+    require.NoError(t, err)
+    require.NotNil(t, item)
+    assert.Equal(t, "queued", item.Value)
+
+    status := rc.Status().WriteBehind
+    require.NotNil(t, status)
+    assert.Equal(t, 0, status.QueueDepth)
+    assert.Equal(t, uint64(2), status.Flushed)
+}
+
+func TestRedisCache_WriteBehindFlushTriggers(t *testing.T) {
+    ctx := context.Background()
+    other := setupTestCache(t)
+    defer other.Close()
+
+    // A full batch is flushed without waiting for the interval
+    rc := setupWriteBehindCache(t, WriteBehindConfig{QueueSize: 100, BatchSize: 2, FlushInterval: time.Hour})
+    require.NoError(t, rc.Set(ctx, "wb:batch:1", 1, 0))
+    require.NoError(t, rc.Set(ctx, "wb:batch:2", 2, 0))
+    require.Eventually(t, func() bool {
+        exists, _ := other.Exists(ctx, "wb:batch:2")
+        return exists
+    }, time.Second, 10*time.Millisecond)
+    rc.Close()
+
+    // A partial batch is flushed once the interval elapses
+    rc = setupWriteBehindCache(t, WriteBehindConfig{QueueSize: 100, BatchSize: 100, FlushInterval: 20 * time.Millisecond})
+    defer rc.Close()
+    require.NoError(t, rc.Set(ctx, "wb:interval", 1, 0))
+    require.Eventually(t, func() bool {
+        exists, _ := other.Exists(ctx, "wb:interval")
+        return exists
+    }, time.Second, 10*time.Millisecond)
+}
+
+func TestRedisCache_WriteBehindQueueFull(t *testing.T) {
+    rc := setupWriteBehindCache(t, WriteBehindConfig{QueueSize: 1, BatchSize: 100, FlushInterval: time.Hour})
+    defer rc.Close()
+    other := setupTestCache(t)
+    defer other.Close()
+    ctx := context.Background()
+
+    require.NoError(t, rc.Set(ctx, "wb:queued", 1, 0))
+    require.NoError(t, rc.Set(ctx, "wb:direct", 2, 0))
+
+    // The write that does not fit goes straight to Redis
+    exists, err := other.Exists(ctx, "wb:direct")
+    require.NoError(t, err)
+    assert.True(t, exists)
+    assert.Equal(t, uint64(1), rc.Status().WriteBehind.WriteThrough)
+}
+
+func TestRedisCache_WriteBehindDeleteAndTTL(t *testing.T) {
+    rc := setupWriteBehindCache(t, WriteBehindConfig{QueueSize: 100, BatchSize: 100, FlushInterval: time.Hour})
+    other := setupTestCache(t)
+    defer other.Close()
+    ctx := context.Background()
+
+    // Deleting a queued key discards the pending write
+    require.NoError(t, rc.Set(ctx, "wb:deleted", "value", 0))
+    require.NoError(t, rc.Delete(ctx, "wb:deleted"))
+    item, err := rc.Get(ctx, "wb:deleted")
+    require.NoError(t, err)
+    assert.Nil(t, item)
+
+    // TTL needs the key in Redis, so its queued write is flushed first
+    require.NoError(t, rc.Set(ctx, "wb:ttl", "value", time.Minute))
+    ttl, err := rc.TTL(ctx, "wb:ttl")
+    require.NoError(t, err)
+    assert.Greater(t, ttl, time.Duration(0))
+
+    // Close flushes whatever is still queued
+    require.NoError(t, rc.Set(ctx, "wb:closed", "value", 0))
+    require.NoError(t, rc.Close())
+
+    exists, err := other.Exists(ctx, "wb:closed")
+    require.NoError(t, err)
+    assert.True(t, exists)
+    exists, err = other.Exists(ctx, "wb:deleted")
+    require.NoError(t, err)
+    assert.False(t, exists)
+}
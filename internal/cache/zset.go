@@ -0,0 +1,42 @@
+package cache
+
+import (
+    "context"
+    "time"
+)
+
+// ScoredMember a sorted set member with its score
+type ScoredMember struct {
+    Member string  `json:"member"`
+    Score  float64 `json:"score"`
+}
+
+// ScoreRange bounds of a range by score. Min and Max use the Redis syntax:
+// "-inf", "+inf", and a "(" prefix for exclusive bounds; empty is unbounded.
+type ScoreRange struct {
+    Min    string
+    Max    string
+    Offset int64
+    Count  int64 // Zero returns every member in the range
+}
+
+// ZSetStore defines operations on Redis sorted sets, such as leaderboards.
+// Members are plain strings. Ranks start at zero; with reverse they are
+// counted from the highest score.
+type ZSetStore interface {
+    // ZAdd adds or updates members and returns how many were new. A positive
+    // ttl sets the key expiration; zero keeps the current one.
+    ZAdd(ctx context.Context, key string, members []ScoredMember, ttl time.Duration) (int64, error)
+    // ZIncrBy adds delta to the score of member and returns the new score
+    ZIncrBy(ctx context.Context, key, member string, delta float64, ttl time.Duration) (float64, error)
+    // ZRem removes members and returns how many existed
+    ZRem(ctx context.Context, key string, members ...string) (int64, error)
+    // ZRank returns the rank and score of member; found is false if it is missing
+    ZRank(ctx context.Context, key, member string, reverse bool) (rank int64, score float64, found bool, err error)
+    // ZRange returns the members between ranks start and stop, both inclusive
+    ZRange(ctx context.Context, key string, start, stop int64, reverse bool) ([]ScoredMember, error)
+    // ZRangeByScore returns the members whose score is inside the range
+    ZRangeByScore(ctx context.Context, key string, scores ScoreRange, reverse bool) ([]ScoredMember, error)
+    // ZCard returns the number of members
+    ZCard(ctx context.Context, key string) (int64, error)
+}
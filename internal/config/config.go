@@ -12,9 +12,21 @@ import (
 
 // Config estructura de configuración principal
 type Config struct {
-	Server ServerConfig      `mapstructure:"server"`
-	Cache  cache.CacheConfig `mapstructure:"cache"`
-	Logger LoggerConfig      `mapstructure:"logger"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Cache       cache.CacheConfig `mapstructure:"cache"`
+	Logger      LoggerConfig      `mapstructure:"logger"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Metrics     MetricsConfig     `mapstructure:"metrics"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	HotKeys     HotKeysConfig     `mapstructure:"hot_keys"`
+	Health      HealthConfig      `mapstructure:"health"`
+	Bloom       BloomConfig       `mapstructure:"bloom"`
+	HyperLogLog HyperLogLogConfig `mapstructure:"hll"`
+	List        ListConfig        `mapstructure:"list"`
+	Scheduler   SchedulerConfig   `mapstructure:"scheduler"`
+	PubSub      PubSubConfig      `mapstructure:"pubsub"`
 }
 
 // ServerConfig configuración del servidor HTTP
@@ -24,6 +36,105 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	TLS          TLSConfig     `mapstructure:"tls"`
+
+	// Proxies (IPs o CIDRs) cuya cabecera X-Forwarded-For se acepta para
+	// obtener la IP del cliente; vacío ignora la cabecera y usa la IP remota
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+	// Al recibir SIGTERM /readyz devuelve 503 durante ShutdownDelay antes de
+	// cerrar el servidor, para que los balanceadores dejen de enviar tráfico
+	ShutdownDelay   time.Duration `mapstructure:"shutdown_delay"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"` // Tiempo máximo para terminar las requests en curso
+}
+
+// TLSConfig configuración TLS del servidor HTTP
+type TLSConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	CertFile       string        `mapstructure:"cert_file"`
+	KeyFile        string        `mapstructure:"key_file"`
+	ClientCAFile   string        `mapstructure:"client_ca_file"`  // CA para verificar certificados de cliente (mTLS)
+	ClientAuth     string        `mapstructure:"client_auth"`     // none, request, require, verify_if_given, require_and_verify
+	ReloadInterval time.Duration `mapstructure:"reload_interval"` // Frecuencia de comprobación de cambios en los certificados
+}
+
+// MetricsConfig configuración del endpoint de métricas Prometheus
+type MetricsConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	Path          string `mapstructure:"path"`
+	MaxNamespaces int    `mapstructure:"max_namespaces"` // Máximo de namespaces distintos como etiqueta, el resto se agrupa en "other"
+}
+
+// TracingConfig configuración de trazas OpenTelemetry
+type TracingConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`
+	Exporter    string            `mapstructure:"exporter"` // otlp, stdout, file
+	Endpoint    string            `mapstructure:"endpoint"` // host:port del colector OTLP/HTTP
+	Insecure    bool              `mapstructure:"insecure"` // Usar HTTP sin TLS hacia el colector
+	Headers     map[string]string `mapstructure:"headers"`
+	FilePath    string            `mapstructure:"file_path"` // Destino del exportador file
+	ServiceName string            `mapstructure:"service_name"`
+	SampleRatio float64           `mapstructure:"sample_ratio"` // Fracción de trazas raíz muestreadas (0-1)
+}
+
+// HotKeysConfig configuración de la detección de hot keys y big keys
+type HotKeysConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	SampleRate      float64       `mapstructure:"sample_rate"`       // Fracción de accesos muestreados (0-1)
+	TopK            int           `mapstructure:"top_k"`             // Número de hot keys seguidas
+	SketchWidth     int           `mapstructure:"sketch_width"`      // Contadores por fila del count-min sketch
+	SketchDepth     int           `mapstructure:"sketch_depth"`      // Filas (funciones hash) del count-min sketch
+	DecayInterval   time.Duration `mapstructure:"decay_interval"`    // Cada cuánto se reducen los contadores a la mitad
+	HotKeyThreshold int64         `mapstructure:"hot_key_threshold"` // Accesos estimados por intervalo que generan un aviso (0 = sin avisos)
+	BigKeys         int           `mapstructure:"big_keys"`          // Número de big keys seguidas
+	BigKeyThreshold int           `mapstructure:"big_key_threshold"` // Tamaño en bytes que genera un aviso (0 = sin avisos)
+}
+
+// HealthConfig configuración de los probes de salud
+type HealthConfig struct {
+	CheckTimeout            time.Duration `mapstructure:"check_timeout"`             // Tiempo máximo de las comprobaciones de /readyz
+	PoolSaturationThreshold float64       `mapstructure:"pool_saturation_threshold"` // Fracción del pool en uso que se reporta como aviso
+}
+
+// BloomConfig valores por defecto y límites de los filtros de Bloom
+type BloomConfig struct {
+	DefaultCapacity  int64   `mapstructure:"default_capacity"`   // Elementos previstos de los filtros creados al añadir el primer elemento
+	DefaultErrorRate float64 `mapstructure:"default_error_rate"` // Probabilidad de falso positivo (0-1)
+	MaxBits          uint64  `mapstructure:"max_bits"`           // Tamaño máximo del bitmap de un filtro (0 = 2^32, el de un string de Redis)
+	MaxItems         int     `mapstructure:"max_items"`          // Elementos por petición (0 = sin límite)
+}
+
+// HyperLogLogConfig límites de los contadores HyperLogLog
+type HyperLogLogConfig struct {
+	MaxElements int `mapstructure:"max_elements"` // Elementos por petición (0 = sin límite)
+}
+
+// ListConfig límites de las operaciones sobre listas (/api/v1/list)
+type ListConfig struct {
+	MaxBlockingPops int `mapstructure:"max_blocking_pops"` // Pops bloqueantes simultáneos por instancia, cada uno con una conexión del pool; debe ser menor que cache.pool_size
+}
+
+// SchedulerConfig configuración de los trabajos programados (/api/v1/schedule)
+type SchedulerConfig struct {
+	PollInterval time.Duration     `mapstructure:"poll_interval"` // Cada cuánto buscan trabajos vencidos el long-poll, SSE y los webhooks
+	LeaseTimeout time.Duration     `mapstructure:"lease_timeout"` // Tiempo para confirmar un trabajo entregado antes de reenviarlo
+	WriteTimeout time.Duration     `mapstructure:"write_timeout"` // Tiempo máximo para enviar eventos a un consumidor SSE antes de desconectarlo
+	Webhooks     []ScheduleWebhook `mapstructure:"webhooks"`
+}
+
+// ScheduleWebhook entrega los trabajos de un tema con un POST a una URL local
+type ScheduleWebhook struct {
+	Topic      string        `mapstructure:"topic"`
+	URL        string        `mapstructure:"url"`
+	Timeout    time.Duration `mapstructure:"timeout"`     // Tiempo máximo de cada entrega
+	RetryDelay time.Duration `mapstructure:"retry_delay"` // Espera tras una entrega fallida, que se duplica en cada intento
+}
+
+// PubSubConfig configuración de la mensajería pub/sub (/api/v1/pubsub)
+type PubSubConfig struct {
+	BufferSize     int           `mapstructure:"buffer_size"`     // Mensajes pendientes por suscriptor antes de desconectarlo por lento
+	WriteTimeout   time.Duration `mapstructure:"write_timeout"`   // Tiempo máximo para enviar mensajes a un suscriptor antes de desconectarlo
+	MaxSubscribers int           `mapstructure:"max_subscribers"` // Suscripciones simultáneas por instancia, cada una con su conexión a Redis (0 = sin límite)
 }
 
 // LoggerConfig configuración del logger
@@ -33,6 +144,64 @@ type LoggerConfig struct {
 	OutputPath string `mapstructure:"output_path"`
 }
 
+// RateLimitConfig configuración del rate limiting
+type RateLimitConfig struct {
+	Enabled     bool             `mapstructure:"enabled"`
+	PerIP       cache.RateLimit  `mapstructure:"per_ip"`
+	PerAPIKey   cache.RateLimit  `mapstructure:"per_api_key"`
+	Routes      []RouteRateLimit `mapstructure:"routes"`
+	ExemptPaths []string         `mapstructure:"exempt_paths"`
+}
+
+// RouteRateLimit límite aplicado por cliente a un grupo de rutas
+type RouteRateLimit struct {
+	Prefix          string `mapstructure:"prefix"`
+	cache.RateLimit `mapstructure:",squash"`
+}
+
+// IdempotencyConfig configuración de las claves de idempotencia (cabecera Idempotency-Key)
+type IdempotencyConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	TTL         time.Duration `mapstructure:"ttl"`          // Tiempo que se guarda la respuesta para repetirla
+	LockTTL     time.Duration `mapstructure:"lock_ttl"`     // Duración máxima de la marca "en curso" de una petición
+	WaitTimeout time.Duration `mapstructure:"wait_timeout"` // Espera de los duplicados concurrentes antes del 409 (0 = sin espera)
+	Methods     []string      `mapstructure:"methods"`      // Métodos HTTP a los que se aplica
+}
+
+// AuthConfig configuración de autenticación de la API
+type AuthConfig struct {
+	Enabled     bool           `mapstructure:"enabled"`
+	APIKeys     []APIKeyConfig `mapstructure:"api_keys"`
+	APIKeysFile string         `mapstructure:"api_keys_file"`
+	JWT         JWTConfig      `mapstructure:"jwt"`
+}
+
+// JWTConfig configuración de autenticación con tokens JWT (Bearer)
+type JWTConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	JWKSFile        string        `mapstructure:"jwks_file"`        // JWKS local, para entornos sin acceso al emisor
+	JWKSURL         string        `mapstructure:"jwks_url"`         // JWKS remoto, por ejemplo el jwks_uri del proveedor OIDC
+	JWKSRefresh     time.Duration `mapstructure:"jwks_refresh"`     // Intervalo de recarga del JWKS
+	Issuer          string        `mapstructure:"issuer"`           // Claim "iss" esperado
+	Audience        string        `mapstructure:"audience"`         // Claim "aud" esperado
+	Algorithms      []string      `mapstructure:"algorithms"`       // RS256, ES256
+	Leeway          time.Duration `mapstructure:"leeway"`           // Tolerancia de reloj para exp/nbf/iat
+	SubjectClaim    string        `mapstructure:"subject_claim"`    // Claim con el nombre del principal
+	ScopesClaim     string        `mapstructure:"scopes_claim"`     // Claim con los scopes (string separado por espacios o lista)
+	ScopePrefix     string        `mapstructure:"scope_prefix"`     // Prefijo de los scopes del caché, por ejemplo "cache:"
+	NamespacesClaim string        `mapstructure:"namespaces_claim"` // Claim con los namespaces permitidos
+}
+
+// APIKeyConfig define una API key, almacenada como hash SHA-256, y sus permisos
+type APIKeyConfig struct {
+	Name        string          `mapstructure:"name"`
+	KeyHash     string          `mapstructure:"key_hash"`     // SHA-256 en hexadecimal, con o sin prefijo "sha256:"
+	Scopes      []string        `mapstructure:"scopes"`       // read, write, admin
+	Namespaces  []string        `mapstructure:"namespaces"`   // Namespaces permitidos ("users" permite "users:*")
+	KeyPrefixes []string        `mapstructure:"key_prefixes"` // Prefijos de clave permitidos
+	RateLimit   cache.RateLimit `mapstructure:"rate_limit"`   // Límite propio de la key, sustituye a rate_limit.per_api_key
+}
+
 // LoadConfig carga la configuración desde archivos de configuración y variables de entorno
 func LoadConfig() (*Config, error) {
 	// Configurar Viper
@@ -57,6 +226,45 @@ func LoadConfig() (*Config, error) {
 	viper.BindEnv("cache.read_timeout", "DC_CACHE_READ_TIMEOUT")
 	viper.BindEnv("cache.write_timeout", "DC_CACHE_WRITE_TIMEOUT")
 	viper.BindEnv("cache.pool_timeout", "DC_CACHE_POOL_TIMEOUT")
+	viper.BindEnv("cache.tls.enabled", "DC_CACHE_TLS_ENABLED")
+	viper.BindEnv("cache.tls.ca_file", "DC_CACHE_TLS_CA_FILE")
+	viper.BindEnv("cache.tls.cert_file", "DC_CACHE_TLS_CERT_FILE")
+	viper.BindEnv("cache.tls.key_file", "DC_CACHE_TLS_KEY_FILE")
+	viper.BindEnv("cache.tls.server_name", "DC_CACHE_TLS_SERVER_NAME")
+	viper.BindEnv("cache.circuit_breaker.enabled", "DC_CACHE_CIRCUIT_BREAKER_ENABLED")
+	viper.BindEnv("cache.circuit_breaker.miss_on_open", "DC_CACHE_CIRCUIT_BREAKER_MISS_ON_OPEN")
+	viper.BindEnv("cache.circuit_breaker.write_policy", "DC_CACHE_CIRCUIT_BREAKER_WRITE_POLICY")
+	viper.BindEnv("cache.fallback.enabled", "DC_CACHE_FALLBACK_ENABLED")
+	viper.BindEnv("cache.write_behind.enabled", "DC_CACHE_WRITE_BEHIND_ENABLED")
+	viper.BindEnv("cache.start_degraded", "DC_CACHE_START_DEGRADED")
+	viper.BindEnv("server.tls.enabled", "DC_SERVER_TLS_ENABLED")
+	viper.BindEnv("server.tls.cert_file", "DC_SERVER_TLS_CERT_FILE")
+	viper.BindEnv("server.tls.key_file", "DC_SERVER_TLS_KEY_FILE")
+	viper.BindEnv("server.tls.client_ca_file", "DC_SERVER_TLS_CLIENT_CA_FILE")
+	viper.BindEnv("server.tls.client_auth", "DC_SERVER_TLS_CLIENT_AUTH")
+	viper.BindEnv("server.shutdown_delay", "DC_SERVER_SHUTDOWN_DELAY")
+	viper.BindEnv("server.shutdown_timeout", "DC_SERVER_SHUTDOWN_TIMEOUT")
+	viper.BindEnv("rate_limit.enabled", "DC_RATE_LIMIT_ENABLED")
+	viper.BindEnv("idempotency.enabled", "DC_IDEMPOTENCY_ENABLED")
+	viper.BindEnv("auth.enabled", "DC_AUTH_ENABLED")
+	viper.BindEnv("auth.api_keys_file", "DC_AUTH_API_KEYS_FILE")
+	viper.BindEnv("auth.jwt.enabled", "DC_AUTH_JWT_ENABLED")
+	viper.BindEnv("auth.jwt.jwks_file", "DC_AUTH_JWT_JWKS_FILE")
+	viper.BindEnv("auth.jwt.jwks_url", "DC_AUTH_JWT_JWKS_URL")
+	viper.BindEnv("auth.jwt.issuer", "DC_AUTH_JWT_ISSUER")
+	viper.BindEnv("auth.jwt.audience", "DC_AUTH_JWT_AUDIENCE")
+	viper.BindEnv("metrics.enabled", "DC_METRICS_ENABLED")
+	viper.BindEnv("metrics.path", "DC_METRICS_PATH")
+	viper.BindEnv("tracing.enabled", "DC_TRACING_ENABLED")
+	viper.BindEnv("tracing.exporter", "DC_TRACING_EXPORTER")
+	viper.BindEnv("tracing.endpoint", "DC_TRACING_ENDPOINT")
+	viper.BindEnv("tracing.insecure", "DC_TRACING_INSECURE")
+	viper.BindEnv("tracing.file_path", "DC_TRACING_FILE_PATH")
+	viper.BindEnv("tracing.sample_ratio", "DC_TRACING_SAMPLE_RATIO")
+	viper.BindEnv("hot_keys.enabled", "DC_HOT_KEYS_ENABLED")
+	viper.BindEnv("hot_keys.sample_rate", "DC_HOT_KEYS_SAMPLE_RATE")
+	viper.BindEnv("hot_keys.hot_key_threshold", "DC_HOT_KEYS_HOT_KEY_THRESHOLD")
+	viper.BindEnv("hot_keys.big_key_threshold", "DC_HOT_KEYS_BIG_KEY_THRESHOLD")
 
 	// Configuración por defecto
 	setDefaults()
@@ -96,6 +304,12 @@ func setDefaults() {
 	viper.SetDefault("server.read_timeout", "30s")
 	viper.SetDefault("server.write_timeout", "30s")
 	viper.SetDefault("server.idle_timeout", "120s")
+	viper.SetDefault("server.shutdown_delay", "5s")
+	viper.SetDefault("server.shutdown_timeout", "30s")
+	viper.SetDefault("server.trusted_proxies", []string{})
+	viper.SetDefault("server.tls.enabled", false)
+	viper.SetDefault("server.tls.client_auth", "")
+	viper.SetDefault("server.tls.reload_interval", "10s")
 
 	// Cache defaults - usar localhost para desarrollo local, redis para contenedores
 	viper.SetDefault("cache.addresses", []string{"localhost:6379"})
@@ -108,6 +322,106 @@ func setDefaults() {
 	viper.SetDefault("cache.read_timeout", "3s")
 	viper.SetDefault("cache.write_timeout", "3s")
 	viper.SetDefault("cache.pool_timeout", "4s")
+	viper.SetDefault("cache.tls.enabled", false)
+	viper.SetDefault("cache.tls.insecure_skip_verify", false)
+	viper.SetDefault("cache.tls.reload_interval", "10s")
+	viper.SetDefault("cache.stats.prefixes", []string{})
+	viper.SetDefault("cache.stats.max_namespaces", 100)
+	viper.SetDefault("cache.circuit_breaker.enabled", true)
+	viper.SetDefault("cache.circuit_breaker.failure_threshold", 5)
+	viper.SetDefault("cache.circuit_breaker.open_timeout", "10s")
+	viper.SetDefault("cache.circuit_breaker.miss_on_open", false)
+	viper.SetDefault("cache.circuit_breaker.write_policy", cache.WritePolicyFail)
+	viper.SetDefault("cache.circuit_breaker.buffer_size", 1000)
+	viper.SetDefault("cache.fallback.enabled", false)
+	viper.SetDefault("cache.fallback.max_items", 10000)
+	viper.SetDefault("cache.fallback.max_value_size", 64*1024)
+	viper.SetDefault("cache.fallback.reconcile_interval", "5s")
+	viper.SetDefault("cache.write_behind.enabled", false)
+	viper.SetDefault("cache.write_behind.queue_size", 10000)
+	viper.SetDefault("cache.write_behind.batch_size", 500)
+	viper.SetDefault("cache.write_behind.flush_interval", "50ms")
+	viper.SetDefault("cache.start_degraded", false)
+
+	// Rate limit defaults - deshabilitado salvo configuración explícita
+	viper.SetDefault("rate_limit.enabled", false)
+	viper.SetDefault("rate_limit.per_ip.algorithm", cache.AlgorithmTokenBucket)
+	viper.SetDefault("rate_limit.per_ip.limit", 100)
+	viper.SetDefault("rate_limit.per_ip.window", "1s")
+	viper.SetDefault("rate_limit.per_ip.burst", 200)
+	viper.SetDefault("rate_limit.per_api_key.algorithm", cache.AlgorithmSlidingWindow)
+	viper.SetDefault("rate_limit.per_api_key.limit", 0)
+	viper.SetDefault("rate_limit.per_api_key.window", "1m")
+	viper.SetDefault("rate_limit.exempt_paths", []string{"/health", "/ping"})
+
+	// Idempotency defaults
+	viper.SetDefault("idempotency.enabled", false)
+	viper.SetDefault("idempotency.ttl", "24h")
+	viper.SetDefault("idempotency.lock_ttl", "30s")
+	viper.SetDefault("idempotency.wait_timeout", "5s")
+	viper.SetDefault("idempotency.methods", []string{"POST", "PUT", "PATCH", "DELETE"})
+
+	// Bloom filter defaults
+	viper.SetDefault("bloom.default_capacity", 10000)
+	viper.SetDefault("bloom.default_error_rate", 0.01)
+	viper.SetDefault("bloom.max_bits", 1<<27)
+	viper.SetDefault("bloom.max_items", 1000)
+
+	// HyperLogLog defaults
+	viper.SetDefault("hll.max_elements", 1000)
+
+	// List defaults
+	viper.SetDefault("list.max_blocking_pops", 5)
+
+	// Scheduler defaults
+	viper.SetDefault("scheduler.poll_interval", "500ms")
+	viper.SetDefault("scheduler.lease_timeout", "30s")
+	viper.SetDefault("scheduler.write_timeout", "10s")
+
+	// Pub/sub defaults
+	viper.SetDefault("pubsub.buffer_size", 100)
+	viper.SetDefault("pubsub.write_timeout", "10s")
+	viper.SetDefault("pubsub.max_subscribers", 1000)
+
+	// Auth defaults
+	viper.SetDefault("auth.enabled", false)
+	viper.SetDefault("auth.api_keys_file", "")
+	viper.SetDefault("auth.jwt.enabled", false)
+	viper.SetDefault("auth.jwt.jwks_refresh", "1h")
+	viper.SetDefault("auth.jwt.algorithms", []string{"RS256", "ES256"})
+	viper.SetDefault("auth.jwt.leeway", "30s")
+	viper.SetDefault("auth.jwt.subject_claim", "sub")
+	viper.SetDefault("auth.jwt.scopes_claim", "scope")
+	viper.SetDefault("auth.jwt.scope_prefix", "")
+	viper.SetDefault("auth.jwt.namespaces_claim", "cache_namespaces")
+
+	// Metrics defaults
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("metrics.path", "/metrics")
+	viper.SetDefault("metrics.max_namespaces", 100)
+
+	// Tracing defaults
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.exporter", "otlp")
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.service_name", "distributed-cache")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+
+	// Hot keys defaults
+	viper.SetDefault("hot_keys.enabled", true)
+	viper.SetDefault("hot_keys.sample_rate", 0.1)
+	viper.SetDefault("hot_keys.top_k", 50)
+	viper.SetDefault("hot_keys.sketch_width", 2048)
+	viper.SetDefault("hot_keys.sketch_depth", 4)
+	viper.SetDefault("hot_keys.decay_interval", "1m")
+	viper.SetDefault("hot_keys.hot_key_threshold", 0)
+	viper.SetDefault("hot_keys.big_keys", 50)
+	viper.SetDefault("hot_keys.big_key_threshold", 1048576)
+
+	// Health defaults
+	viper.SetDefault("health.check_timeout", "2s")
+	viper.SetDefault("health.pool_saturation_threshold", 0.9)
 
 	// Logger defaults
 	viper.SetDefault("logger.level", "info")
@@ -115,6 +429,23 @@ func setDefaults() {
 	viper.SetDefault("logger.output_path", "stdout")
 }
 
+// LoadAPIKeys carga las API keys desde un archivo local (YAML o JSON) con una lista "api_keys"
+func LoadAPIKeys(path string) ([]APIKeyConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading API keys file: %w", err)
+	}
+
+	var keys []APIKeyConfig
+	if err := v.UnmarshalKey("api_keys", &keys); err != nil {
+		return nil, fmt.Errorf("error unmarshalling API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
 // GetAddress devuelve la dirección completa del servidor
 func (sc *ServerConfig) GetAddress() string {
 	return fmt.Sprintf("%s:%d", sc.Host, sc.Port)
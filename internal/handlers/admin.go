@@ -0,0 +1,71 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/hotkeys"
+)
+
+// AdminHandler exposes operational diagnostics
+type AdminHandler struct {
+    tracker *hotkeys.Tracker
+    logger  *zap.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(tracker *hotkeys.Tracker, logger *zap.Logger) *AdminHandler {
+    return &AdminHandler{
+        tracker: tracker,
+        logger:  logger,
+    }
+}
+
+// HotKeys handles GET /admin/hotkeys
+func (h *AdminHandler) HotKeys(c *gin.Context) {
+    limit, ok := parseLimit(c)
+    if !ok {
+        return
+    }
+
+    keys := h.tracker.HotKeys(limit)
+    cfg := h.tracker.Config()
+    c.JSON(http.StatusOK, gin.H{
+        "keys":        keys,
+        "count":       len(keys),
+        "sample_rate": cfg.SampleRate,
+        "interval":    cfg.DecayInterval.String(),
+    })
+}
+
+// BigKeys handles GET /admin/bigkeys
+func (h *AdminHandler) BigKeys(c *gin.Context) {
+    limit, ok := parseLimit(c)
+    if !ok {
+        return
+    }
+
+    keys := h.tracker.BigKeys(limit)
+    c.JSON(http.StatusOK, gin.H{
+        "keys":  keys,
+        "count": len(keys),
+    })
+}
+
+// parseLimit reads the optional ?limit= query parameter, writing a 400 on error
+func parseLimit(c *gin.Context) (int, bool) {
+    raw := c.Query("limit")
+    if raw == "" {
+        return 0, true
+    }
+
+    limit, err := strconv.Atoi(raw)
+    if err != nil || limit < 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a non-negative integer"})
+        return 0, false
+    }
+    return limit, true
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+    "github.com/gin-gonic/gin"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/middleware"
+)
+
+// authorizeKeys checks that the principal may access every key, writing a
+// 403 response otherwise. Requests without a principal are only kept away
+// from internal subsystem keys.
+func authorizeKeys(c *gin.Context, keys ...string) bool {
+    principal, ok := middleware.PrincipalFromContext(c)
+    for _, key := range keys {
+        if (ok && !principal.CanAccessKey(key)) || cache.IsInternalKey(key) {
+            middleware.AbortForbidden(c, "access to key is not allowed: "+key)
+            return false
+        }
+    }
+    return true
+}
+
+// authorizePattern checks that the principal may list keys matching pattern
+func authorizePattern(c *gin.Context, pattern string) bool {
+    principal, ok := middleware.PrincipalFromContext(c)
+    if !ok || principal.CanAccessPattern(pattern) {
+        return true
+    }
+
+    middleware.AbortForbidden(c, "access to pattern is not allowed: "+pattern)
+    return false
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+    "distributed-cache/pkg/models"
+)
+
+// BloomHandler handles HTTP operations on Bloom filters
+type BloomHandler struct {
+    filters cache.BloomStore
+    config  config.BloomConfig
+    logger  *zap.Logger
+}
+
+// NewBloomHandler creates a new Bloom filter handler
+func NewBloomHandler(filters cache.BloomStore, cfg config.BloomConfig, logger *zap.Logger) *BloomHandler {
+    return &BloomHandler{
+        filters: filters,
+        config:  cfg,
+        logger:  logger,
+    }
+}
+
+// itemsRequest is the body of the endpoints that add or check items
+type itemsRequest struct {
+    Items []string `json:"items"`
+    TTL   string   `json:"ttl,omitempty"` // Expiration of a filter created by the add
+}
+
+// Reserve handles PUT /bloom/:key
+func (h *BloomHandler) Reserve(c *gin.Context) {
+    key := c.Param("key")
+
+    var request struct {
+        Capacity  int64   `json:"capacity"`
+        ErrorRate float64 `json:"error_rate"`
+        TTL       string  `json:"ttl,omitempty"` // Empty means no expiration
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    filter, err := h.reserve(c.Request.Context(), key, request.Capacity, request.ErrorRate, ttl)
+    if err != nil {
+        switch {
+        case errors.Is(err, cache.ErrInvalidBloom):
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        case errors.Is(err, cache.ErrBloomExists):
+            c.JSON(http.StatusConflict, gin.H{"error": "bloom filter already exists"})
+            return
+        }
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to reserve bloom filter", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to reserve bloom filter"})
+        return
+    }
+
+    c.JSON(http.StatusCreated, filter)
+}
+
+// Info handles GET /bloom/:key
+func (h *BloomHandler) Info(c *gin.Context) {
+    key := c.Param("key")
+
+    filter, err := h.filters.BloomInfo(c.Request.Context(), key)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to get bloom filter", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get bloom filter"})
+        return
+    }
+    if filter == nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "bloom filter not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, filter)
+}
+
+// Delete handles DELETE /bloom/:key
+func (h *BloomHandler) Delete(c *gin.Context) {
+    key := c.Param("key")
+
+    deleted, err := h.filters.BloomDelete(c.Request.Context(), key)
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to delete bloom filter", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to delete bloom filter"})
+        return
+    }
+    if !deleted {
+        c.JSON(http.StatusNotFound, gin.H{"error": "bloom filter not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "bloom filter deleted successfully"})
+}
+
+// AddItems handles POST /bloom/:key/items. A missing filter is created with
+// the configured default capacity and error rate.
+func (h *BloomHandler) AddItems(c *gin.Context) {
+    key := c.Param("key")
+
+    request, ok := h.bindItems(c)
+    if !ok {
+        return
+    }
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    ctx := c.Request.Context()
+    added, err := h.filters.BloomAdd(ctx, key, request.Items)
+    if errors.Is(err, cache.ErrBloomNotFound) {
+        added, err = h.reserveAndAdd(ctx, key, request.Items, ttl)
+    }
+    if err != nil {
+        if errors.Is(err, cache.ErrInvalidBloom) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to add bloom filter items", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to add bloom filter items"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "items": request.Items,
+        "added": added,
+    })
+}
+
+// reserve creates a filter unless it would be larger than the configured maximum
+func (h *BloomHandler) reserve(ctx context.Context, key string, capacity int64, errorRate float64, ttl time.Duration) (*models.BloomFilter, error) {
+    bits, _, err := cache.BloomSize(capacity, errorRate)
+    if err != nil {
+        return nil, err
+    }
+    if h.config.MaxBits > 0 && bits > h.config.MaxBits {
+        return nil, fmt.Errorf("%w: filter needs %d bits, more than the maximum of %d", cache.ErrInvalidBloom, bits, h.config.MaxBits)
+    }
+    return h.filters.BloomReserve(ctx, key, capacity, errorRate, ttl)
+}
+
+// reserveAndAdd creates the filter with the default size and adds the items.
+// A filter created concurrently by another request is used as is.
+func (h *BloomHandler) reserveAndAdd(ctx context.Context, key string, items []string, ttl time.Duration) ([]bool, error) {
+    _, err := h.reserve(ctx, key, h.config.DefaultCapacity, h.config.DefaultErrorRate, ttl)
+    if err != nil && !errors.Is(err, cache.ErrBloomExists) {
+        return nil, err
+    }
+    return h.filters.BloomAdd(ctx, key, items)
+}
+
+// CheckItems handles POST /bloom/:key/items/exists
+func (h *BloomHandler) CheckItems(c *gin.Context) {
+    request, ok := h.bindItems(c)
+    if !ok {
+        return
+    }
+
+    found, ok := h.exists(c, request.Items)
+    if !ok {
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":    c.Param("key"),
+        "items":  request.Items,
+        "exists": found,
+    })
+}
+
+// CheckItem handles GET /bloom/:key/items/:item
+func (h *BloomHandler) CheckItem(c *gin.Context) {
+    item := c.Param("item")
+
+    found, ok := h.exists(c, []string{item})
+    if !ok {
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":    c.Param("key"),
+        "item":   item,
+        "exists": found[0],
+    })
+}
+
+// exists checks the items, writing the error response on failure. Items
+// are never reported present in a missing filter.
+func (h *BloomHandler) exists(c *gin.Context, items []string) ([]bool, bool) {
+    key := c.Param("key")
+
+    found, err := h.filters.BloomExists(c.Request.Context(), key, items)
+    if errors.Is(err, cache.ErrBloomNotFound) {
+        return make([]bool, len(items)), true
+    }
+    if err != nil {
+        if dataTypeError(c, err) {
+            return nil, false
+        }
+        requestLogger(c, h.logger).Error("failed to check bloom filter items", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to check bloom filter items"})
+        return nil, false
+    }
+    return found, true
+}
+
+func (h *BloomHandler) bindItems(c *gin.Context) (*itemsRequest, bool) {
+    var request itemsRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return nil, false
+    }
+    if len(request.Items) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "items are required"})
+        return nil, false
+    }
+    if h.config.MaxItems > 0 && len(request.Items) > h.config.MaxItems {
+        c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many items, the maximum is %d", h.config.MaxItems)})
+        return nil, false
+    }
+    return &request, true
+}
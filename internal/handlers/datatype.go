@@ -0,0 +1,36 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+
+    "distributed-cache/internal/cache"
+)
+
+// dataTypeError responds 409 when err is caused by the value stored at the
+// key rather than by the backend, and reports whether it did
+func dataTypeError(c *gin.Context, err error) bool {
+    if errors.Is(err, cache.ErrWrongType) || errors.Is(err, cache.ErrNotInteger) {
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+        return true
+    }
+    return false
+}
+
+// parseKeyTTL parses the optional TTL of the data type endpoints; empty
+// keeps the current expiration of the key
+func parseKeyTTL(c *gin.Context, value string) (time.Duration, bool) {
+    if value == "" {
+        return 0, true
+    }
+
+    ttl, err := time.ParseDuration(value)
+    if err != nil || ttl <= 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid TTL format"})
+        return 0, false
+    }
+    return ttl, true
+}
@@ -1,13 +1,16 @@
 package handlers
 
 import (
+    "errors"
     "net/http"
+    "strings"
     "time"
 
     "github.com/gin-gonic/gin"
     "go.uber.org/zap"
 
     "distributed-cache/internal/cache"
+    "distributed-cache/internal/logging"
     "distributed-cache/pkg/models"
 )
 
@@ -25,44 +28,105 @@ func NewCacheHandler(cache cache.Cache, logger *zap.Logger) *CacheHandler {
     }
 }
 
-// SetItem handles PUT /cache/:key
-func (h *CacheHandler) SetItem(c *gin.Context) {
-    key := c.Param("key")
-    if key == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "key is required"})
-        return
+// requestLogger returns the request-scoped logger set by the Logger middleware
+func requestLogger(c *gin.Context, fallback *zap.Logger) *zap.Logger {
+    return logging.FromContext(c.Request.Context(), fallback)
+}
+
+// errorStatus maps a cache error to an HTTP status: 503 while the circuit
+// breaker rejects requests, 500 otherwise
+func errorStatus(err error) int {
+    if errors.Is(err, cache.ErrCircuitOpen) {
+        return http.StatusServiceUnavailable
     }
+    return http.StatusInternalServerError
+}
 
-    var request struct {
-        Value interface{} `json:"value"`
+// setItemRequest body of PUT /cache/:key and POST /cache/:key/getset
+type setItemRequest struct {
+    Value interface{} `json:"value"`
     TTL   string      `json:"ttl,omitempty"` // Duration in format "1h", "30m", "60s"
-    }
+}
 
+// bindSetItem parses the body of a write; the TTL defaults to 1 hour
+func (h *CacheHandler) bindSetItem(c *gin.Context) (value interface{}, ttl time.Duration, ok bool) {
+    var request setItemRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        h.logger.Warn("invalid request body", zap.Error(err))
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
         c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
-        return
+        return nil, 0, false
     }
 
-    // Parse TTL (default 1 hour)
-    ttl := 1 * time.Hour
+    ttl = 1 * time.Hour
     if request.TTL != "" {
         parsedTTL, err := time.ParseDuration(request.TTL)
         if err != nil {
             c.JSON(http.StatusBadRequest, gin.H{"error": "invalid TTL format"})
-            return
+            return nil, 0, false
         }
         ttl = parsedTTL
     }
+    return request.Value, ttl, true
+}
+
+// itemResponse JSON representation of a cache item
+func itemResponse(item *models.CacheItem) gin.H {
+    return gin.H{
+        "key":           item.Key,
+        "value":         item.Value,
+        "created_at":    item.CreatedAt,
+        "expires_at":    item.ExpiresAt,
+        "remaining_ttl": item.RemainingTTL().String(),
+    }
+}
+
+// SetItem handles PUT /cache/:key. With ?mode=nx the item is only stored if
+// the key is missing (409 otherwise) and with ?mode=xx only if it exists
+// (404 otherwise).
+func (h *CacheHandler) SetItem(c *gin.Context) {
+    key := c.Param("key")
+    if key == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "key is required"})
+        return
+    }
+
+    mode := c.Query("mode")
+    if mode != "" && mode != "nx" && mode != "xx" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be nx or xx"})
+        return
+    }
 
-    err := h.cache.Set(c.Request.Context(), key, request.Value, ttl)
+    value, ttl, ok := h.bindSetItem(c)
+    if !ok {
+        return
+    }
+
+    var err error
+    stored := true
+    switch mode {
+    case "nx":
+        stored, err = h.cache.SetNX(c.Request.Context(), key, value, ttl)
+    case "xx":
+        stored, err = h.cache.SetXX(c.Request.Context(), key, value, ttl)
+    default:
+        err = h.cache.Set(c.Request.Context(), key, value, ttl)
+    }
     if err != nil {
-        h.logger.Error("failed to set cache item", zap.Error(err), zap.String("key", key))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set cache item"})
+        requestLogger(c, h.logger).Error("failed to set cache item", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to set cache item"})
+        return
+    }
+
+    switch {
+    case !stored && mode == "nx":
+        c.JSON(http.StatusConflict, gin.H{"error": "key already exists"})
+        return
+    case !stored:
+        c.JSON(http.StatusNotFound, gin.H{"error": "key not found"})
         return
     }
 
-    h.logger.Debug("cache item set via API", zap.String("key", key), zap.Duration("ttl", ttl))
+    requestLogger(c, h.logger).Debug("cache item set via API", zap.String("key", key), zap.Duration("ttl", ttl))
     c.JSON(http.StatusOK, gin.H{"message": "item stored successfully"})
 }
 
@@ -76,8 +140,8 @@ func (h *CacheHandler) GetItem(c *gin.Context) {
 
     item, err := h.cache.Get(c.Request.Context(), key)
     if err != nil {
-        h.logger.Error("failed to get cache item", zap.Error(err), zap.String("key", key))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cache item"})
+        requestLogger(c, h.logger).Error("failed to get cache item", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get cache item"})
         return
     }
 
@@ -86,17 +150,58 @@ func (h *CacheHandler) GetItem(c *gin.Context) {
         return
     }
 
-    response := gin.H{
-        "key":         item.Key,
-        "value":       item.Value,
-        "created_at":  item.CreatedAt,
-        "expires_at":  item.ExpiresAt,
-        "remaining_ttl": item.RemainingTTL().String(),
+    c.JSON(http.StatusOK, itemResponse(item))
+}
+
+// GetSetItem maneja POST /cache/:key/getset: guarda el valor y devuelve el anterior
+func (h *CacheHandler) GetSetItem(c *gin.Context) {
+    key := c.Param("key")
+
+    value, ttl, ok := h.bindSetItem(c)
+    if !ok {
+        return
     }
 
+    previous, err := h.cache.GetSet(c.Request.Context(), key, value, ttl)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to get and set cache item", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get and set cache item"})
+        return
+    }
+
+    response := gin.H{"key": key, "previous": nil}
+    if previous != nil {
+        response["previous"] = itemResponse(previous)
+    }
     c.JSON(http.StatusOK, response)
 }
 
+// GetDelItem maneja POST /cache/:key/getdel: elimina el elemento y lo devuelve
+func (h *CacheHandler) GetDelItem(c *gin.Context) {
+    key := c.Param("key")
+
+    item, err := h.cache.GetDel(c.Request.Context(), key)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to get and delete cache item", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get and delete cache item"})
+        return
+    }
+
+    if item == nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "key not found"})
+        return
+    }
+
+    requestLogger(c, h.logger).Debug("cache item deleted via API", zap.String("key", key))
+    c.JSON(http.StatusOK, itemResponse(item))
+}
+
 // DeleteItem maneja DELETE /cache/:key
 func (h *CacheHandler) DeleteItem(c *gin.Context) {
     key := c.Param("key")
@@ -107,12 +212,12 @@ func (h *CacheHandler) DeleteItem(c *gin.Context) {
 
     err := h.cache.Delete(c.Request.Context(), key)
     if err != nil {
-        h.logger.Error("failed to delete cache item", zap.Error(err), zap.String("key", key))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete cache item"})
+        requestLogger(c, h.logger).Error("failed to delete cache item", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to delete cache item"})
         return
     }
 
-    h.logger.Debug("cache item deleted via API", zap.String("key", key))
+    requestLogger(c, h.logger).Debug("cache item deleted via API", zap.String("key", key))
     c.JSON(http.StatusOK, gin.H{"message": "item deleted successfully"})
 }
 
@@ -126,8 +231,8 @@ func (h *CacheHandler) ExistsItem(c *gin.Context) {
 
     exists, err := h.cache.Exists(c.Request.Context(), key)
     if err != nil {
-        h.logger.Error("failed to check cache item existence", zap.Error(err), zap.String("key", key))
-        c.Status(http.StatusInternalServerError)
+        requestLogger(c, h.logger).Error("failed to check cache item existence", zap.Error(err), zap.String("key", key))
+        c.Status(errorStatus(err))
         return
     }
 
@@ -148,11 +253,19 @@ func (h *CacheHandler) SetMultiple(c *gin.Context) {
     }
 
     if err := c.ShouldBindJSON(&request); err != nil {
-        h.logger.Warn("invalid request body", zap.Error(err))
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
         c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
         return
     }
 
+    keys := make([]string, 0, len(request.Items))
+    for key := range request.Items {
+        keys = append(keys, key)
+    }
+    if !authorizeKeys(c, keys...) {
+        return
+    }
+
     items := make(map[string]*models.CacheItem)
     for key, item := range request.Items {
         ttl := 1 * time.Hour
@@ -171,12 +284,12 @@ func (h *CacheHandler) SetMultiple(c *gin.Context) {
 
     err := h.cache.SetMultiple(c.Request.Context(), items)
     if err != nil {
-        h.logger.Error("failed to set multiple cache items", zap.Error(err))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set multiple items"})
+        requestLogger(c, h.logger).Error("failed to set multiple cache items", zap.Error(err))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to set multiple items"})
         return
     }
 
-    h.logger.Debug("multiple cache items set via API", zap.Int("count", len(items)))
+    requestLogger(c, h.logger).Debug("multiple cache items set via API", zap.Int("count", len(items)))
     c.JSON(http.StatusOK, gin.H{
         "message": "items stored successfully",
         "count":   len(items),
@@ -190,15 +303,19 @@ func (h *CacheHandler) GetMultiple(c *gin.Context) {
     }
 
     if err := c.ShouldBindJSON(&request); err != nil {
-        h.logger.Warn("invalid request body", zap.Error(err))
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
         c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
         return
     }
 
+    if !authorizeKeys(c, request.Keys...) {
+        return
+    }
+
     items, err := h.cache.GetMultiple(c.Request.Context(), request.Keys)
     if err != nil {
-        h.logger.Error("failed to get multiple cache items", zap.Error(err))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get multiple items"})
+        requestLogger(c, h.logger).Error("failed to get multiple cache items", zap.Error(err))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get multiple items"})
         return
     }
 
@@ -225,19 +342,23 @@ func (h *CacheHandler) DeleteMultiple(c *gin.Context) {
     }
 
     if err := c.ShouldBindJSON(&request); err != nil {
-        h.logger.Warn("invalid request body", zap.Error(err))
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
         c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
         return
     }
 
+    if !authorizeKeys(c, request.Keys...) {
+        return
+    }
+
     err := h.cache.DeleteMultiple(c.Request.Context(), request.Keys)
     if err != nil {
-        h.logger.Error("failed to delete multiple cache items", zap.Error(err))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete multiple items"})
+        requestLogger(c, h.logger).Error("failed to delete multiple cache items", zap.Error(err))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to delete multiple items"})
         return
     }
 
-    h.logger.Debug("multiple cache items deleted via API", zap.Int("count", len(request.Keys)))
+    requestLogger(c, h.logger).Debug("multiple cache items deleted via API", zap.Int("count", len(request.Keys)))
     c.JSON(http.StatusOK, gin.H{
         "message": "items deleted successfully",
         "count":   len(request.Keys),
@@ -248,12 +369,12 @@ func (h *CacheHandler) DeleteMultiple(c *gin.Context) {
 func (h *CacheHandler) Clear(c *gin.Context) {
     err := h.cache.Clear(c.Request.Context())
     if err != nil {
-        h.logger.Error("failed to clear cache", zap.Error(err))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear cache"})
+        requestLogger(c, h.logger).Error("failed to clear cache", zap.Error(err))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to clear cache"})
         return
     }
 
-    h.logger.Info("cache cleared via API")
+    requestLogger(c, h.logger).Info("cache cleared via API")
     c.JSON(http.StatusOK, gin.H{"message": "cache cleared successfully"})
 }
 
@@ -270,7 +391,7 @@ func (h *CacheHandler) SetExpiration(c *gin.Context) {
     }
 
     if err := c.ShouldBindJSON(&request); err != nil {
-        h.logger.Warn("invalid request body", zap.Error(err))
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
         c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
         return
     }
@@ -283,12 +404,12 @@ func (h *CacheHandler) SetExpiration(c *gin.Context) {
 
     err = h.cache.Expire(c.Request.Context(), key, ttl)
     if err != nil {
-        h.logger.Error("failed to set expiration", zap.Error(err), zap.String("key", key))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set expiration"})
+        requestLogger(c, h.logger).Error("failed to set expiration", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to set expiration"})
         return
     }
 
-    h.logger.Debug("expiration set via API", zap.String("key", key), zap.Duration("ttl", ttl))
+    requestLogger(c, h.logger).Debug("expiration set via API", zap.String("key", key), zap.Duration("ttl", ttl))
     c.JSON(http.StatusOK, gin.H{"message": "expiration set successfully"})
 }
 
@@ -302,8 +423,8 @@ func (h *CacheHandler) GetTTL(c *gin.Context) {
 
     ttl, err := h.cache.TTL(c.Request.Context(), key)
     if err != nil {
-        h.logger.Error("failed to get TTL", zap.Error(err), zap.String("key", key))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get TTL"})
+        requestLogger(c, h.logger).Error("failed to get TTL", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get TTL"})
         return
     }
 
@@ -316,11 +437,14 @@ func (h *CacheHandler) GetTTL(c *gin.Context) {
 // GetKeys maneja GET /cache/keys
 func (h *CacheHandler) GetKeys(c *gin.Context) {
     pattern := c.DefaultQuery("pattern", "*")
+    if !authorizePattern(c, pattern) {
+        return
+    }
 
     keys, err := h.cache.Keys(c.Request.Context(), pattern)
     if err != nil {
-        h.logger.Error("failed to get keys", zap.Error(err))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get keys"})
+        requestLogger(c, h.logger).Error("failed to get keys", zap.Error(err))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get keys"})
         return
     }
 
@@ -335,20 +459,44 @@ func (h *CacheHandler) GetKeys(c *gin.Context) {
 func (h *CacheHandler) GetStats(c *gin.Context) {
     size, err := h.cache.Size(c.Request.Context())
     if err != nil {
-        h.logger.Error("failed to get cache size", zap.Error(err))
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cache stats"})
+        requestLogger(c, h.logger).Error("failed to get cache size", zap.Error(err))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get cache stats"})
         return
     }
 
-    info, err := h.cache.Info(c.Request.Context())
+    // ?section=memory or ?section=memory,stats limits the INFO sections returned
+    var sections []string
+    if section := c.Query("section"); section != "" {
+        sections = strings.Split(strings.ToLower(section), ",")
+    }
+
+    info, err := h.cache.Info(c.Request.Context(), sections...)
     if err != nil {
-        h.logger.Warn("failed to get cache info", zap.Error(err))
-        info = make(map[string]interface{})
+        requestLogger(c, h.logger).Warn("failed to get cache info", zap.Error(err))
+        info = cache.ParseInfo("")
+    } else if len(sections) > 0 && len(info.Sections) == 0 && len(info.Keyspace) == 0 && !containsString(sections, cache.KeyspaceSection) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "unknown INFO section"})
+        return
     }
 
+    access := h.cache.Stats()
+    status := h.cache.Status()
     stats := gin.H{
-        "size": size,
-        "info": info,
+        "size":       size,
+        "info":       info,
+        "pool":       status.Pool,
+        "since":      access.Since,
+        "namespaces": access.Namespaces,
+        "prefixes":   access.Prefixes,
+    }
+    if status.Breaker != nil {
+        stats["circuit_breaker"] = status.Breaker
+    }
+    if status.Fallback != nil {
+        stats["fallback"] = status.Fallback
+    }
+    if status.WriteBehind != nil {
+        stats["write_behind"] = status.WriteBehind
     }
 
     c.JSON(http.StatusOK, stats)
@@ -358,7 +506,7 @@ func (h *CacheHandler) GetStats(c *gin.Context) {
 func (h *CacheHandler) Health(c *gin.Context) {
     err := h.cache.Ping(c.Request.Context())
     if err != nil {
-        h.logger.Error("health check failed", zap.Error(err))
+        requestLogger(c, h.logger).Error("health check failed", zap.Error(err))
         c.JSON(http.StatusServiceUnavailable, gin.H{
             "status": "unhealthy",
             "error":  err.Error(),
@@ -371,3 +519,13 @@ func (h *CacheHandler) Health(c *gin.Context) {
         "timestamp": time.Now(),
     })
 }
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+    for _, v := range values {
+        if v == s {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+    "net/http"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+)
+
+// HashHandler handles HTTP operations on hash fields
+type HashHandler struct {
+    hashes cache.HashStore
+    logger *zap.Logger
+}
+
+// NewHashHandler creates a new hash handler
+func NewHashHandler(hashes cache.HashStore, logger *zap.Logger) *HashHandler {
+    return &HashHandler{
+        hashes: hashes,
+        logger: logger,
+    }
+}
+
+// GetHash handles GET /hash/:key, limited to ?fields=a,b if given
+func (h *HashHandler) GetHash(c *gin.Context) {
+    key := c.Param("key")
+
+    var (
+        fields map[string]interface{}
+        err    error
+    )
+    if list := c.Query("fields"); list != "" {
+        fields, err = h.hashes.HMGet(c.Request.Context(), key, strings.Split(list, ",")...)
+    } else {
+        fields, err = h.hashes.HGetAll(c.Request.Context(), key)
+    }
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to get hash", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get hash"})
+        return
+    }
+
+    if len(fields) == 0 && c.Query("fields") == "" {
+        c.JSON(http.StatusNotFound, gin.H{"error": "key not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":    key,
+        "fields": fields,
+    })
+}
+
+// CountFields handles GET /hash/:key/len
+func (h *HashHandler) CountFields(c *gin.Context) {
+    key := c.Param("key")
+
+    count, err := h.hashes.HLen(c.Request.Context(), key)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to count hash fields", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to count hash fields"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "count": count,
+    })
+}
+
+// SetFields handles PUT /hash/:key/fields
+func (h *HashHandler) SetFields(c *gin.Context) {
+    key := c.Param("key")
+
+    var request struct {
+        Fields map[string]interface{} `json:"fields"`
+        TTL    string                 `json:"ttl,omitempty"` // Duration in format "1h"; empty keeps the current TTL
+    }
+
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if len(request.Fields) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "fields are required"})
+        return
+    }
+
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    added, err := h.hashes.HSet(c.Request.Context(), key, request.Fields, ttl)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to set hash fields", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to set hash fields"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "added": added,
+    })
+}
+
+// GetField handles GET /hash/:key/fields/:field
+func (h *HashHandler) GetField(c *gin.Context) {
+    key, field := c.Param("key"), c.Param("field")
+
+    value, found, err := h.hashes.HGet(c.Request.Context(), key, field)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to get hash field", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get hash field"})
+        return
+    }
+
+    if !found {
+        c.JSON(http.StatusNotFound, gin.H{"error": "field not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "field": field,
+        "value": value,
+    })
+}
+
+// SetField handles PUT /hash/:key/fields/:field
+func (h *HashHandler) SetField(c *gin.Context) {
+    key, field := c.Param("key"), c.Param("field")
+
+    var request struct {
+        Value interface{} `json:"value"`
+        TTL   string      `json:"ttl,omitempty"`
+    }
+
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    added, err := h.hashes.HSet(c.Request.Context(), key, map[string]interface{}{field: request.Value}, ttl)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to set hash field", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to set hash field"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":     key,
+        "field":   field,
+        "created": added == 1,
+    })
+}
+
+// DeleteField handles DELETE /hash/:key/fields/:field
+func (h *HashHandler) DeleteField(c *gin.Context) {
+    key, field := c.Param("key"), c.Param("field")
+
+    removed, err := h.hashes.HDel(c.Request.Context(), key, field)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to delete hash field", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to delete hash field"})
+        return
+    }
+
+    if removed == 0 {
+        c.JSON(http.StatusNotFound, gin.H{"error": "field not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "field deleted successfully"})
+}
+
+// IncrementField handles POST /hash/:key/fields/:field/incr
+func (h *HashHandler) IncrementField(c *gin.Context) {
+    key, field := c.Param("key"), c.Param("field")
+
+    var request struct {
+        By  *int64 `json:"by,omitempty"` // Defaults to 1
+        TTL string `json:"ttl,omitempty"`
+    }
+    if c.Request.ContentLength != 0 {
+        if err := c.ShouldBindJSON(&request); err != nil {
+            requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+            return
+        }
+    }
+
+    delta := int64(1)
+    if request.By != nil {
+        delta = *request.By
+    }
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    value, err := h.hashes.HIncrBy(c.Request.Context(), key, field, delta, ttl)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to increment hash field", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to increment hash field"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "field": field,
+        "value": value,
+    })
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "sync/atomic"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+)
+
+// Check results reported by /readyz
+const (
+    CheckOK   = "ok"
+    CheckWarn = "warn" // Reported but does not fail readiness
+    CheckFail = "fail"
+)
+
+// Check result of a single readiness check
+type Check struct {
+    Status  string                 `json:"status"`
+    Message string                 `json:"message,omitempty"`
+    Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthHandler serves the liveness and readiness probes
+type HealthHandler struct {
+    cache    cache.Cache
+    config   config.HealthConfig
+    logger   *zap.Logger
+    draining atomic.Bool
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(cache cache.Cache, cfg config.HealthConfig, logger *zap.Logger) *HealthHandler {
+    return &HealthHandler{
+        cache:  cache,
+        config: cfg,
+        logger: logger,
+    }
+}
+
+// SetDraining marks the server as shutting down so /readyz starts failing
+func (h *HealthHandler) SetDraining(draining bool) {
+    h.draining.Store(draining)
+}
+
+// Livez handles GET /livez. It only reports that the process is serving
+// requests; dependencies are checked by /readyz.
+func (h *HealthHandler) Livez(c *gin.Context) {
+    c.JSON(http.StatusOK, gin.H{
+        "status":    "ok",
+        "timestamp": time.Now(),
+    })
+}
+
+// Readyz handles GET /readyz
+func (h *HealthHandler) Readyz(c *gin.Context) {
+    draining := h.draining.Load()
+    checks := make(map[string]Check)
+
+    if draining {
+        checks["draining"] = Check{Status: CheckFail, Message: "server is shutting down"}
+    } else {
+        ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.CheckTimeout)
+        defer cancel()
+
+        checks["redis"] = h.checkRedis(ctx)
+        // Read the status after the ping, which may have been the breaker probe
+        status := h.cache.Status()
+        checks["pool"] = h.checkPool(status.Pool)
+        if status.Breaker != nil {
+            checks["circuit_breaker"] = h.checkBreaker(*status.Breaker)
+        }
+        if status.Fallback != nil {
+            checks["fallback"] = h.checkFallback(*status.Fallback)
+        }
+        if status.WriteBehind != nil {
+            checks["write_behind"] = h.checkWriteBehind(*status.WriteBehind)
+        }
+        if checks["redis"].Status == CheckOK {
+            h.checkServer(ctx, checks)
+        }
+    }
+
+    ready := true
+    for _, check := range checks {
+        if check.Status == CheckFail {
+            ready = false
+        }
+    }
+
+    status, code := "ready", http.StatusOK
+    if !ready {
+        status, code = "not_ready", http.StatusServiceUnavailable
+        requestLogger(c, h.logger).Debug("readiness check failed", zap.Any("checks", checks))
+    }
+
+    c.JSON(code, gin.H{
+        "status":    status,
+        "draining":  draining,
+        "checks":    checks,
+        "timestamp": time.Now(),
+    })
+}
+
+// checkRedis pings Redis and reports the round trip latency
+func (h *HealthHandler) checkRedis(ctx context.Context) Check {
+    start := time.Now()
+    if err := h.cache.Ping(ctx); err != nil {
+        if errors.Is(err, cache.ErrCircuitOpen) {
+            return Check{Status: CheckFail, Message: "redis unreachable (circuit breaker open)"}
+        }
+        return Check{Status: CheckFail, Message: "redis unreachable"}
+    }
+    return Check{
+        Status:  CheckOK,
+        Details: map[string]interface{}{"latency_ms": float64(time.Since(start).Microseconds()) / 1000},
+    }
+}
+
+// checkPool reports connection pool usage; saturation is a warning only, as
+// failing readiness under load would shed traffic onto the other instances
+func (h *HealthHandler) checkPool(pool cache.PoolStats) Check {
+    inUse := int(pool.TotalConns) - int(pool.IdleConns)
+    saturation := 0.0
+    if pool.PoolSize > 0 {
+        saturation = float64(inUse) / float64(pool.PoolSize)
+    }
+
+    check := Check{
+        Status: CheckOK,
+        Details: map[string]interface{}{
+            "in_use":     inUse,
+            "idle":       pool.IdleConns,
+            "size":       pool.PoolSize,
+            "saturation": saturation,
+            "timeouts":   pool.Timeouts,
+        },
+    }
+    if h.config.PoolSaturationThreshold > 0 && saturation >= h.config.PoolSaturationThreshold {
+        check.Status = CheckWarn
+        check.Message = "connection pool saturated"
+    }
+    return check
+}
+
+// checkBreaker reports the circuit breaker state. An open circuit fails
+// readiness; half-open means a probe is under way.
+func (h *HealthHandler) checkBreaker(breaker cache.BreakerStatus) Check {
+    check := Check{
+        Status: CheckOK,
+        Details: map[string]interface{}{
+            "state":           breaker.State,
+            "failures":        breaker.Failures,
+            "since":           breaker.Since,
+            "buffered_writes": breaker.BufferedWrites,
+        },
+    }
+    switch breaker.State {
+    case cache.BreakerOpen:
+        check.Status = CheckFail
+        check.Message = "circuit breaker open"
+    case cache.BreakerHalfOpen:
+        check.Status = CheckWarn
+        check.Message = "circuit breaker half-open"
+    }
+    return check
+}
+
+// checkFallback reports writes held locally until Redis is reachable again
+func (h *HealthHandler) checkFallback(fallback cache.FallbackStatus) Check {
+    check := Check{
+        Status: CheckOK,
+        Details: map[string]interface{}{
+            "items":          fallback.Items,
+            "pending_writes": fallback.PendingWrites,
+            "conflicts":      fallback.Conflicts,
+        },
+    }
+    if fallback.PendingWrites > 0 {
+        check.Status = CheckWarn
+        check.Message = "writes pending reconciliation with redis"
+    }
+    return check
+}
+
+// checkWriteBehind reports a full write-behind queue, which sends writes
+// straight to Redis
+func (h *HealthHandler) checkWriteBehind(wb cache.WriteBehindStatus) Check {
+    check := Check{
+        Status: CheckOK,
+        Details: map[string]interface{}{
+            "queue_depth":  wb.QueueDepth,
+            "capacity":     wb.Capacity,
+            "flush_errors": wb.FlushErrors,
+        },
+    }
+    if wb.QueueDepth >= wb.Capacity {
+        check.Status = CheckWarn
+        check.Message = "write-behind queue full"
+    }
+    return check
+}
+
+// checkServer inspects INFO for the replication role and persistence state
+func (h *HealthHandler) checkServer(ctx context.Context, checks map[string]Check) {
+    info, err := h.cache.Info(ctx)
+    if err != nil {
+        // Managed Redis services may disable INFO; reachability was already checked
+        checks["replication"] = Check{Status: CheckWarn, Message: "INFO unavailable"}
+        return
+    }
+
+    replication := Check{
+        Status:  CheckOK,
+        Details: map[string]interface{}{"role": info.String("replication", "role")},
+    }
+    if info.String("replication", "role") == "slave" {
+        link := info.String("replication", "master_link_status")
+        replication.Details["master_link_status"] = link
+        if link != "up" {
+            replication.Status = CheckWarn
+            replication.Message = "replica is not connected to its master"
+        }
+    }
+    checks["replication"] = replication
+
+    loading, _ := info.Int("persistence", "loading")
+    rdbInProgress, _ := info.Int("persistence", "rdb_bgsave_in_progress")
+    aofInProgress, _ := info.Int("persistence", "aof_rewrite_in_progress")
+    rdbStatus := info.String("persistence", "rdb_last_bgsave_status")
+    aofStatus := info.String("persistence", "aof_last_write_status")
+
+    persistence := Check{
+        Status: CheckOK,
+        Details: map[string]interface{}{
+            "loading":                 loading == 1,
+            "rdb_bgsave_in_progress":  rdbInProgress == 1,
+            "aof_rewrite_in_progress": aofInProgress == 1,
+            "rdb_last_bgsave_status":  rdbStatus,
+            "aof_last_write_status":   aofStatus,
+        },
+    }
+    switch {
+    case loading == 1:
+        // Redis rejects commands with LOADING until the dataset is in memory
+        persistence.Status = CheckFail
+        persistence.Message = "redis is loading the dataset"
+    case (rdbStatus != "" && rdbStatus != "ok") || (aofStatus != "" && aofStatus != "ok"):
+        // With stop-writes-on-bgsave-error Redis refuses writes after a failed save
+        persistence.Status = CheckWarn
+        persistence.Message = "last persistence operation failed"
+    }
+    checks["persistence"] = persistence
+}
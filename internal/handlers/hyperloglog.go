@@ -0,0 +1,134 @@
+package handlers
+
+import (
+    "fmt"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+)
+
+// HyperLogLogHandler handles HTTP operations on HyperLogLog counters
+type HyperLogLogHandler struct {
+    counters cache.HyperLogLogStore
+    config   config.HyperLogLogConfig
+    logger   *zap.Logger
+}
+
+// NewHyperLogLogHandler creates a new HyperLogLog handler
+func NewHyperLogLogHandler(counters cache.HyperLogLogStore, cfg config.HyperLogLogConfig, logger *zap.Logger) *HyperLogLogHandler {
+    return &HyperLogLogHandler{
+        counters: counters,
+        config:   cfg,
+        logger:   logger,
+    }
+}
+
+// Add handles POST /hll/:key
+func (h *HyperLogLogHandler) Add(c *gin.Context) {
+    key := c.Param("key")
+
+    var request struct {
+        Elements []string `json:"elements"`
+        TTL      string   `json:"ttl,omitempty"` // Empty keeps the current TTL
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if len(request.Elements) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "elements are required"})
+        return
+    }
+    if h.config.MaxElements > 0 && len(request.Elements) > h.config.MaxElements {
+        c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many elements, the maximum is %d", h.config.MaxElements)})
+        return
+    }
+
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    changed, err := h.counters.PFAdd(c.Request.Context(), key, request.Elements, ttl)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to add hyperloglog elements", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to add hyperloglog elements"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":     key,
+        "changed": changed,
+    })
+}
+
+// Count handles GET /hll/:key
+func (h *HyperLogLogHandler) Count(c *gin.Context) {
+    key := c.Param("key")
+
+    count, err := h.counters.PFCount(c.Request.Context(), key)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to count hyperloglog", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to count hyperloglog"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "count": count,
+    })
+}
+
+// Merge handles POST /hll/:key/merge, merging the counters of the body into key
+func (h *HyperLogLogHandler) Merge(c *gin.Context) {
+    key := c.Param("key")
+
+    var request struct {
+        Keys []string `json:"keys"`
+        TTL  string   `json:"ttl,omitempty"` // Empty keeps the current TTL
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if len(request.Keys) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "keys are required"})
+        return
+    }
+    if !authorizeKeys(c, request.Keys...) {
+        return
+    }
+
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    count, err := h.counters.PFMerge(c.Request.Context(), key, request.Keys, ttl)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to merge hyperloglogs", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to merge hyperloglogs"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":     key,
+        "sources": request.Keys,
+        "count":   count,
+    })
+}
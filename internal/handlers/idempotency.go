@@ -0,0 +1,191 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/middleware"
+    "distributed-cache/pkg/models"
+)
+
+// defaultIdempotencyTTL is used when a completion does not specify a TTL
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyHandler exposes idempotency keys over HTTP for services that
+// deduplicate their own requests
+type IdempotencyHandler struct {
+    store  cache.IdempotencyStore
+    logger *zap.Logger
+}
+
+// NewIdempotencyHandler creates a new idempotency handler
+func NewIdempotencyHandler(store cache.IdempotencyStore, logger *zap.Logger) *IdempotencyHandler {
+    return &IdempotencyHandler{
+        store:  store,
+        logger: logger,
+    }
+}
+
+// idempotencyKeyName namespaces the keys of the REST API apart from the
+// ones claimed by the HTTP middleware, scoped by principal like them
+func idempotencyKeyName(c *gin.Context, id string) string {
+    return middleware.IdempotencyScope(c, "api", id)
+}
+
+// Claim handles POST /idempotency/:id/claim
+func (h *IdempotencyHandler) Claim(c *gin.Context) {
+    id := c.Param("id")
+    if !authorizeKeys(c, id) {
+        return
+    }
+
+    var request struct {
+        Owner       string `json:"owner,omitempty"`
+        Fingerprint string `json:"fingerprint,omitempty"` // Identifies the request, duplicates must send the same one
+        LockTTL     string `json:"lock_ttl,omitempty"`    // Duration in format "30s", "5m"
+    }
+    if c.Request.ContentLength != 0 {
+        if err := c.ShouldBindJSON(&request); err != nil {
+            requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+            return
+        }
+    }
+
+    lockTTL, ok := parseLockTTL(c, request.LockTTL)
+    if !ok {
+        return
+    }
+
+    owner := request.Owner
+    if owner == "" {
+        owner = newOwnerID()
+    }
+
+    record, claimed, err := h.store.ClaimIdempotencyKey(c.Request.Context(), idempotencyKeyName(c, id), owner, request.Fingerprint, lockTTL)
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to claim idempotency key", zap.Error(err), zap.String("id", id))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to claim idempotency key"})
+        return
+    }
+    record.Key = id
+
+    switch {
+    case claimed:
+        requestLogger(c, h.logger).Debug("idempotency key claimed via API", zap.String("id", id))
+        c.JSON(http.StatusCreated, record)
+    case record.Fingerprint != request.Fingerprint:
+        c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "idempotency key was used with a different fingerprint"})
+    case record.Completed():
+        c.JSON(http.StatusOK, record)
+    default:
+        c.JSON(http.StatusConflict, record)
+    }
+}
+
+// Complete handles PUT /idempotency/:id/complete
+func (h *IdempotencyHandler) Complete(c *gin.Context) {
+    id := c.Param("id")
+    if !authorizeKeys(c, id) {
+        return
+    }
+
+    var request struct {
+        Owner    string                     `json:"owner"`
+        Response *models.IdempotentResponse `json:"response"`
+        TTL      string                     `json:"ttl,omitempty"` // Duration in format "1h", "24h"
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if request.Owner == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "owner is required"})
+        return
+    }
+    if request.Response == nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "response is required"})
+        return
+    }
+
+    ttl := defaultIdempotencyTTL
+    if request.TTL != "" {
+        var err error
+        if ttl, err = time.ParseDuration(request.TTL); err != nil || ttl <= 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid TTL format"})
+            return
+        }
+    }
+
+    record, err := h.store.CompleteIdempotencyKey(c.Request.Context(), idempotencyKeyName(c, id), request.Owner, request.Response, ttl)
+    if err != nil {
+        if errors.Is(err, cache.ErrIdempotencyNotOwned) {
+            c.JSON(http.StatusConflict, gin.H{"error": "idempotency key is not claimed by this owner"})
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to complete idempotency key", zap.Error(err), zap.String("id", id))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to complete idempotency key"})
+        return
+    }
+    record.Key = id
+
+    requestLogger(c, h.logger).Debug("idempotency key completed via API", zap.String("id", id))
+    c.JSON(http.StatusOK, record)
+}
+
+// Release handles DELETE /idempotency/:id
+func (h *IdempotencyHandler) Release(c *gin.Context) {
+    id := c.Param("id")
+    if !authorizeKeys(c, id) {
+        return
+    }
+
+    var request struct {
+        Owner string `json:"owner"`
+    }
+    if err := c.ShouldBindJSON(&request); err != nil || request.Owner == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "owner is required"})
+        return
+    }
+
+    err := h.store.ReleaseIdempotencyKey(c.Request.Context(), idempotencyKeyName(c, id), request.Owner)
+    if err != nil {
+        if errors.Is(err, cache.ErrIdempotencyNotOwned) {
+            c.JSON(http.StatusConflict, gin.H{"error": "idempotency key is not claimed by this owner"})
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to release idempotency key", zap.Error(err), zap.String("id", id))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to release idempotency key"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "idempotency key released successfully"})
+}
+
+// Get handles GET /idempotency/:id
+func (h *IdempotencyHandler) Get(c *gin.Context) {
+    id := c.Param("id")
+    if !authorizeKeys(c, id) {
+        return
+    }
+
+    record, err := h.store.GetIdempotencyKey(c.Request.Context(), idempotencyKeyName(c, id))
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to get idempotency key", zap.Error(err), zap.String("id", id))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get idempotency key"})
+        return
+    }
+    if record == nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "idempotency key not found"})
+        return
+    }
+    record.Key = id
+
+    c.JSON(http.StatusOK, record)
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "sync/atomic"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+)
+
+// maxPopTimeout keeps blocking pops below the server write timeout
+const maxPopTimeout = 25 * time.Second
+
+// ListHandler handles HTTP operations on lists
+type ListHandler struct {
+    lists        cache.ListStore
+    config       config.ListConfig
+    logger       *zap.Logger
+    blockingPops atomic.Int64 // Blocking pops waiting on this instance
+}
+
+// NewListHandler creates a new list handler
+func NewListHandler(lists cache.ListStore, cfg config.ListConfig, logger *zap.Logger) *ListHandler {
+    if cfg.MaxBlockingPops <= 0 {
+        cfg.MaxBlockingPops = 5
+    }
+    return &ListHandler{
+        lists:  lists,
+        config: cfg,
+        logger: logger,
+    }
+}
+
+// parseListEnd validates the side of a push or pop, defaulting to def
+func parseListEnd(c *gin.Context, value string, def cache.ListEnd) (cache.ListEnd, bool) {
+    switch cache.ListEnd(value) {
+    case "":
+        return def, true
+    case cache.ListLeft, cache.ListRight:
+        return cache.ListEnd(value), true
+    }
+    c.JSON(http.StatusBadRequest, gin.H{"error": "side must be left or right"})
+    return "", false
+}
+
+// GetRange handles GET /list/:key?start=0&stop=-1
+func (h *ListHandler) GetRange(c *gin.Context) {
+    key := c.Param("key")
+
+    start, err := strconv.ParseInt(c.DefaultQuery("start", "0"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start index"})
+        return
+    }
+    stop, err := strconv.ParseInt(c.DefaultQuery("stop", "-1"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stop index"})
+        return
+    }
+
+    values, err := h.lists.ListRange(c.Request.Context(), key, start, stop)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to get list range", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get list range"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":    key,
+        "values": values,
+        "count":  len(values),
+    })
+}
+
+// Length handles GET /list/:key/len
+func (h *ListHandler) Length(c *gin.Context) {
+    key := c.Param("key")
+
+    length, err := h.lists.ListLen(c.Request.Context(), key)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to get list length", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get list length"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":    key,
+        "length": length,
+    })
+}
+
+// Push handles POST /list/:key/push
+func (h *ListHandler) Push(c *gin.Context) {
+    key := c.Param("key")
+
+    var request struct {
+        Values []interface{} `json:"values"`
+        Side   string        `json:"side,omitempty"`    // "right" (default) or "left"
+        TTL    string        `json:"ttl,omitempty"`     // Empty keeps the current TTL
+        MaxLen int64         `json:"max_len,omitempty"` // Trim to the newest max_len elements
+    }
+
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if len(request.Values) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "values are required"})
+        return
+    }
+    if request.MaxLen < 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "max_len must not be negative"})
+        return
+    }
+
+    end, ok := parseListEnd(c, request.Side, cache.ListRight)
+    if !ok {
+        return
+    }
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    length, err := h.lists.ListPush(c.Request.Context(), key, end, request.Values, ttl, request.MaxLen)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to push list values", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to push list values"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":    key,
+        "length": length,
+    })
+}
+
+// Pop handles POST /list/:key/pop?side=left&timeout=5s. With a timeout the
+// request waits for an element until it elapses or the client goes away.
+func (h *ListHandler) Pop(c *gin.Context) {
+    key := c.Param("key")
+
+    end, ok := parseListEnd(c, c.Query("side"), cache.ListLeft)
+    if !ok {
+        return
+    }
+
+    var timeout time.Duration
+    if value := c.Query("timeout"); value != "" {
+        parsed, err := time.ParseDuration(value)
+        if err != nil || parsed < 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timeout format"})
+            return
+        }
+        if parsed > maxPopTimeout {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "timeout must not exceed " + maxPopTimeout.String()})
+            return
+        }
+        timeout = parsed
+    }
+
+    // Every blocking pop holds a connection of the shared pool while it waits
+    if timeout > 0 {
+        waiting := h.blockingPops.Add(1)
+        defer h.blockingPops.Add(-1)
+        if waiting > int64(h.config.MaxBlockingPops) {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "too many blocking pops"})
+            return
+        }
+    }
+
+    ctx := c.Request.Context()
+    value, found, err := h.lists.ListPop(ctx, key, end, timeout)
+    if err != nil {
+        if ctx.Err() != nil {
+            // The client is gone; nobody reads the response
+            requestLogger(c, h.logger).Debug("list pop canceled", zap.String("key", key))
+            c.Status(http.StatusRequestTimeout)
+            return
+        }
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to pop list value", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to pop list value"})
+        return
+    }
+
+    if !found {
+        c.JSON(http.StatusNotFound, gin.H{"error": "list is empty"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "value": value,
+    })
+}
+
+// Trim handles PUT /list/:key/trim
+func (h *ListHandler) Trim(c *gin.Context) {
+    key := c.Param("key")
+
+    var request struct {
+        Start *int64 `json:"start"`
+        Stop  *int64 `json:"stop"`
+    }
+
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if request.Start == nil || request.Stop == nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "start and stop are required"})
+        return
+    }
+
+    err := h.lists.ListTrim(c.Request.Context(), key, *request.Start, *request.Stop)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to trim list", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to trim list"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "list trimmed successfully"})
+}
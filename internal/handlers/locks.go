@@ -0,0 +1,178 @@
+package handlers
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/pkg/models"
+)
+
+// defaultLockTTL is used when the request does not specify a TTL
+const defaultLockTTL = 30 * time.Second
+
+// LockHandler handles HTTP distributed lock operations
+type LockHandler struct {
+    locker cache.Locker
+    logger *zap.Logger
+}
+
+// NewLockHandler creates a new lock handler
+func NewLockHandler(locker cache.Locker, logger *zap.Logger) *LockHandler {
+    return &LockHandler{
+        locker: locker,
+        logger: logger,
+    }
+}
+
+// lockRequest is the body accepted by the lock endpoints
+type lockRequest struct {
+    Owner string `json:"owner"`
+    TTL   string `json:"ttl,omitempty"` // Duration in format "30s", "5m"
+}
+
+// Acquire handles POST /locks/:name
+func (h *LockHandler) Acquire(c *gin.Context) {
+    name := c.Param("name")
+    if name == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "lock name is required"})
+        return
+    }
+
+    var request lockRequest
+    if c.Request.ContentLength != 0 {
+        if err := c.ShouldBindJSON(&request); err != nil {
+            requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+            return
+        }
+    }
+
+    ttl, ok := parseLockTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    owner := request.Owner
+    if owner == "" {
+        owner = newOwnerID()
+    }
+
+    lock, err := h.locker.AcquireLock(c.Request.Context(), name, owner, ttl)
+    if err != nil {
+        if errors.Is(err, cache.ErrLockHeld) {
+            c.JSON(http.StatusConflict, gin.H{"error": "lock is already held"})
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to acquire lock", zap.Error(err), zap.String("lock", name))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to acquire lock"})
+        return
+    }
+
+    requestLogger(c, h.logger).Debug("lock acquired via API", zap.String("lock", name), zap.Int64("token", lock.Token))
+    c.JSON(http.StatusOK, lockResponse(lock))
+}
+
+// Renew handles PUT /locks/:name/renew
+func (h *LockHandler) Renew(c *gin.Context) {
+    name := c.Param("name")
+    if name == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "lock name is required"})
+        return
+    }
+
+    var request lockRequest
+    if err := c.ShouldBindJSON(&request); err != nil || request.Owner == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "owner is required"})
+        return
+    }
+
+    ttl, ok := parseLockTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    lock, err := h.locker.RenewLock(c.Request.Context(), name, request.Owner, ttl)
+    if err != nil {
+        if errors.Is(err, cache.ErrLockNotOwned) {
+            c.JSON(http.StatusConflict, gin.H{"error": "lock is not held by this owner"})
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to renew lock", zap.Error(err), zap.String("lock", name))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to renew lock"})
+        return
+    }
+
+    c.JSON(http.StatusOK, lockResponse(lock))
+}
+
+// Release handles DELETE /locks/:name
+func (h *LockHandler) Release(c *gin.Context) {
+    name := c.Param("name")
+    if name == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "lock name is required"})
+        return
+    }
+
+    var request lockRequest
+    if err := c.ShouldBindJSON(&request); err != nil || request.Owner == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "owner is required"})
+        return
+    }
+
+    err := h.locker.ReleaseLock(c.Request.Context(), name, request.Owner)
+    if err != nil {
+        if errors.Is(err, cache.ErrLockNotOwned) {
+            c.JSON(http.StatusConflict, gin.H{"error": "lock is not held by this owner"})
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to release lock", zap.Error(err), zap.String("lock", name))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to release lock"})
+        return
+    }
+
+    requestLogger(c, h.logger).Debug("lock released via API", zap.String("lock", name))
+    c.JSON(http.StatusOK, gin.H{"message": "lock released successfully"})
+}
+
+// parseLockTTL parses the TTL of a lock request, writing a 400 response on
+// failure. Redis expires keys in milliseconds, and a TTL that rounds down to
+// zero would delete the lock as soon as it is taken.
+func parseLockTTL(c *gin.Context, value string) (time.Duration, bool) {
+    if value == "" {
+        return defaultLockTTL, true
+    }
+
+    ttl, err := time.ParseDuration(value)
+    if err != nil || ttl < time.Millisecond {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid TTL format"})
+        return 0, false
+    }
+    return ttl, true
+}
+
+// lockResponse builds the JSON representation of a lock
+func lockResponse(lock *models.Lock) gin.H {
+    return gin.H{
+        "name":          lock.Name,
+        "owner":         lock.Owner,
+        "token":         lock.Token,
+        "expires_at":    lock.ExpiresAt,
+        "remaining_ttl": lock.RemainingTTL().String(),
+    }
+}
+
+// newOwnerID generates a random owner identifier
+func newOwnerID() string {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return time.Now().Format("20060102150405.000000000")
+    }
+    return hex.EncodeToString(b)
+}
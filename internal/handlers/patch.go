@@ -0,0 +1,103 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/pkg/jsonpatch"
+)
+
+// acceptPatch media types advertised for PATCH /cache/:key
+const acceptPatch = jsonpatch.MediaTypeJSONPatch + ", " + jsonpatch.MediaTypeMergePatch
+
+// PatchHandler handles partial updates of cache items
+type PatchHandler struct {
+    updater cache.ItemUpdater
+    logger  *zap.Logger
+}
+
+// NewPatchHandler creates a new patch handler
+func NewPatchHandler(updater cache.ItemUpdater, logger *zap.Logger) *PatchHandler {
+    return &PatchHandler{
+        updater: updater,
+        logger:  logger,
+    }
+}
+
+// PatchItem handles PATCH /cache/:key with a JSON Patch (RFC 6902) or JSON
+// Merge Patch (RFC 7396) body, selected by Content-Type
+func (h *PatchHandler) PatchItem(c *gin.Context) {
+    key := c.Param("key")
+
+    body, err := c.GetRawData()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+
+    var update cache.UpdateFunc
+    switch c.ContentType() {
+    case jsonpatch.MediaTypeJSONPatch:
+        patch, err := jsonpatch.DecodePatch(body)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        update = patch.Apply
+    case jsonpatch.MediaTypeMergePatch:
+        var patch interface{}
+        if err := json.Unmarshal(body, &patch); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+            return
+        }
+        update = func(value interface{}) (interface{}, error) {
+            return jsonpatch.MergePatch(value, patch), nil
+        }
+    default:
+        c.Header("Accept-Patch", acceptPatch)
+        c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be one of " + acceptPatch})
+        return
+    }
+
+    item, err := h.updater.Update(c.Request.Context(), key, update)
+    if err != nil {
+        var patchErr *jsonpatch.Error
+        switch {
+        case errors.Is(err, jsonpatch.ErrTestFailed), errors.Is(err, cache.ErrUpdateConflict):
+            c.JSON(http.StatusConflict, gin.H{"error": patchError(err)})
+            return
+        case errors.As(err, &patchErr):
+            c.JSON(http.StatusUnprocessableEntity, gin.H{"error": patchErr.Error()})
+            return
+        }
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to patch cache item", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to patch cache item"})
+        return
+    }
+
+    if item == nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "key not found"})
+        return
+    }
+
+    requestLogger(c, h.logger).Debug("cache item patched via API", zap.String("key", key))
+    c.JSON(http.StatusOK, itemResponse(item))
+}
+
+// patchError returns the message of the failed patch operation, or of err
+// if it did not come from the patch
+func patchError(err error) string {
+    var patchErr *jsonpatch.Error
+    if errors.As(err, &patchErr) {
+        return patchErr.Error()
+    }
+    return err.Error()
+}
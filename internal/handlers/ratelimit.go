@@ -0,0 +1,112 @@
+package handlers
+
+import (
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/middleware"
+)
+
+// RateLimitHandler exposes the distributed rate limiter over HTTP
+type RateLimitHandler struct {
+    limiter cache.RateLimiter
+    logger  *zap.Logger
+}
+
+// NewRateLimitHandler creates a new rate limit handler
+func NewRateLimitHandler(limiter cache.RateLimiter, logger *zap.Logger) *RateLimitHandler {
+    return &RateLimitHandler{
+        limiter: limiter,
+        logger:  logger,
+    }
+}
+
+// Take handles POST /ratelimit/:bucket/take
+func (h *RateLimitHandler) Take(c *gin.Context) {
+    bucket := c.Param("bucket")
+    if bucket == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "bucket is required"})
+        return
+    }
+
+    var request struct {
+        Algorithm string `json:"algorithm,omitempty"` // "token_bucket" (default) or "sliding_window"
+        Limit     int64  `json:"limit"`
+        Window    string `json:"window"` // Duration in format "1s", "1m"
+        Burst     int64  `json:"burst,omitempty"`
+        Tokens    int64  `json:"tokens,omitempty"`
+    }
+
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+
+    window, err := time.ParseDuration(request.Window)
+    if err != nil || window <= 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid window format"})
+        return
+    }
+    // Windows are computed in milliseconds by the limiter scripts
+    if window < time.Millisecond {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "window must be at least 1ms"})
+        return
+    }
+
+    if request.Limit <= 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be positive"})
+        return
+    }
+
+    switch request.Algorithm {
+    case "", cache.AlgorithmTokenBucket, cache.AlgorithmSlidingWindow:
+    default:
+        c.JSON(http.StatusBadRequest, gin.H{"error": "unknown algorithm"})
+        return
+    }
+
+    // A request for more tokens than the bucket can hold would never succeed
+    capacity := request.Limit
+    if request.Algorithm != cache.AlgorithmSlidingWindow && request.Burst > 0 {
+        capacity = request.Burst
+    }
+    if request.Tokens > capacity {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "tokens must not exceed the bucket capacity"})
+        return
+    }
+
+    limit := cache.RateLimit{
+        Algorithm: request.Algorithm,
+        Limit:     request.Limit,
+        Window:    window,
+        Burst:     request.Burst,
+    }
+
+    result, err := h.limiter.Take(c.Request.Context(), "oracle:"+bucket, limit, request.Tokens)
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to take rate limit tokens", zap.Error(err), zap.String("bucket", bucket))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to take rate limit tokens"})
+        return
+    }
+
+    middleware.SetRateLimitHeaders(c, result)
+
+    status := http.StatusOK
+    if !result.Allowed {
+        status = http.StatusTooManyRequests
+    }
+
+    c.JSON(status, gin.H{
+        "bucket":      bucket,
+        "allowed":     result.Allowed,
+        "limit":       result.Limit,
+        "remaining":   result.Remaining,
+        "retry_after": result.RetryAfter.String(),
+        "reset_after": result.ResetAfter.String(),
+    })
+}
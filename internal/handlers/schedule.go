@@ -0,0 +1,392 @@
+package handlers
+
+import (
+    "context"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+    "distributed-cache/pkg/models"
+)
+
+const (
+    // defaultClaimBatch is the number of jobs a consumer receives at a time
+    defaultClaimBatch = 10
+    // maxClaimBatch bounds the max parameter of the long-poll
+    maxClaimBatch = 100
+    // streamHeartbeat is how often an idle event stream sends a comment so
+    // proxies do not close it
+    streamHeartbeat = 15 * time.Second
+)
+
+// ScheduleHandler handles HTTP operations on scheduled jobs
+type ScheduleHandler struct {
+    jobs     cache.JobScheduler
+    config   config.SchedulerConfig
+    logger   *zap.Logger
+    shutdown chan struct{} // Closed when the server shuts down
+    closing  sync.Once
+}
+
+// NewScheduleHandler creates a new scheduled job handler
+func NewScheduleHandler(jobs cache.JobScheduler, cfg config.SchedulerConfig, logger *zap.Logger) *ScheduleHandler {
+    if cfg.PollInterval <= 0 {
+        cfg.PollInterval = 500 * time.Millisecond
+    }
+    if cfg.LeaseTimeout <= 0 {
+        cfg.LeaseTimeout = 30 * time.Second
+    }
+    if cfg.WriteTimeout <= 0 {
+        cfg.WriteTimeout = 10 * time.Second
+    }
+    return &ScheduleHandler{
+        jobs:     jobs,
+        config:   cfg,
+        logger:   logger,
+        shutdown: make(chan struct{}),
+    }
+}
+
+// Shutdown ends the open event streams. http.Server.Shutdown waits for them
+// without canceling their request context, so it must be registered with
+// RegisterOnShutdown.
+func (h *ScheduleHandler) Shutdown() {
+    h.closing.Do(func() { close(h.shutdown) })
+}
+
+// Schedule handles POST /schedule. The job is due after delay or at the
+// given time, or immediately if neither is set.
+func (h *ScheduleHandler) Schedule(c *gin.Context) {
+    var request struct {
+        Topic   string      `json:"topic" binding:"required"`
+        Payload interface{} `json:"payload"`
+        Delay   string      `json:"delay,omitempty"` // Format "30s", "15m"
+        At      *time.Time  `json:"at,omitempty"`    // RFC 3339
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if !authorizeKeys(c, request.Topic) {
+        return
+    }
+
+    dueAt := time.Now()
+    switch {
+    case request.Delay != "" && request.At != nil:
+        c.JSON(http.StatusBadRequest, gin.H{"error": "delay and at are mutually exclusive"})
+        return
+    case request.Delay != "":
+        delay, err := time.ParseDuration(request.Delay)
+        if err != nil || delay < 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delay format"})
+            return
+        }
+        dueAt = dueAt.Add(delay)
+    case request.At != nil:
+        dueAt = *request.At
+    }
+
+    job, err := h.jobs.ScheduleJob(c.Request.Context(), request.Topic, request.Payload, dueAt)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to schedule job", zap.Error(err), zap.String("topic", request.Topic))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to schedule job"})
+        return
+    }
+
+    c.JSON(http.StatusCreated, job)
+}
+
+// GetJob handles GET /schedule/:topic/jobs/:id
+func (h *ScheduleHandler) GetJob(c *gin.Context) {
+    topic, id := c.Param("topic"), c.Param("id")
+    if !authorizeKeys(c, topic) {
+        return
+    }
+
+    job, err := h.jobs.GetJob(c.Request.Context(), topic, id)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to get job", zap.Error(err), zap.String("topic", topic), zap.String("id", id))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get job"})
+        return
+    }
+    if job == nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, job)
+}
+
+// CancelJob handles DELETE /schedule/:topic/jobs/:id
+func (h *ScheduleHandler) CancelJob(c *gin.Context) {
+    h.deleteJob(c, "job canceled successfully")
+}
+
+// AckJob handles POST /schedule/:topic/jobs/:id/ack, confirming that a
+// delivered job was processed so it is not delivered again
+func (h *ScheduleHandler) AckJob(c *gin.Context) {
+    h.deleteJob(c, "job acknowledged successfully")
+}
+
+func (h *ScheduleHandler) deleteJob(c *gin.Context, message string) {
+    topic, id := c.Param("topic"), c.Param("id")
+    if !authorizeKeys(c, topic) {
+        return
+    }
+
+    deleted, err := h.jobs.DeleteJob(c.Request.Context(), topic, id)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to delete job", zap.Error(err), zap.String("topic", topic), zap.String("id", id))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to delete job"})
+        return
+    }
+    if !deleted {
+        c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// RetryJob handles POST /schedule/:topic/jobs/:id/retry?delay=30s, giving a
+// delivered job back before its lease expires
+func (h *ScheduleHandler) RetryJob(c *gin.Context) {
+    topic, id := c.Param("topic"), c.Param("id")
+    if !authorizeKeys(c, topic) {
+        return
+    }
+
+    var delay time.Duration
+    if value := c.Query("delay"); value != "" {
+        parsed, err := time.ParseDuration(value)
+        if err != nil || parsed < 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delay format"})
+            return
+        }
+        delay = parsed
+    }
+
+    retried, err := h.jobs.RetryJob(c.Request.Context(), topic, id, delay)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to retry job", zap.Error(err), zap.String("topic", topic), zap.String("id", id))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to retry job"})
+        return
+    }
+    if !retried {
+        c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "job rescheduled successfully"})
+}
+
+// Poll handles POST /schedule/:topic/poll?max=10&timeout=20s&lease=30s. The
+// request waits up to timeout for due jobs; the jobs returned must be
+// acknowledged within lease or they are delivered again.
+func (h *ScheduleHandler) Poll(c *gin.Context) {
+    topic := c.Param("topic")
+    if !authorizeKeys(c, topic) {
+        return
+    }
+
+    max := defaultClaimBatch
+    if value := c.Query("max"); value != "" {
+        parsed, err := strconv.Atoi(value)
+        if err != nil || parsed <= 0 || parsed > maxClaimBatch {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "max must be between 1 and " + strconv.Itoa(maxClaimBatch)})
+            return
+        }
+        max = parsed
+    }
+
+    var timeout time.Duration
+    if value := c.Query("timeout"); value != "" {
+        parsed, err := time.ParseDuration(value)
+        if err != nil || parsed < 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timeout format"})
+            return
+        }
+        if parsed > maxPopTimeout {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "timeout must not exceed " + maxPopTimeout.String()})
+            return
+        }
+        timeout = parsed
+    }
+
+    lease, ok := h.parseLease(c)
+    if !ok {
+        return
+    }
+
+    ctx := c.Request.Context()
+    jobs, err := h.claim(ctx, topic, max, lease, timeout)
+    if err != nil {
+        if ctx.Err() != nil {
+            // The client is gone; nobody reads the response
+            requestLogger(c, h.logger).Debug("job poll canceled", zap.String("topic", topic))
+            c.Status(http.StatusRequestTimeout)
+            return
+        }
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to claim jobs", zap.Error(err), zap.String("topic", topic))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to claim jobs"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "topic": topic,
+        "jobs":  jobs,
+    })
+}
+
+// claim returns the due jobs of topic, checking every poll interval until
+// some are due or timeout elapses
+func (h *ScheduleHandler) claim(ctx context.Context, topic string, max int, lease, timeout time.Duration) ([]*models.ScheduledJob, error) {
+    deadline := time.Now().Add(timeout)
+    for {
+        jobs, err := h.jobs.ClaimJobs(ctx, topic, max, lease)
+        if err != nil || len(jobs) > 0 || !time.Now().Before(deadline) {
+            return jobs, err
+        }
+
+        select {
+        case <-ctx.Done():
+            return nil, ctx.Err()
+        case <-time.After(h.config.PollInterval):
+        }
+    }
+}
+
+// Events handles GET /schedule/:topic/events?lease=30s, a Server-Sent Events
+// stream with one "job" event per due job. Jobs must be acknowledged within
+// lease or they are delivered again, possibly to another consumer.
+func (h *ScheduleHandler) Events(c *gin.Context) {
+    topic := c.Param("topic")
+    if !authorizeKeys(c, topic) {
+        return
+    }
+
+    lease, ok := h.parseLease(c)
+    if !ok {
+        return
+    }
+
+    logger := requestLogger(c, h.logger)
+    c.Header("Content-Type", "text/event-stream")
+    c.Header("Cache-Control", "no-cache")
+    c.Header("Connection", "keep-alive")
+    c.Header("X-Accel-Buffering", "no")
+    c.Status(http.StatusOK)
+
+    writer := http.NewResponseController(c.Writer)
+    // Not supported by test recorders; the write simply has no deadline
+    writer.SetWriteDeadline(time.Now().Add(h.config.WriteTimeout))
+    c.Writer.Flush()
+
+    ctx, cancel := streamContext(c, h.shutdown)
+    defer cancel()
+    ticker := time.NewTicker(h.config.PollInterval)
+    defer ticker.Stop()
+    lastWrite := time.Now()
+
+    for {
+        jobs, err := h.jobs.ClaimJobs(ctx, topic, defaultClaimBatch, lease)
+        if err != nil {
+            if ctx.Err() != nil {
+                return
+            }
+            logger.Error("failed to claim jobs", zap.Error(err), zap.String("topic", topic))
+            h.send(c, writer, "error", gin.H{"error": "failed to claim jobs"})
+            return
+        }
+
+        for _, job := range jobs {
+            h.send(c, writer, "job", job)
+        }
+        if len(jobs) > 0 {
+            lastWrite = time.Now()
+        }
+        if len(jobs) == defaultClaimBatch {
+            // More may be due
+            continue
+        }
+
+        select {
+        case <-ctx.Done():
+            logger.Debug("job stream closed", zap.String("topic", topic))
+            return
+        case <-ticker.C:
+        }
+
+        if time.Since(lastWrite) >= streamHeartbeat {
+            h.send(c, writer, "", nil)
+            lastWrite = time.Now()
+        }
+    }
+}
+
+// send writes an event, or a keepalive comment if event is empty. A write
+// that exceeds the write timeout closes the connection, which ends the
+// stream through the request context.
+func (h *ScheduleHandler) send(c *gin.Context, writer *http.ResponseController, event string, data interface{}) {
+    // Not supported by test recorders; the write simply has no deadline
+    writer.SetWriteDeadline(time.Now().Add(h.config.WriteTimeout))
+
+    if event == "" {
+        c.Writer.WriteString(": keepalive\n\n")
+    } else {
+        c.SSEvent(event, data)
+    }
+    c.Writer.Flush()
+}
+
+// streamContext returns the request context of an event stream, also
+// canceled when shutdown is closed
+func streamContext(c *gin.Context, shutdown <-chan struct{}) (context.Context, context.CancelFunc) {
+    ctx, cancel := context.WithCancel(c.Request.Context())
+    go func() {
+        select {
+        case <-shutdown:
+            cancel()
+        case <-ctx.Done():
+        }
+    }()
+    return ctx, cancel
+}
+
+// parseLease reads the lease query parameter, defaulting to the configured lease timeout
+func (h *ScheduleHandler) parseLease(c *gin.Context) (time.Duration, bool) {
+    value := c.Query("lease")
+    if value == "" {
+        return h.config.LeaseTimeout, true
+    }
+
+    lease, err := time.ParseDuration(value)
+    if err != nil || lease <= 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lease format"})
+        return 0, false
+    }
+    return lease, true
+}
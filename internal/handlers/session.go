@@ -0,0 +1,237 @@
+package handlers
+
+import (
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/pkg/models"
+)
+
+// defaultSessionTTL is used when a session is created without a TTL
+const defaultSessionTTL = 30 * time.Minute
+
+// SessionHandler handles HTTP session operations
+type SessionHandler struct {
+    sessions cache.SessionStore
+    logger   *zap.Logger
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(sessions cache.SessionStore, logger *zap.Logger) *SessionHandler {
+    return &SessionHandler{
+        sessions: sessions,
+        logger:   logger,
+    }
+}
+
+// sessionResource and userSessionsResource name sessions for the key prefix
+// checks of the principal; the Redis keys themselves are internal
+func sessionResource(id string) string {
+    return "session:" + id
+}
+
+func userSessionsResource(userID string) string {
+    return "sessions:user:" + userID
+}
+
+// CreateSession handles POST /sessions
+func (h *SessionHandler) CreateSession(c *gin.Context) {
+    var request struct {
+        UserID     string                 `json:"user_id" binding:"required"`
+        Attributes map[string]interface{} `json:"attributes,omitempty"`
+        TTL        string                 `json:"ttl,omitempty"` // Idle timeout in format "30m", "12h"
+    }
+
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if !authorizeKeys(c, userSessionsResource(request.UserID)) {
+        return
+    }
+
+    ttl := defaultSessionTTL
+    if request.TTL != "" {
+        var err error
+        if ttl, err = time.ParseDuration(request.TTL); err != nil || ttl <= 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid TTL format"})
+            return
+        }
+    }
+
+    session, err := h.sessions.CreateSession(c.Request.Context(), request.UserID, request.Attributes, ttl)
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to create session", zap.Error(err), zap.String("user_id", request.UserID))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to create session"})
+        return
+    }
+
+    requestLogger(c, h.logger).Debug("session created via API", zap.String("user_id", request.UserID))
+    c.JSON(http.StatusCreated, sessionResponse(session))
+}
+
+// GetSession handles GET /sessions/:id
+func (h *SessionHandler) GetSession(c *gin.Context) {
+    id := c.Param("id")
+    if !authorizeKeys(c, sessionResource(id)) {
+        return
+    }
+
+    session, err := h.sessions.GetSession(c.Request.Context(), id)
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to get session", zap.Error(err))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get session"})
+        return
+    }
+    h.respond(c, session)
+}
+
+// UpdateSession handles PATCH /sessions/:id, merging the attributes of the
+// body; a null attribute is removed
+func (h *SessionHandler) UpdateSession(c *gin.Context) {
+    id := c.Param("id")
+    if !authorizeKeys(c, sessionResource(id)) {
+        return
+    }
+
+    var request struct {
+        Attributes map[string]interface{} `json:"attributes" binding:"required"`
+    }
+
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+
+    session, err := h.sessions.UpdateSession(c.Request.Context(), id, request.Attributes)
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to update session", zap.Error(err))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to update session"})
+        return
+    }
+    h.respond(c, session)
+}
+
+// TouchSession handles POST /sessions/:id/touch
+func (h *SessionHandler) TouchSession(c *gin.Context) {
+    id := c.Param("id")
+    if !authorizeKeys(c, sessionResource(id)) {
+        return
+    }
+
+    session, err := h.sessions.TouchSession(c.Request.Context(), id)
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to touch session", zap.Error(err))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to touch session"})
+        return
+    }
+    h.respond(c, session)
+}
+
+// DeleteSession handles DELETE /sessions/:id
+func (h *SessionHandler) DeleteSession(c *gin.Context) {
+    id := c.Param("id")
+    if !authorizeKeys(c, sessionResource(id)) {
+        return
+    }
+
+    deleted, err := h.sessions.DeleteSession(c.Request.Context(), id)
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to delete session", zap.Error(err))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to delete session"})
+        return
+    }
+
+    if !deleted {
+        c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "session deleted successfully"})
+}
+
+// ListUserSessions handles GET /sessions?user_id=. Session IDs are bearer
+// credentials, so only their metadata is listed.
+func (h *SessionHandler) ListUserSessions(c *gin.Context) {
+    userID := c.Query("user_id")
+    if userID == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+        return
+    }
+    if !authorizeKeys(c, userSessionsResource(userID)) {
+        return
+    }
+
+    sessions, err := h.sessions.ListUserSessions(c.Request.Context(), userID)
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to list sessions", zap.Error(err), zap.String("user_id", userID))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to list sessions"})
+        return
+    }
+
+    response := make([]gin.H, len(sessions))
+    for i, session := range sessions {
+        response[i] = sessionResponse(session)
+        delete(response[i], "id")
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "user_id":  userID,
+        "sessions": response,
+        "count":    len(response),
+    })
+}
+
+// DeleteUserSessions handles DELETE /sessions?user_id=, logging the user
+// out everywhere
+func (h *SessionHandler) DeleteUserSessions(c *gin.Context) {
+    userID := c.Query("user_id")
+    if userID == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+        return
+    }
+    if !authorizeKeys(c, userSessionsResource(userID)) {
+        return
+    }
+
+    deleted, err := h.sessions.DeleteUserSessions(c.Request.Context(), userID)
+    if err != nil {
+        requestLogger(c, h.logger).Error("failed to delete user sessions", zap.Error(err), zap.String("user_id", userID))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to delete user sessions"})
+        return
+    }
+
+    requestLogger(c, h.logger).Debug("user sessions deleted via API", zap.String("user_id", userID), zap.Int64("sessions", deleted))
+    c.JSON(http.StatusOK, gin.H{
+        "user_id": userID,
+        "deleted": deleted,
+    })
+}
+
+// respond writes the session, or 404 if it does not exist
+func (h *SessionHandler) respond(c *gin.Context, session *models.Session) {
+    if session == nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+        return
+    }
+    c.JSON(http.StatusOK, sessionResponse(session))
+}
+
+// sessionResponse builds the JSON representation of a session
+func sessionResponse(session *models.Session) gin.H {
+    return gin.H{
+        "id":               session.ID,
+        "user_id":          session.UserID,
+        "attributes":       session.Attributes,
+        "ttl":              session.TTL.String(),
+        "created_at":       session.CreatedAt,
+        "last_accessed_at": session.LastAccessedAt,
+        "expires_at":       session.ExpiresAt,
+    }
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+    "context"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+)
+
+// defaultScanCount members requested per SSCAN page when the client does not say
+const defaultScanCount = 100
+
+// SetHandler handles HTTP operations on sets
+type SetHandler struct {
+    sets   cache.SetStore
+    logger *zap.Logger
+}
+
+// NewSetHandler creates a new set handler
+func NewSetHandler(sets cache.SetStore, logger *zap.Logger) *SetHandler {
+    return &SetHandler{
+        sets:   sets,
+        logger: logger,
+    }
+}
+
+// membersRequest is the body of the endpoints that add or remove members
+type membersRequest struct {
+    Members []string `json:"members"`
+    TTL     string   `json:"ttl,omitempty"` // Empty keeps the current TTL
+}
+
+// AddMembers handles POST /set/:key/members
+func (h *SetHandler) AddMembers(c *gin.Context) {
+    key := c.Param("key")
+
+    var request membersRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if len(request.Members) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "members are required"})
+        return
+    }
+
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    added, err := h.sets.SAdd(c.Request.Context(), key, request.Members, ttl)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to add set members", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to add set members"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "added": added,
+    })
+}
+
+// RemoveMembers handles DELETE /set/:key/members
+func (h *SetHandler) RemoveMembers(c *gin.Context) {
+    key := c.Param("key")
+
+    var request membersRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if len(request.Members) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "members are required"})
+        return
+    }
+
+    h.remove(c, key, request.Members)
+}
+
+// RemoveMember handles DELETE /set/:key/members/:member
+func (h *SetHandler) RemoveMember(c *gin.Context) {
+    h.remove(c, c.Param("key"), []string{c.Param("member")})
+}
+
+func (h *SetHandler) remove(c *gin.Context, key string, members []string) {
+    removed, err := h.sets.SRem(c.Request.Context(), key, members...)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to remove set members", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to remove set members"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":     key,
+        "removed": removed,
+    })
+}
+
+// IsMember handles GET /set/:key/members/:member
+func (h *SetHandler) IsMember(c *gin.Context) {
+    key, member := c.Param("key"), c.Param("member")
+
+    isMember, err := h.sets.SIsMember(c.Request.Context(), key, member)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to check set membership", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to check set membership"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":       key,
+        "member":    member,
+        "is_member": isMember,
+    })
+}
+
+// ScanMembers handles GET /set/:key/members?cursor=0&match=*&count=100
+func (h *SetHandler) ScanMembers(c *gin.Context) {
+    key := c.Param("key")
+
+    cursor, err := strconv.ParseUint(c.DefaultQuery("cursor", "0"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+        return
+    }
+    count, err := strconv.ParseInt(c.DefaultQuery("count", strconv.Itoa(defaultScanCount)), 10, 64)
+    if err != nil || count <= 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "count must be a positive integer"})
+        return
+    }
+
+    members, next, err := h.sets.SScan(c.Request.Context(), key, cursor, c.Query("match"), count)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to scan set members", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to scan set members"})
+        return
+    }
+
+    // SCAN may return duplicates across pages; "cursor": 0 ends the iteration
+    c.JSON(http.StatusOK, gin.H{
+        "key":     key,
+        "members": members,
+        "cursor":  next,
+    })
+}
+
+// Cardinality handles GET /set/:key/card
+func (h *SetHandler) Cardinality(c *gin.Context) {
+    key := c.Param("key")
+
+    count, err := h.sets.SCard(c.Request.Context(), key)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to count set members", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to count set members"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "count": count,
+    })
+}
+
+// Union handles POST /set/union
+func (h *SetHandler) Union(c *gin.Context) {
+    h.combine(c, "union", h.sets.SUnion)
+}
+
+// Intersection handles POST /set/inter
+func (h *SetHandler) Intersection(c *gin.Context) {
+    h.combine(c, "intersection", h.sets.SInter)
+}
+
+func (h *SetHandler) combine(c *gin.Context, name string, fn func(ctx context.Context, keys ...string) ([]string, error)) {
+    var request struct {
+        Keys []string `json:"keys"`
+    }
+
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if len(request.Keys) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "keys are required"})
+        return
+    }
+    if !authorizeKeys(c, request.Keys...) {
+        return
+    }
+
+    members, err := fn(c.Request.Context(), request.Keys...)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to compute set "+name, zap.Error(err), zap.Strings("keys", request.Keys))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to compute set " + name})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "keys":    request.Keys,
+        "members": members,
+        "count":   len(members),
+    })
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+)
+
+// ZSetHandler handles HTTP operations on sorted sets
+type ZSetHandler struct {
+    zsets  cache.ZSetStore
+    logger *zap.Logger
+}
+
+// NewZSetHandler creates a new sorted set handler
+func NewZSetHandler(zsets cache.ZSetStore, logger *zap.Logger) *ZSetHandler {
+    return &ZSetHandler{
+        zsets:  zsets,
+        logger: logger,
+    }
+}
+
+// AddMembers handles POST /zset/:key/members
+func (h *ZSetHandler) AddMembers(c *gin.Context) {
+    key := c.Param("key")
+
+    var request struct {
+        Members []cache.ScoredMember `json:"members"`
+        TTL     string               `json:"ttl,omitempty"` // Empty keeps the current TTL
+    }
+
+    if err := c.ShouldBindJSON(&request); err != nil {
+        requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        return
+    }
+    if len(request.Members) == 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "members are required"})
+        return
+    }
+
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    added, err := h.zsets.ZAdd(c.Request.Context(), key, request.Members, ttl)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to add sorted set members", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to add sorted set members"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "added": added,
+    })
+}
+
+// IncrementScore handles POST /zset/:key/members/:member/incr
+func (h *ZSetHandler) IncrementScore(c *gin.Context) {
+    key, member := c.Param("key"), c.Param("member")
+
+    var request struct {
+        By  *float64 `json:"by,omitempty"` // Defaults to 1
+        TTL string   `json:"ttl,omitempty"`
+    }
+    if c.Request.ContentLength != 0 {
+        if err := c.ShouldBindJSON(&request); err != nil {
+            requestLogger(c, h.logger).Warn("invalid request body", zap.Error(err))
+            c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+            return
+        }
+    }
+
+    delta := 1.0
+    if request.By != nil {
+        delta = *request.By
+    }
+    ttl, ok := parseKeyTTL(c, request.TTL)
+    if !ok {
+        return
+    }
+
+    score, err := h.zsets.ZIncrBy(c.Request.Context(), key, member, delta, ttl)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to increment sorted set score", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to increment sorted set score"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":    key,
+        "member": member,
+        "score":  score,
+    })
+}
+
+// RemoveMember handles DELETE /zset/:key/members/:member
+func (h *ZSetHandler) RemoveMember(c *gin.Context) {
+    key, member := c.Param("key"), c.Param("member")
+
+    removed, err := h.zsets.ZRem(c.Request.Context(), key, member)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to remove sorted set member", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to remove sorted set member"})
+        return
+    }
+
+    if removed == 0 {
+        c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "member removed successfully"})
+}
+
+// GetRank handles GET /zset/:key/members/:member?reverse=true
+func (h *ZSetHandler) GetRank(c *gin.Context) {
+    key, member := c.Param("key"), c.Param("member")
+
+    reverse, ok := parseReverse(c)
+    if !ok {
+        return
+    }
+
+    rank, score, found, err := h.zsets.ZRank(c.Request.Context(), key, member, reverse)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to get sorted set rank", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get sorted set rank"})
+        return
+    }
+
+    if !found {
+        c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":    key,
+        "member": member,
+        "rank":   rank,
+        "score":  score,
+    })
+}
+
+// RangeByRank handles GET /zset/:key/range?start=0&stop=9&reverse=true
+func (h *ZSetHandler) RangeByRank(c *gin.Context) {
+    key := c.Param("key")
+
+    start, err := strconv.ParseInt(c.DefaultQuery("start", "0"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start rank"})
+        return
+    }
+    stop, err := strconv.ParseInt(c.DefaultQuery("stop", "-1"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stop rank"})
+        return
+    }
+    reverse, ok := parseReverse(c)
+    if !ok {
+        return
+    }
+
+    members, err := h.zsets.ZRange(c.Request.Context(), key, start, stop, reverse)
+    h.respondRange(c, key, members, err)
+}
+
+// RangeByScore handles GET /zset/:key/range/score?min=-inf&max=+inf&offset=0&count=10&reverse=true
+func (h *ZSetHandler) RangeByScore(c *gin.Context) {
+    key := c.Param("key")
+
+    offset, err := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 64)
+    if err != nil || offset < 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+        return
+    }
+    count, err := strconv.ParseInt(c.DefaultQuery("count", "0"), 10, 64)
+    if err != nil || count < 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid count"})
+        return
+    }
+    reverse, ok := parseReverse(c)
+    if !ok {
+        return
+    }
+
+    min, max := c.Query("min"), c.Query("max")
+    if !validScoreBound(min) || !validScoreBound(max) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "min and max must be numbers, -inf or +inf, optionally prefixed by ( for exclusive bounds"})
+        return
+    }
+
+    scores := cache.ScoreRange{
+        Min:    min,
+        Max:    max,
+        Offset: offset,
+        Count:  count,
+    }
+    members, err := h.zsets.ZRangeByScore(c.Request.Context(), key, scores, reverse)
+    h.respondRange(c, key, members, err)
+}
+
+// Cardinality handles GET /zset/:key/card
+func (h *ZSetHandler) Cardinality(c *gin.Context) {
+    key := c.Param("key")
+
+    count, err := h.zsets.ZCard(c.Request.Context(), key)
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to count sorted set members", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to count sorted set members"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":   key,
+        "count": count,
+    })
+}
+
+func (h *ZSetHandler) respondRange(c *gin.Context, key string, members []cache.ScoredMember, err error) {
+    if err != nil {
+        if dataTypeError(c, err) {
+            return
+        }
+        requestLogger(c, h.logger).Error("failed to get sorted set range", zap.Error(err), zap.String("key", key))
+        c.JSON(errorStatus(err), gin.H{"error": "failed to get sorted set range"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "key":     key,
+        "members": members,
+        "count":   len(members),
+    })
+}
+
+// validScoreBound checks a score bound before it reaches Redis; empty means unbounded
+func validScoreBound(value string) bool {
+    value = strings.TrimPrefix(value, "(")
+    switch value {
+    case "", "-inf", "+inf", "inf":
+        return true
+    }
+    _, err := strconv.ParseFloat(value, 64)
+    return err == nil
+}
+
+// parseReverse reads the ?reverse flag; reverse ranks count from the highest score
+func parseReverse(c *gin.Context) (bool, bool) {
+    value := c.Query("reverse")
+    if value == "" {
+        return false, true
+    }
+    reverse, err := strconv.ParseBool(value)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "reverse must be true or false"})
+        return false, false
+    }
+    return reverse, true
+}
@@ -0,0 +1,208 @@
+package hotkeys
+
+import (
+    "math/rand"
+    "sync"
+    "time"
+
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+)
+
+// HotKey a frequently accessed key
+type HotKey struct {
+    Key       string `json:"key"`
+    Namespace string `json:"namespace,omitempty"`
+    Count     uint64 `json:"count"` // Estimated accesses, decayed every interval
+}
+
+// BigKey a key holding a large value
+type BigKey struct {
+    Key       string    `json:"key"`
+    Namespace string    `json:"namespace,omitempty"`
+    Size      int       `json:"size"` // Encoded value size in bytes
+    Operation string    `json:"operation"`
+    SeenAt    time.Time `json:"seen_at"`
+}
+
+// bigKeyInfo data attached to tracked big keys
+type bigKeyInfo struct {
+    operation string
+    seenAt    time.Time
+}
+
+// defaultSampleRate keeps the tracker cheap on busy instances: hot keys stand
+// out just as well from a tenth of the accesses
+const defaultSampleRate = 0.1
+
+// Tracker samples key accesses to find hot keys (count-min sketch plus top-K)
+// and big keys (largest values written or read). It implements cache.Observer.
+type Tracker struct {
+    cache.NopObserver
+
+    cfg    config.HotKeysConfig
+    logger *zap.Logger
+
+    mu        sync.Mutex
+    sketch    *countMinSketch
+    hot       *topK
+    big       *topK
+    warned    map[string]bool // Hot keys already reported in the current interval
+    lastDecay time.Time
+}
+
+// New creates a tracker, filling unset options with sensible defaults
+func New(cfg config.HotKeysConfig, logger *zap.Logger) *Tracker {
+    if cfg.SampleRate <= 0 {
+        cfg.SampleRate = defaultSampleRate
+    }
+    if cfg.SampleRate > 1 {
+        cfg.SampleRate = 1
+    }
+    if cfg.TopK <= 0 {
+        cfg.TopK = 50
+    }
+    if cfg.SketchWidth <= 0 {
+        cfg.SketchWidth = 2048
+    }
+    if cfg.SketchDepth <= 0 {
+        cfg.SketchDepth = 4
+    }
+    if cfg.DecayInterval <= 0 {
+        cfg.DecayInterval = time.Minute
+    }
+    if cfg.BigKeys <= 0 {
+        cfg.BigKeys = 50
+    }
+
+    return &Tracker{
+        cfg:       cfg,
+        logger:    logger,
+        sketch:    newCountMinSketch(cfg.SketchWidth, cfg.SketchDepth),
+        hot:       newTopK(cfg.TopK),
+        big:       newTopK(cfg.BigKeys),
+        warned:    make(map[string]bool),
+        lastDecay: time.Now(),
+    }
+}
+
+// ObserveKeyAccess implements cache.Observer
+func (t *Tracker) ObserveKeyAccess(access cache.KeyAccess) {
+    // Sample before locking so that skipped accesses without a size to
+    // track never contend on the mutex
+    sampled := t.cfg.SampleRate >= 1 || rand.Float64() < t.cfg.SampleRate
+    removal := access.Kind == cache.AccessDelete || access.Kind == cache.AccessEvict
+    if !sampled && !removal && access.Size <= 0 {
+        return
+    }
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    now := time.Now()
+    t.maybeDecay(now)
+
+    if removal {
+        t.big.remove(access.Key)
+        return
+    }
+
+    if access.Size > 0 {
+        t.observeSize(access, now)
+    }
+
+    if !sampled {
+        return
+    }
+    count := t.scale(t.sketch.add(access.Key))
+    t.hot.offer(access.Key, count, nil)
+
+    if t.cfg.HotKeyThreshold > 0 && count >= uint64(t.cfg.HotKeyThreshold) && !t.warned[access.Key] {
+        t.warned[access.Key] = true
+        t.logger.Warn("hot key detected",
+            zap.String("key", access.Key),
+            zap.String("namespace", access.Namespace),
+            zap.Uint64("estimated_accesses", count),
+            zap.Duration("interval", t.cfg.DecayInterval))
+    }
+}
+
+// observeSize tracks the largest values; callers hold t.mu
+func (t *Tracker) observeSize(access cache.KeyAccess, now time.Time) {
+    previous := 0
+    if e, ok := t.big.get(access.Key); ok {
+        previous = int(e.score)
+    }
+
+    tracked := t.big.offer(access.Key, uint64(access.Size), bigKeyInfo{operation: access.Operation, seenAt: now})
+    if tracked && t.cfg.BigKeyThreshold > 0 && access.Size >= t.cfg.BigKeyThreshold && previous < t.cfg.BigKeyThreshold {
+        t.logger.Warn("big key detected",
+            zap.String("key", access.Key),
+            zap.String("namespace", access.Namespace),
+            zap.Int("size", access.Size),
+            zap.String("operation", access.Operation))
+    }
+}
+
+// maybeDecay halves the hot key counters once per interval; callers hold t.mu
+func (t *Tracker) maybeDecay(now time.Time) {
+    if now.Sub(t.lastDecay) < t.cfg.DecayInterval {
+        return
+    }
+    t.sketch.decay()
+    t.hot.decay()
+    t.warned = make(map[string]bool)
+    t.lastDecay = now
+}
+
+// scale converts a sampled count into an estimate of real accesses
+func (t *Tracker) scale(count uint32) uint64 {
+    return uint64(float64(count)/t.cfg.SampleRate + 0.5)
+}
+
+// HotKeys returns up to limit hot keys, most accessed first (limit <= 0 means all)
+func (t *Tracker) HotKeys(limit int) []HotKey {
+    t.mu.Lock()
+    t.maybeDecay(time.Now())
+    entries := t.hot.sorted()
+    t.mu.Unlock()
+
+    if limit > 0 && len(entries) > limit {
+        entries = entries[:limit]
+    }
+    keys := make([]HotKey, len(entries))
+    for i, e := range entries {
+        keys[i] = HotKey{Key: e.key, Namespace: cache.Namespace(e.key), Count: e.score}
+    }
+    return keys
+}
+
+// BigKeys returns up to limit big keys, largest first (limit <= 0 means all)
+func (t *Tracker) BigKeys(limit int) []BigKey {
+    t.mu.Lock()
+    entries := t.big.sorted()
+    t.mu.Unlock()
+
+    if limit > 0 && len(entries) > limit {
+        entries = entries[:limit]
+    }
+    keys := make([]BigKey, len(entries))
+    for i, e := range entries {
+        info := e.data.(bigKeyInfo)
+        keys[i] = BigKey{
+            Key:       e.key,
+            Namespace: cache.Namespace(e.key),
+            Size:      int(e.score),
+            Operation: info.operation,
+            SeenAt:    info.seenAt,
+        }
+    }
+    return keys
+}
+
+// Config returns the effective tracker configuration
+func (t *Tracker) Config() config.HotKeysConfig {
+    return t.cfg
+}
@@ -0,0 +1,100 @@
+package hotkeys
+
+import (
+    "fmt"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+    "go.uber.org/zap/zaptest/observer"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+)
+
+func hit(t *Tracker, key string, size int) {
+    t.ObserveKeyAccess(cache.KeyAccess{
+        Operation: "get",
+        Key:       key,
+        Namespace: cache.Namespace(key),
+        Kind:      cache.AccessHit,
+        Size:      size,
+    })
+}
+
+func TestTracker_HotKeys(t *testing.T) {
+    tracker := New(config.HotKeysConfig{SampleRate: 1, TopK: 3}, zap.NewNop())
+
+    for i := 0; i < 100; i++ {
+        hit(tracker, "product:1", 10)
+    }
+    for i := 0; i < 50; i++ {
+        hit(tracker, "product:2", 10)
+    }
+    // Long tail of cold keys
+    for i := 0; i < 500; i++ {
+        hit(tracker, fmt.Sprintf("user:%d", i), 10)
+    }
+
+    keys := tracker.HotKeys(0)
+    require.Len(t, keys, 3)
+    assert.Equal(t, "product:1", keys[0].Key)
+    assert.Equal(t, "product", keys[0].Namespace)
+    assert.GreaterOrEqual(t, keys[0].Count, uint64(100))
+    assert.Equal(t, "product:2", keys[1].Key)
+
+    assert.Len(t, tracker.HotKeys(1), 1)
+}
+
+func TestTracker_BigKeys(t *testing.T) {
+    core, logs := observer.New(zap.WarnLevel)
+    tracker := New(config.HotKeysConfig{SampleRate: 1, BigKeys: 2, BigKeyThreshold: 1000}, zap.New(core))
+
+    hit(tracker, "a", 100)
+    hit(tracker, "b", 5000)
+    hit(tracker, "c", 300)
+    hit(tracker, "b", 5000) // Already reported
+
+    keys := tracker.BigKeys(0)
+    require.Len(t, keys, 2)
+    assert.Equal(t, "b", keys[0].Key)
+    assert.Equal(t, 5000, keys[0].Size)
+    assert.Equal(t, "c", keys[1].Key)
+    assert.Equal(t, 1, logs.FilterMessage("big key detected").Len())
+
+    tracker.ObserveKeyAccess(cache.KeyAccess{Operation: "delete", Key: "b", Kind: cache.AccessDelete})
+    keys = tracker.BigKeys(0)
+    require.Len(t, keys, 1)
+    assert.Equal(t, "c", keys[0].Key)
+}
+
+func TestTracker_HotKeyWarningAndDecay(t *testing.T) {
+    core, logs := observer.New(zap.WarnLevel)
+    tracker := New(config.HotKeysConfig{SampleRate: 1, HotKeyThreshold: 10, DecayInterval: time.Hour}, zap.New(core))
+
+    for i := 0; i < 20; i++ {
+        hit(tracker, "product:1", 0)
+    }
+    assert.Equal(t, 1, logs.FilterMessage("hot key detected").Len())
+
+    // Force a decay: counters are halved and warnings re-armed
+    tracker.lastDecay = time.Now().Add(-2 * time.Hour)
+    keys := tracker.HotKeys(0)
+    require.Len(t, keys, 1)
+    assert.Equal(t, uint64(10), keys[0].Count)
+    assert.Empty(t, tracker.warned)
+}
+
+func TestTracker_SampleRate(t *testing.T) {
+    tracker := New(config.HotKeysConfig{SampleRate: 0.5}, zap.NewNop())
+
+    for i := 0; i < 10000; i++ {
+        hit(tracker, "product:1", 0)
+    }
+
+    keys := tracker.HotKeys(0)
+    require.Len(t, keys, 1)
+    assert.InDelta(t, 10000, float64(keys[0].Count), 1000)
+}
@@ -0,0 +1,50 @@
+package hotkeys
+
+import (
+    "hash/maphash"
+)
+
+// countMinSketch estimates key frequencies in fixed memory. Estimates never
+// undercount; they may overcount when keys collide in every row.
+type countMinSketch struct {
+    width  uint64
+    seeds  []maphash.Seed
+    counts [][]uint32
+}
+
+func newCountMinSketch(width, depth int) *countMinSketch {
+    s := &countMinSketch{
+        width:  uint64(width),
+        seeds:  make([]maphash.Seed, depth),
+        counts: make([][]uint32, depth),
+    }
+    for i := range s.counts {
+        s.seeds[i] = maphash.MakeSeed()
+        s.counts[i] = make([]uint32, width)
+    }
+    return s
+}
+
+// add increments key and returns its new estimated count
+func (s *countMinSketch) add(key string) uint32 {
+    var estimate uint32
+    for i, row := range s.counts {
+        idx := maphash.String(s.seeds[i], key) % s.width
+        if row[idx] < ^uint32(0) {
+            row[idx]++
+        }
+        if i == 0 || row[idx] < estimate {
+            estimate = row[idx]
+        }
+    }
+    return estimate
+}
+
+// decay halves every counter so old traffic fades out
+func (s *countMinSketch) decay() {
+    for _, row := range s.counts {
+        for i := range row {
+            row[i] >>= 1
+        }
+    }
+}
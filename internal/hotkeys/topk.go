@@ -0,0 +1,129 @@
+package hotkeys
+
+import (
+    "container/heap"
+    "sort"
+)
+
+// entry is a key tracked by a topK with its score
+type entry struct {
+    key   string
+    score uint64
+    index int
+    data  interface{}
+}
+
+// topK keeps the k keys with the highest score using a min-heap, so the
+// weakest candidate can be evicted in O(log k)
+type topK struct {
+    k       int
+    entries entryHeap
+    byKey   map[string]*entry
+}
+
+func newTopK(k int) *topK {
+    return &topK{k: k, byKey: make(map[string]*entry, k)}
+}
+
+// offer records score for key and reports whether the key is tracked afterwards
+func (t *topK) offer(key string, score uint64, data interface{}) bool {
+    if e, ok := t.byKey[key]; ok {
+        e.score = score
+        e.data = data
+        heap.Fix(&t.entries, e.index)
+        return true
+    }
+
+    if len(t.entries) < t.k {
+        e := &entry{key: key, score: score, data: data}
+        heap.Push(&t.entries, e)
+        t.byKey[key] = e
+        return true
+    }
+
+    weakest := t.entries[0]
+    if score <= weakest.score {
+        return false
+    }
+    delete(t.byKey, weakest.key)
+    weakest.key = key
+    weakest.score = score
+    weakest.data = data
+    t.byKey[key] = weakest
+    heap.Fix(&t.entries, 0)
+    return true
+}
+
+// get returns the tracked entry for key, if any
+func (t *topK) get(key string) (*entry, bool) {
+    e, ok := t.byKey[key]
+    return e, ok
+}
+
+// remove stops tracking key
+func (t *topK) remove(key string) {
+    if e, ok := t.byKey[key]; ok {
+        heap.Remove(&t.entries, e.index)
+        delete(t.byKey, key)
+    }
+}
+
+// decay halves every score, dropping entries that reach zero
+func (t *topK) decay() {
+    kept := t.entries[:0]
+    for _, e := range t.entries {
+        e.score >>= 1
+        if e.score == 0 {
+            delete(t.byKey, e.key)
+            continue
+        }
+        kept = append(kept, e)
+    }
+    t.entries = kept
+    for i, e := range t.entries {
+        e.index = i
+    }
+    heap.Init(&t.entries)
+}
+
+// sorted returns a copy of the tracked entries, highest score first
+func (t *topK) sorted() []entry {
+    out := make([]entry, len(t.entries))
+    for i, e := range t.entries {
+        out[i] = *e
+    }
+    sort.Slice(out, func(i, j int) bool {
+        if out[i].score != out[j].score {
+            return out[i].score > out[j].score
+        }
+        return out[i].key < out[j].key
+    })
+    return out
+}
+
+// entryHeap implements heap.Interface ordered by ascending score
+type entryHeap []*entry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+
+func (h entryHeap) Swap(i, j int) {
+    h[i], h[j] = h[j], h[i]
+    h[i].index = i
+    h[j].index = j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+    e := x.(*entry)
+    e.index = len(*h)
+    *h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() interface{} {
+    old := *h
+    n := len(old)
+    e := old[n-1]
+    old[n-1] = nil
+    *h = old[:n-1]
+    return e
+}
@@ -0,0 +1,30 @@
+package logging
+
+import (
+    "context"
+
+    "go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying logger
+func NewContext(ctx context.Context, logger *zap.Logger) context.Context {
+    return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request-scoped logger stored in ctx, or fallback
+// when the context has none
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+    if logger, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+        return logger
+    }
+    return fallback
+}
+
+// With adds fields to the logger stored in ctx (or fallback) and returns the
+// enriched context together with the new logger
+func With(ctx context.Context, fallback *zap.Logger, fields ...zap.Field) (context.Context, *zap.Logger) {
+    logger := FromContext(ctx, fallback).With(fields...)
+    return NewContext(ctx, logger), logger
+}
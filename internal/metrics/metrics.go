@@ -0,0 +1,412 @@
+package metrics
+
+import (
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/collectors"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+
+    "distributed-cache/internal/cache"
+)
+
+const (
+    namespace = "dcache"
+
+    // OtherNamespace label used once the namespace limit is reached
+    OtherNamespace = cache.OtherNamespace
+    // NoNamespace label for keys without a namespace prefix
+    NoNamespace = cache.NoNamespace
+)
+
+// Metrics holds the Prometheus collectors for the HTTP server and the cache
+type Metrics struct {
+    cache.NopObserver
+
+    registry *prometheus.Registry
+
+    httpRequests *prometheus.CounterVec
+    httpDuration *prometheus.HistogramVec
+
+    cacheOperations *prometheus.CounterVec
+    cacheErrors     *prometheus.CounterVec
+    cacheDuration   *prometheus.HistogramVec
+    cacheHits       *prometheus.CounterVec
+    cacheMisses     *prometheus.CounterVec
+
+    namespaces *namespaceLimiter
+}
+
+// New creates the metrics registry. status is sampled on every scrape to
+// export the connection pool statistics; maxNamespaces bounds the number of
+// distinct namespace label values (0 means unlimited).
+func New(status func() cache.Status, maxNamespaces int) *Metrics {
+    m := &Metrics{
+        registry: prometheus.NewRegistry(),
+        httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Subsystem: "http",
+            Name:      "requests_total",
+            Help:      "Total HTTP requests by method, route and status code.",
+        }, []string{"method", "route", "status"}),
+        httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+            Namespace: namespace,
+            Subsystem: "http",
+            Name:      "request_duration_seconds",
+            Help:      "HTTP request latency by method, route and status code.",
+            Buckets:   prometheus.DefBuckets,
+        }, []string{"method", "route", "status"}),
+        cacheOperations: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Subsystem: "cache",
+            Name:      "operations_total",
+            Help:      "Total cache operations by method and key namespace.",
+        }, []string{"operation", "namespace"}),
+        cacheErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Subsystem: "cache",
+            Name:      "errors_total",
+            Help:      "Failed cache operations by method and key namespace.",
+        }, []string{"operation", "namespace"}),
+        cacheDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+            Namespace: namespace,
+            Subsystem: "cache",
+            Name:      "operation_duration_seconds",
+            Help:      "Cache operation latency by method.",
+            Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+        }, []string{"operation"}),
+        cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Subsystem: "cache",
+            Name:      "hits_total",
+            Help:      "Cache reads that found a live item, by method and key namespace.",
+        }, []string{"operation", "namespace"}),
+        cacheMisses: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace,
+            Subsystem: "cache",
+            Name:      "misses_total",
+            Help:      "Cache reads that found no live item, by method and key namespace.",
+        }, []string{"operation", "namespace"}),
+        namespaces: newNamespaceLimiter(maxNamespaces),
+    }
+
+    m.registry.MustRegister(
+        collectors.NewGoCollector(),
+        collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+        m.httpRequests,
+        m.httpDuration,
+        m.cacheOperations,
+        m.cacheErrors,
+        m.cacheDuration,
+        m.cacheHits,
+        m.cacheMisses,
+    )
+    if status != nil {
+        m.registry.MustRegister(
+            newPoolCollector(status),
+            newBreakerCollector(status),
+            newFallbackCollector(status),
+            newWriteBehindCollector(status),
+        )
+    }
+
+    return m
+}
+
+// Registry returns the underlying registry so other components can add collectors
+func (m *Metrics) Registry() *prometheus.Registry {
+    return m.registry
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus format
+func (m *Metrics) Handler() http.Handler {
+    return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a completed HTTP request
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+    code := strconv.Itoa(status)
+    m.httpRequests.WithLabelValues(method, route, code).Inc()
+    m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveOperation implements cache.Observer
+func (m *Metrics) ObserveOperation(operation, ns string, failed bool, duration time.Duration) {
+    label := m.namespaces.label(ns)
+    m.cacheOperations.WithLabelValues(operation, label).Inc()
+    m.cacheDuration.WithLabelValues(operation).Observe(duration.Seconds())
+    if failed {
+        m.cacheErrors.WithLabelValues(operation, label).Inc()
+    }
+}
+
+// ObserveKeyAccess implements cache.Observer
+func (m *Metrics) ObserveKeyAccess(access cache.KeyAccess) {
+    switch access.Kind {
+    case cache.AccessHit:
+        m.cacheHits.WithLabelValues(access.Operation, m.namespaces.label(access.Namespace)).Inc()
+    case cache.AccessMiss:
+        m.cacheMisses.WithLabelValues(access.Operation, m.namespaces.label(access.Namespace)).Inc()
+    }
+}
+
+// namespaceLimiter caps the cardinality of the namespace label
+type namespaceLimiter struct {
+    mu   sync.RWMutex
+    max  int
+    seen map[string]struct{}
+}
+
+func newNamespaceLimiter(max int) *namespaceLimiter {
+    return &namespaceLimiter{max: max, seen: make(map[string]struct{})}
+}
+
+// label returns ns if it is already tracked or there is room for it
+func (l *namespaceLimiter) label(ns string) string {
+    if ns == "" {
+        return NoNamespace
+    }
+    if l.max <= 0 {
+        return ns
+    }
+
+    l.mu.RLock()
+    _, ok := l.seen[ns]
+    l.mu.RUnlock()
+    if ok {
+        return ns
+    }
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if _, ok := l.seen[ns]; ok {
+        return ns
+    }
+    if len(l.seen) >= l.max {
+        return OtherNamespace
+    }
+    l.seen[ns] = struct{}{}
+    return ns
+}
+
+// poolCollector exports the go-redis connection pool statistics on scrape
+type poolCollector struct {
+    status func() cache.Status
+
+    hits       *prometheus.Desc
+    misses     *prometheus.Desc
+    timeouts   *prometheus.Desc
+    totalConns *prometheus.Desc
+    idleConns  *prometheus.Desc
+    staleConns *prometheus.Desc
+    poolSize   *prometheus.Desc
+}
+
+func newPoolCollector(status func() cache.Status) *poolCollector {
+    desc := func(name, help string) *prometheus.Desc {
+        return prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", name), help, nil, nil)
+    }
+
+    return &poolCollector{
+        status:     status,
+        hits:       desc("hits_total", "Times a free connection was found in the pool."),
+        misses:     desc("misses_total", "Times a free connection was not found in the pool."),
+        timeouts:   desc("timeouts_total", "Times a wait for a pool connection timed out."),
+        totalConns: desc("total_connections", "Connections currently in the pool."),
+        idleConns:  desc("idle_connections", "Idle connections currently in the pool."),
+        staleConns: desc("stale_connections_total", "Stale connections removed from the pool."),
+        poolSize:   desc("max_connections", "Configured maximum pool size."),
+    }
+}
+
+// Describe implements prometheus.Collector
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+    ch <- c.hits
+    ch <- c.misses
+    ch <- c.timeouts
+    ch <- c.totalConns
+    ch <- c.idleConns
+    ch <- c.staleConns
+    ch <- c.poolSize
+}
+
+// Collect implements prometheus.Collector
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+    pool := c.status().Pool
+
+    ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(pool.Hits))
+    ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(pool.Misses))
+    ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(pool.Timeouts))
+    ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(pool.TotalConns))
+    ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(pool.IdleConns))
+    ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(pool.StaleConns))
+    ch <- prometheus.MustNewConstMetric(c.poolSize, prometheus.GaugeValue, float64(pool.PoolSize))
+}
+
+// Values of the circuit breaker state gauge
+var breakerStates = map[string]float64{
+    cache.BreakerClosed:   0,
+    cache.BreakerHalfOpen: 1,
+    cache.BreakerOpen:     2,
+}
+
+// breakerCollector exports the circuit breaker state on scrape; nothing is
+// exported while the breaker is disabled
+type breakerCollector struct {
+    status func() cache.Status
+
+    state    *prometheus.Desc
+    failures *prometheus.Desc
+    rejected *prometheus.Desc
+    dropped  *prometheus.Desc
+    buffered *prometheus.Desc
+}
+
+func newBreakerCollector(status func() cache.Status) *breakerCollector {
+    desc := func(name, help string) *prometheus.Desc {
+        return prometheus.NewDesc(prometheus.BuildFQName(namespace, "circuit_breaker", name), help, nil, nil)
+    }
+
+    return &breakerCollector{
+        status:   status,
+        state:    desc("state", "Circuit breaker state: 0 closed, 1 half-open, 2 open."),
+        failures: desc("consecutive_failures", "Consecutive Redis failures counted by the breaker."),
+        rejected: desc("rejected_total", "Redis commands rejected while the circuit was open."),
+        dropped:  desc("dropped_writes_total", "Writes discarded while the circuit was open."),
+        buffered: desc("buffered_writes", "Writes waiting to be replayed once Redis recovers."),
+    }
+}
+
+// Describe implements prometheus.Collector
+func (c *breakerCollector) Describe(ch chan<- *prometheus.Desc) {
+    ch <- c.state
+    ch <- c.failures
+    ch <- c.rejected
+    ch <- c.dropped
+    ch <- c.buffered
+}
+
+// Collect implements prometheus.Collector
+func (c *breakerCollector) Collect(ch chan<- prometheus.Metric) {
+    breaker := c.status().Breaker
+    if breaker == nil {
+        return
+    }
+
+    ch <- prometheus.MustNewConstMetric(c.state, prometheus.GaugeValue, breakerStates[breaker.State])
+    ch <- prometheus.MustNewConstMetric(c.failures, prometheus.GaugeValue, float64(breaker.Failures))
+    ch <- prometheus.MustNewConstMetric(c.rejected, prometheus.CounterValue, float64(breaker.Rejected))
+    ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(breaker.DroppedWrites))
+    ch <- prometheus.MustNewConstMetric(c.buffered, prometheus.GaugeValue, float64(breaker.BufferedWrites))
+}
+
+// fallbackCollector exports the local fallback store state on scrape; nothing
+// is exported while the fallback is disabled
+type fallbackCollector struct {
+    status func() cache.Status
+
+    items      *prometheus.Desc
+    pending    *prometheus.Desc
+    hits       *prometheus.Desc
+    reconciled *prometheus.Desc
+    conflicts  *prometheus.Desc
+    lost       *prometheus.Desc
+}
+
+func newFallbackCollector(status func() cache.Status) *fallbackCollector {
+    desc := func(name, help string) *prometheus.Desc {
+        return prometheus.NewDesc(prometheus.BuildFQName(namespace, "fallback", name), help, nil, nil)
+    }
+
+    return &fallbackCollector{
+        status:     status,
+        items:      desc("items", "Entries held by the local fallback store."),
+        pending:    desc("pending_writes", "Writes made while Redis was unavailable, awaiting reconciliation."),
+        hits:       desc("hits_total", "Reads served by the local store because Redis was unavailable."),
+        reconciled: desc("reconciled_total", "Pending writes reconciled with Redis."),
+        conflicts:  desc("conflicts_total", "Pending writes whose key was also written to Redis during the outage."),
+        lost:       desc("lost_writes_total", "Pending writes evicted from the local store before reconciliation."),
+    }
+}
+
+// Describe implements prometheus.Collector
+func (c *fallbackCollector) Describe(ch chan<- *prometheus.Desc) {
+    ch <- c.items
+    ch <- c.pending
+    ch <- c.hits
+    ch <- c.reconciled
+    ch <- c.conflicts
+    ch <- c.lost
+}
+
+// Collect implements prometheus.Collector
+func (c *fallbackCollector) Collect(ch chan<- prometheus.Metric) {
+    fallback := c.status().Fallback
+    if fallback == nil {
+        return
+    }
+
+    ch <- prometheus.MustNewConstMetric(c.items, prometheus.GaugeValue, float64(fallback.Items))
+    ch <- prometheus.MustNewConstMetric(c.pending, prometheus.GaugeValue, float64(fallback.PendingWrites))
+    ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(fallback.Hits))
+    ch <- prometheus.MustNewConstMetric(c.reconciled, prometheus.CounterValue, float64(fallback.Reconciled))
+    ch <- prometheus.MustNewConstMetric(c.conflicts, prometheus.CounterValue, float64(fallback.Conflicts))
+    ch <- prometheus.MustNewConstMetric(c.lost, prometheus.CounterValue, float64(fallback.LostWrites))
+}
+
+// writeBehindCollector exports the write-behind queue state on scrape; nothing
+// is exported while write-behind is disabled
+type writeBehindCollector struct {
+    status func() cache.Status
+
+    depth        *prometheus.Desc
+    capacity     *prometheus.Desc
+    flushed      *prometheus.Desc
+    coalesced    *prometheus.Desc
+    writeThrough *prometheus.Desc
+    flushErrors  *prometheus.Desc
+}
+
+func newWriteBehindCollector(status func() cache.Status) *writeBehindCollector {
+    desc := func(name, help string) *prometheus.Desc {
+        return prometheus.NewDesc(prometheus.BuildFQName(namespace, "write_behind", name), help, nil, nil)
+    }
+
+    return &writeBehindCollector{
+        status:       status,
+        depth:        desc("queue_depth", "Writes waiting in the write-behind queue."),
+        capacity:     desc("capacity", "Maximum number of writes the write-behind queue holds."),
+        flushed:      desc("flushed_total", "Queued writes sent to Redis."),
+        coalesced:    desc("coalesced_total", "Queued writes replaced by a newer write to the same key."),
+        writeThrough: desc("write_through_total", "Writes sent directly to Redis because the queue was full."),
+        flushErrors:  desc("flush_errors_total", "Batches that failed to flush and were requeued."),
+    }
+}
+
+// Describe implements prometheus.Collector
+func (c *writeBehindCollector) Describe(ch chan<- *prometheus.Desc) {
+    ch <- c.depth
+    ch <- c.capacity
+    ch <- c.flushed
+    ch <- c.coalesced
+    ch <- c.writeThrough
+    ch <- c.flushErrors
+}
+
+// Collect implements prometheus.Collector
+func (c *writeBehindCollector) Collect(ch chan<- prometheus.Metric) {
+    wb := c.status().WriteBehind
+    if wb == nil {
+        return
+    }
+
+    ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(wb.QueueDepth))
+    ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(wb.Capacity))
+    ch <- prometheus.MustNewConstMetric(c.flushed, prometheus.CounterValue, float64(wb.Flushed))
+    ch <- prometheus.MustNewConstMetric(c.coalesced, prometheus.CounterValue, float64(wb.Coalesced))
+    ch <- prometheus.MustNewConstMetric(c.writeThrough, prometheus.CounterValue, float64(wb.WriteThrough))
+    ch <- prometheus.MustNewConstMetric(c.flushErrors, prometheus.CounterValue, float64(wb.FlushErrors))
+}
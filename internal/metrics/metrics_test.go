@@ -0,0 +1,113 @@
+package metrics
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "github.com/stretchr/testify/assert"
+
+    "distributed-cache/internal/cache"
+)
+
+func TestMetrics_CacheObserver(t *testing.T) {
+    m := New(nil, 0)
+
+    m.ObserveOperation("get", "users", false, time.Millisecond)
+    m.ObserveOperation("get", "users", true, time.Millisecond)
+    m.ObserveKeyAccess(cache.KeyAccess{Operation: "get", Key: "users:1", Namespace: "users", Kind: cache.AccessHit})
+    m.ObserveKeyAccess(cache.KeyAccess{Operation: "get", Key: "users:2", Namespace: "users", Kind: cache.AccessMiss})
+    m.ObserveKeyAccess(cache.KeyAccess{Operation: "get", Key: "plain", Kind: cache.AccessMiss})
+
+    assert.Equal(t, 2.0, testutil.ToFloat64(m.cacheOperations.WithLabelValues("get", "users")))
+    assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheErrors.WithLabelValues("get", "users")))
+    assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheHits.WithLabelValues("get", "users")))
+    assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheMisses.WithLabelValues("get", "users")))
+    assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheMisses.WithLabelValues("get", NoNamespace)))
+}
+
+func TestMetrics_NamespaceLimit(t *testing.T) {
+    m := New(nil, 2)
+
+    for _, ns := range []string{"a", "b", "c", "d", "a"} {
+        m.ObserveOperation("set", ns, false, time.Millisecond)
+    }
+
+    assert.Equal(t, 2.0, testutil.ToFloat64(m.cacheOperations.WithLabelValues("set", "a")))
+    assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheOperations.WithLabelValues("set", "b")))
+    assert.Equal(t, 2.0, testutil.ToFloat64(m.cacheOperations.WithLabelValues("set", OtherNamespace)))
+}
+
+func TestMetrics_Handler(t *testing.T) {
+    status := func() cache.Status {
+        return cache.Status{Pool: cache.PoolStats{Hits: 7, TotalConns: 3, IdleConns: 2, PoolSize: 10}}
+    }
+    m := New(status, 0)
+    m.ObserveRequest(http.MethodGet, "/api/v1/cache/:key", http.StatusOK, 5*time.Millisecond)
+
+    w := httptest.NewRecorder()
+    m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    body := w.Body.String()
+    assert.Contains(t, body, `dcache_http_requests_total{method="GET",route="/api/v1/cache/:key",status="200"} 1`)
+    assert.Contains(t, body, "dcache_redis_pool_hits_total 7")
+    assert.Contains(t, body, "dcache_redis_pool_idle_connections 2")
+    assert.Contains(t, body, "dcache_redis_pool_max_connections 10")
+}
+
+func TestMetrics_CircuitBreaker(t *testing.T) {
+    var breaker *cache.BreakerStatus
+    m := New(func() cache.Status { return cache.Status{Breaker: breaker} }, 0)
+
+    scrape := func() string {
+        w := httptest.NewRecorder()
+        m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+        return w.Body.String()
+    }
+
+    // Nothing is exported while the breaker is disabled
+    assert.NotContains(t, scrape(), "dcache_circuit_breaker_state")
+
+    breaker = &cache.BreakerStatus{State: cache.BreakerOpen, Failures: 5, Rejected: 12, DroppedWrites: 3, BufferedWrites: 4}
+    body := scrape()
+    assert.Contains(t, body, "dcache_circuit_breaker_state 2")
+    assert.Contains(t, body, "dcache_circuit_breaker_consecutive_failures 5")
+    assert.Contains(t, body, "dcache_circuit_breaker_rejected_total 12")
+    assert.Contains(t, body, "dcache_circuit_breaker_dropped_writes_total 3")
+    assert.Contains(t, body, "dcache_circuit_breaker_buffered_writes 4")
+}
+
+func TestMetrics_Fallback(t *testing.T) {
+    status := func() cache.Status {
+        return cache.Status{Fallback: &cache.FallbackStatus{Items: 10, PendingWrites: 2, Hits: 5, Reconciled: 3, Conflicts: 1}}
+    }
+    m := New(status, 0)
+
+    w := httptest.NewRecorder()
+    m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+    body := w.Body.String()
+    assert.Contains(t, body, "dcache_fallback_items 10")
+    assert.Contains(t, body, "dcache_fallback_pending_writes 2")
+    assert.Contains(t, body, "dcache_fallback_hits_total 5")
+    assert.Contains(t, body, "dcache_fallback_conflicts_total 1")
+}
+
+func TestMetrics_WriteBehind(t *testing.T) {
+    status := func() cache.Status {
+        return cache.Status{WriteBehind: &cache.WriteBehindStatus{QueueDepth: 7, Capacity: 100, Flushed: 40, Coalesced: 3}}
+    }
+    m := New(status, 0)
+
+    w := httptest.NewRecorder()
+    m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+    body := w.Body.String()
+    assert.Contains(t, body, "dcache_write_behind_queue_depth 7")
+    assert.Contains(t, body, "dcache_write_behind_capacity 100")
+    assert.Contains(t, body, "dcache_write_behind_flushed_total 40")
+    assert.Contains(t, body, "dcache_write_behind_coalesced_total 3")
+}
@@ -0,0 +1,239 @@
+package middleware
+
+import (
+    "fmt"
+    "net/http"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+    "distributed-cache/internal/logging"
+)
+
+// Permission scopes. Each scope includes the ones below it: admin > write > read.
+const (
+    ScopeRead  = "read"
+    ScopeWrite = "write"
+    ScopeAdmin = "admin"
+)
+
+// principalContextKey is the Gin context key holding the authenticated principal
+const principalContextKey = "Principal"
+
+// scopeLevels orders scopes so that higher scopes include lower ones
+var scopeLevels = map[string]int{
+    ScopeRead:  1,
+    ScopeWrite: 2,
+    ScopeAdmin: 3,
+}
+
+// Principal is the identity a request is authenticated as
+type Principal struct {
+    Name        string
+    Scopes      []string
+    KeyPrefixes []string        // Allowed key prefixes, empty means every key
+    RateLimit   cache.RateLimit // Own rate limit, overrides the per API key default
+}
+
+// HasScope reports whether the principal was granted scope
+func (p *Principal) HasScope(scope string) bool {
+    required := scopeLevels[scope]
+    for _, granted := range p.Scopes {
+        if level, ok := scopeLevels[granted]; ok && level >= required {
+            return true
+        }
+    }
+    return false
+}
+
+// CanAccessKey reports whether the key falls inside the principal's prefixes.
+// Internal subsystem keys are never accessible through the key-value API.
+func (p *Principal) CanAccessKey(key string) bool {
+    if cache.IsInternalKey(key) {
+        return false
+    }
+    if len(p.KeyPrefixes) == 0 {
+        return true
+    }
+    for _, prefix := range p.KeyPrefixes {
+        if strings.HasPrefix(key, prefix) {
+            return true
+        }
+    }
+    return false
+}
+
+// CanAccessPattern reports whether every key matched by a glob pattern falls
+// inside the principal's prefixes
+func (p *Principal) CanAccessPattern(pattern string) bool {
+    if len(p.KeyPrefixes) == 0 {
+        return true
+    }
+    literal := pattern
+    if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+        literal = pattern[:i]
+    }
+    return p.CanAccessKey(literal)
+}
+
+// PrincipalFromContext returns the authenticated principal, if any
+func PrincipalFromContext(c *gin.Context) (*Principal, bool) {
+    value, ok := c.Get(principalContextKey)
+    if !ok {
+        return nil, false
+    }
+    principal, ok := value.(*Principal)
+    return principal, ok
+}
+
+// Authenticate middleware validates API keys sent in the X-API-Key header
+// and, when enabled, JWT bearer tokens. When authentication is disabled every
+// request runs as an unrestricted anonymous principal.
+func Authenticate(cfg config.AuthConfig, logger *zap.Logger) (gin.HandlerFunc, error) {
+    if !cfg.Enabled {
+        anonymous := &Principal{Name: "anonymous", Scopes: []string{ScopeAdmin}}
+        return func(c *gin.Context) {
+            setPrincipal(c, anonymous, logger)
+            c.Next()
+        }, nil
+    }
+
+    keys := cfg.APIKeys
+    if cfg.APIKeysFile != "" {
+        fileKeys, err := config.LoadAPIKeys(cfg.APIKeysFile)
+        if err != nil {
+            return nil, err
+        }
+        keys = append(keys, fileKeys...)
+    }
+
+    principals, err := buildPrincipals(keys)
+    if err != nil {
+        return nil, err
+    }
+
+    var verifier *jwtVerifier
+    if cfg.JWT.Enabled {
+        verifier, err = newJWTVerifier(cfg.JWT, logger)
+        if err != nil {
+            return nil, fmt.Errorf("failed to configure JWT authentication: %w", err)
+        }
+    }
+
+    logger.Info("authentication enabled",
+        zap.Int("api_keys", len(principals)),
+        zap.Bool("jwt", verifier != nil))
+
+    return func(c *gin.Context) {
+        if token, ok := bearerToken(c); ok && verifier != nil {
+            principal, err := verifier.verify(token)
+            if err != nil {
+                logging.FromContext(c.Request.Context(), logger).Warn("invalid bearer token", zap.Error(err))
+                AbortUnauthorized(c, "invalid bearer token")
+                return
+            }
+
+            setPrincipal(c, principal, logger)
+            c.Next()
+            return
+        }
+
+        apiKey := c.GetHeader(APIKeyHeader)
+        if apiKey == "" {
+            AbortUnauthorized(c, "missing credentials")
+            return
+        }
+
+        // Keys are stored hashed, so the lookup never touches the plain key
+        principal, ok := principals[hashAPIKey(apiKey)]
+        if !ok {
+            logging.FromContext(c.Request.Context(), logger).Warn("invalid API key")
+            AbortUnauthorized(c, "invalid API key")
+            return
+        }
+
+        setPrincipal(c, principal, logger)
+        c.Next()
+    }, nil
+}
+
+// setPrincipal stores the authenticated principal and tags the request logger with it
+func setPrincipal(c *gin.Context, principal *Principal, logger *zap.Logger) {
+    c.Set(principalContextKey, principal)
+    ctx, _ := logging.With(c.Request.Context(), logger, zap.String("principal", principal.Name))
+    c.Request = c.Request.WithContext(ctx)
+}
+
+// resourceParams are the route parameters that name a key-like resource
+// (cache keys, lock names, rate limit buckets, job topics, pub/sub channels)
+var resourceParams = []string{"key", "name", "bucket", "topic", "channel"}
+
+// RequireScope middleware rejects requests whose principal lacks scope or
+// whose routed resource names fall outside the principal's prefixes
+func RequireScope(scope string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        principal, ok := PrincipalFromContext(c)
+        if !ok {
+            AbortUnauthorized(c, "authentication required")
+            return
+        }
+
+        if !principal.HasScope(scope) {
+            AbortForbidden(c, "insufficient scope, requires "+scope)
+            return
+        }
+
+        for _, param := range resourceParams {
+            if name := c.Param(param); name != "" && !principal.CanAccessKey(name) {
+                AbortForbidden(c, "access to "+param+" is not allowed")
+                return
+            }
+        }
+
+        c.Next()
+    }
+}
+
+// AbortUnauthorized ends the request with a 401 response
+func AbortUnauthorized(c *gin.Context, message string) {
+    c.Header("WWW-Authenticate", fmt.Sprintf("APIKey header=%q, Bearer", APIKeyHeader))
+    c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+}
+
+// AbortForbidden ends the request with a 403 response
+func AbortForbidden(c *gin.Context, message string) {
+    c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": message})
+}
+
+// buildPrincipals indexes the configured API keys by hash
+func buildPrincipals(keys []config.APIKeyConfig) (map[string]*Principal, error) {
+    principals := make(map[string]*Principal, len(keys))
+    for _, key := range keys {
+        hash := strings.ToLower(strings.TrimPrefix(key.KeyHash, "sha256:"))
+        if len(hash) != 64 {
+            return nil, fmt.Errorf("invalid key hash for API key %q: expected hex SHA-256", key.Name)
+        }
+
+        for _, scope := range key.Scopes {
+            if _, ok := scopeLevels[scope]; !ok {
+                return nil, fmt.Errorf("unknown scope %q for API key %q", scope, key.Name)
+            }
+        }
+
+        prefixes := append([]string{}, key.KeyPrefixes...)
+        for _, namespace := range key.Namespaces {
+            prefixes = append(prefixes, namespace+cache.NamespaceSeparator)
+        }
+
+        principals[hash] = &Principal{
+            Name:        key.Name,
+            Scopes:      key.Scopes,
+            KeyPrefixes: prefixes,
+            RateLimit:   key.RateLimit,
+        }
+    }
+    return principals, nil
+}
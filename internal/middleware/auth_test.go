@@ -0,0 +1,95 @@
+package middleware
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap/zaptest"
+
+    "distributed-cache/internal/config"
+)
+
+func setupAuthRouter(t *testing.T, cfg config.AuthConfig) *gin.Engine {
+    authenticate, err := Authenticate(cfg, zaptest.NewLogger(t))
+    require.NoError(t, err)
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+
+    ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+    api := router.Group("/api/v1", authenticate)
+    api.GET("/cache/:key", RequireScope(ScopeRead), ok)
+    api.PUT("/cache/:key", RequireScope(ScopeWrite), ok)
+    api.DELETE("/cache/", RequireScope(ScopeAdmin), ok)
+    api.POST("/locks/:name", RequireScope(ScopeWrite), ok)
+    api.POST("/ratelimit/:bucket/take", RequireScope(ScopeWrite), ok)
+
+    return router
+}
+
+func TestAuthenticate_APIKeys(t *testing.T) {
+    router := setupAuthRouter(t, config.AuthConfig{
+        Enabled: true,
+        APIKeys: []config.APIKeyConfig{
+            {Name: "reader", KeyHash: hashAPIKey("reader-key"), Scopes: []string{ScopeRead}, Namespaces: []string{"users"}},
+            {Name: "writer", KeyHash: hashAPIKey("writer-key"), Scopes: []string{ScopeWrite}, Namespaces: []string{"users"}},
+            {Name: "ops", KeyHash: "sha256:" + hashAPIKey("ops-key"), Scopes: []string{ScopeAdmin}},
+        },
+    })
+
+    tests := []struct {
+        name   string
+        method string
+        path   string
+        apiKey string
+        status int
+    }{
+        {"sin credenciales", "GET", "/api/v1/cache/users:1", "", http.StatusUnauthorized},
+        {"key inválida", "GET", "/api/v1/cache/users:1", "wrong", http.StatusUnauthorized},
+        {"lectura permitida", "GET", "/api/v1/cache/users:1", "reader-key", http.StatusOK},
+        {"namespace no permitido", "GET", "/api/v1/cache/orders:1", "reader-key", http.StatusForbidden},
+        {"scope insuficiente", "PUT", "/api/v1/cache/users:1", "reader-key", http.StatusForbidden},
+        {"admin incluye write", "PUT", "/api/v1/cache/orders:1", "ops-key", http.StatusOK},
+        {"lock en namespace permitido", "POST", "/api/v1/locks/users:1", "writer-key", http.StatusOK},
+        {"lock fuera del namespace", "POST", "/api/v1/locks/orders:1", "writer-key", http.StatusForbidden},
+        {"bucket fuera del namespace", "POST", "/api/v1/ratelimit/orders/take", "writer-key", http.StatusForbidden},
+        {"claves internas prohibidas", "GET", "/api/v1/cache/_internal:lock:{a}", "ops-key", http.StatusForbidden},
+        {"flush solo admin", "DELETE", "/api/v1/cache/", "reader-key", http.StatusForbidden},
+        {"flush con admin", "DELETE", "/api/v1/cache/", "ops-key", http.StatusOK},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(tt.method, tt.path, nil)
+            if tt.apiKey != "" {
+                req.Header.Set(APIKeyHeader, tt.apiKey)
+            }
+
+            w := httptest.NewRecorder()
+            router.ServeHTTP(w, req)
+            assert.Equal(t, tt.status, w.Code)
+        })
+    }
+}
+
+func TestAuthenticate_Disabled(t *testing.T) {
+    router := setupAuthRouter(t, config.AuthConfig{Enabled: false})
+
+    req := httptest.NewRequest("DELETE", "/api/v1/cache/", nil)
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPrincipal_CanAccessPattern(t *testing.T) {
+    principal := &Principal{KeyPrefixes: []string{"users:"}}
+
+    assert.True(t, principal.CanAccessPattern("users:*"))
+    assert.True(t, principal.CanAccessPattern("users:42"))
+    assert.False(t, principal.CanAccessPattern("*"))
+    assert.False(t, principal.CanAccessPattern("user*"))
+}
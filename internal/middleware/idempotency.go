@@ -0,0 +1,216 @@
+package middleware
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "io"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+    "distributed-cache/internal/logging"
+    "distributed-cache/pkg/models"
+)
+
+const (
+    // IdempotencyKeyHeader is the header carrying the client idempotency key
+    IdempotencyKeyHeader = "Idempotency-Key"
+    // IdempotentReplayedHeader marks responses replayed from a stored result
+    IdempotentReplayedHeader = "Idempotent-Replayed"
+
+    maxIdempotencyKeyLength = 255
+    idempotencyPollInterval = 50 * time.Millisecond
+)
+
+// unreplayedHeaders are response headers that describe the original request
+// rather than its result, so they are not stored
+var unreplayedHeaders = []string{
+    "X-Request-Id",
+    "X-Ratelimit-Limit",
+    "X-Ratelimit-Remaining",
+    "X-Ratelimit-Reset",
+    "Retry-After",
+}
+
+// recordingWriter keeps a copy of the response body
+type recordingWriter struct {
+    gin.ResponseWriter
+    body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+    w.body.Write(data)
+    return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+    w.body.WriteString(s)
+    return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency middleware runs requests carrying an Idempotency-Key header
+// at most once. The first request claims the key and its response is
+// stored; concurrent duplicates wait up to WaitTimeout and then get 409,
+// later duplicates get the stored response replayed. Keys are scoped to the
+// authenticated principal, so it must run after Authenticate.
+func Idempotency(store cache.IdempotencyStore, cfg config.IdempotencyConfig, logger *zap.Logger) gin.HandlerFunc {
+    methods := make(map[string]bool, len(cfg.Methods))
+    for _, method := range cfg.Methods {
+        methods[strings.ToUpper(method)] = true
+    }
+
+    return func(c *gin.Context) {
+        key := c.GetHeader(IdempotencyKeyHeader)
+        if !cfg.Enabled || key == "" || !methods[c.Request.Method] {
+            c.Next()
+            return
+        }
+        if len(key) > maxIdempotencyKeyLength {
+            c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "idempotency key is too long"})
+            return
+        }
+
+        log := logging.FromContext(c.Request.Context(), logger).With(zap.String("idempotency_key", key))
+
+        body, err := io.ReadAll(c.Request.Body)
+        if err != nil {
+            c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+            return
+        }
+        c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+        scoped := IdempotencyScope(c, "http", key)
+        fingerprint := requestFingerprint(c.Request, body)
+        owner := randomString(32)
+
+        record, claimed, err := claimOrWait(c.Request.Context(), store, scoped, owner, fingerprint, cfg)
+        if err != nil {
+            // Fail closed: running the request without the claim could apply it twice
+            log.Error("idempotency store unavailable", zap.Error(err))
+            c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "idempotency store unavailable"})
+            return
+        }
+
+        if !claimed {
+            switch {
+            case record.Fingerprint != fingerprint:
+                c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "idempotency key was used with a different request"})
+            case record.Completed():
+                log.Debug("replaying idempotent response")
+                replay(c, record.Response)
+            default:
+                c.Header("Retry-After", strconv.FormatInt(retryAfter(record), 10))
+                c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this idempotency key is in progress"})
+            }
+            return
+        }
+
+        writer := &recordingWriter{ResponseWriter: c.Writer}
+        c.Writer = writer
+
+        // The claim outlives the request context, which is canceled once the
+        // response is sent
+        ctx := context.WithoutCancel(c.Request.Context())
+        completed := false
+        defer func() {
+            if completed {
+                return
+            }
+            // Failed requests release the key so the client can retry them
+            if err := store.ReleaseIdempotencyKey(ctx, scoped, owner); err != nil {
+                log.Warn("failed to release idempotency key", zap.Error(err))
+            }
+        }()
+
+        c.Next()
+
+        if writer.Status() >= http.StatusInternalServerError {
+            return
+        }
+
+        response := &models.IdempotentResponse{
+            StatusCode: writer.Status(),
+            Header:     replayableHeader(writer.Header()),
+            Body:       writer.body.Bytes(),
+        }
+        if _, err := store.CompleteIdempotencyKey(ctx, scoped, owner, response, cfg.TTL); err != nil {
+            log.Warn("failed to store idempotent response", zap.Error(err))
+            return
+        }
+        completed = true
+    }
+}
+
+// claimOrWait claims key, polling while another request holds it for up to
+// cfg.WaitTimeout
+func claimOrWait(ctx context.Context, store cache.IdempotencyStore, key, owner, fingerprint string, cfg config.IdempotencyConfig) (*models.IdempotencyRecord, bool, error) {
+    deadline := time.Now().Add(cfg.WaitTimeout)
+    for {
+        record, claimed, err := store.ClaimIdempotencyKey(ctx, key, owner, fingerprint, cfg.LockTTL)
+        if err != nil || claimed || record.Completed() || record.Fingerprint != fingerprint || !time.Now().Before(deadline) {
+            return record, claimed, err
+        }
+
+        select {
+        case <-ctx.Done():
+            return record, false, nil
+        case <-time.After(idempotencyPollInterval):
+        }
+    }
+}
+
+// IdempotencyScope prefixes key with namespace and the principal so clients
+// cannot see each other's responses. The principal name is length-prefixed,
+// so names and keys containing ":" cannot produce the same scoped key.
+func IdempotencyScope(c *gin.Context, namespace, key string) string {
+    name := "anonymous"
+    if principal, ok := PrincipalFromContext(c); ok && principal.Name != "" {
+        name = principal.Name
+    }
+    return namespace + ":" + strconv.Itoa(len(name)) + ":" + name + ":" + key
+}
+
+// requestFingerprint identifies the method, URI and body of a request
+func requestFingerprint(r *http.Request, body []byte) string {
+    hash := sha256.New()
+    hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+    hash.Write(body)
+    return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayableHeader copies the response headers worth replaying
+func replayableHeader(header http.Header) map[string][]string {
+    stored := header.Clone()
+    for _, name := range unreplayedHeaders {
+        stored.Del(name)
+    }
+    return stored
+}
+
+// replay writes a stored response and stops the chain
+func replay(c *gin.Context, response *models.IdempotentResponse) {
+    for name, values := range response.Header {
+        c.Writer.Header()[name] = values
+    }
+    c.Header(IdempotentReplayedHeader, "true")
+    c.Status(response.StatusCode)
+    c.Writer.Write(response.Body)
+    c.Abort()
+}
+
+// retryAfter returns the seconds until an in-progress claim expires
+func retryAfter(record *models.IdempotencyRecord) int64 {
+    seconds := ceilSeconds(time.Until(record.ExpiresAt))
+    if seconds < 1 {
+        return 1
+    }
+    return seconds
+}
@@ -0,0 +1,371 @@
+package middleware
+
+import (
+    "context"
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rsa"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "math/big"
+    "net/http"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/golang-jwt/jwt/v5"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+)
+
+// jwksMinReload limits how often an unknown key ID can trigger a JWKS reload
+const jwksMinReload = 1 * time.Minute
+
+// allNamespaces in the namespaces claim grants access to every key
+const allNamespaces = "*"
+
+// jwtVerifier validates bearer tokens and maps their claims to a principal
+type jwtVerifier struct {
+    cfg    config.JWTConfig
+    jwks   *jwksCache
+    parser *jwt.Parser
+}
+
+// newJWTVerifier creates a verifier, loading the JWKS for the first time
+func newJWTVerifier(cfg config.JWTConfig, logger *zap.Logger) (*jwtVerifier, error) {
+    jwks, err := newJWKSCache(cfg, logger)
+    if err != nil {
+        return nil, err
+    }
+
+    options := []jwt.ParserOption{
+        jwt.WithValidMethods(cfg.Algorithms),
+        jwt.WithLeeway(cfg.Leeway),
+        jwt.WithExpirationRequired(),
+    }
+    if cfg.Issuer != "" {
+        options = append(options, jwt.WithIssuer(cfg.Issuer))
+    }
+    if cfg.Audience != "" {
+        options = append(options, jwt.WithAudience(cfg.Audience))
+    }
+
+    return &jwtVerifier{
+        cfg:    cfg,
+        jwks:   jwks,
+        parser: jwt.NewParser(options...),
+    }, nil
+}
+
+// verify validates a raw token and returns the principal it represents
+func (v *jwtVerifier) verify(raw string) (*Principal, error) {
+    claims := jwt.MapClaims{}
+    _, err := v.parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+        kid, _ := token.Header["kid"].(string)
+        return v.jwks.key(kid)
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    principal := &Principal{Name: "jwt"}
+    if subject, ok := claims[v.cfg.SubjectClaim].(string); ok && subject != "" {
+        principal.Name = subject
+    }
+
+    for _, scope := range claimStrings(claims[v.cfg.ScopesClaim]) {
+        if !strings.HasPrefix(scope, v.cfg.ScopePrefix) {
+            continue
+        }
+        scope = strings.TrimPrefix(scope, v.cfg.ScopePrefix)
+        if _, ok := scopeLevels[scope]; ok {
+            principal.Scopes = append(principal.Scopes, scope)
+        }
+    }
+
+    if v.cfg.NamespacesClaim != "" {
+        namespaces := claimStrings(claims[v.cfg.NamespacesClaim])
+        if len(namespaces) == 0 {
+            // Without allowed namespaces the token grants no cache access
+            principal.Scopes = nil
+        }
+        for _, namespace := range namespaces {
+            if namespace == allNamespaces {
+                principal.KeyPrefixes = nil
+                break
+            }
+            principal.KeyPrefixes = append(principal.KeyPrefixes, namespace+cache.NamespaceSeparator)
+        }
+    }
+
+    return principal, nil
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header
+func bearerToken(c *gin.Context) (string, bool) {
+    header := c.GetHeader("Authorization")
+    scheme, token, found := strings.Cut(header, " ")
+    if !found || !strings.EqualFold(scheme, "Bearer") {
+        return "", false
+    }
+    token = strings.TrimSpace(token)
+    return token, token != ""
+}
+
+// claimStrings reads a claim holding either a space separated string or a list
+func claimStrings(value interface{}) []string {
+    switch v := value.(type) {
+    case string:
+        return strings.Fields(v)
+    case []interface{}:
+        values := make([]string, 0, len(v))
+        for _, item := range v {
+            if s, ok := item.(string); ok {
+                values = append(values, s)
+            }
+        }
+        return values
+    default:
+        return nil
+    }
+}
+
+// jwksCache holds the signing keys of a JWKS document loaded from a file or URL
+type jwksCache struct {
+    file    string
+    url     string
+    refresh time.Duration
+    client  *http.Client
+    logger  *zap.Logger
+
+    mu          sync.RWMutex
+    keys        map[string]crypto.PublicKey
+    loadedAt    time.Time
+    lastAttempt time.Time
+}
+
+// newJWKSCache creates the cache and performs the initial load. A local file
+// must load; a remote JWKS that is unreachable is retried on demand.
+func newJWKSCache(cfg config.JWTConfig, logger *zap.Logger) (*jwksCache, error) {
+    if cfg.JWKSFile == "" && cfg.JWKSURL == "" {
+        return nil, errors.New("JWT authentication requires jwks_file or jwks_url")
+    }
+
+    jwks := &jwksCache{
+        file:    cfg.JWKSFile,
+        url:     cfg.JWKSURL,
+        refresh: cfg.JWKSRefresh,
+        client:  &http.Client{Timeout: 10 * time.Second},
+        logger:  logger,
+        keys:    make(map[string]crypto.PublicKey),
+    }
+
+    if err := jwks.reload(); err != nil {
+        if jwks.file != "" {
+            return nil, err
+        }
+        logger.Warn("failed to load JWKS, will retry on demand", zap.Error(err), zap.String("url", jwks.url))
+    }
+
+    return jwks, nil
+}
+
+// key returns the public key for a key ID, reloading the JWKS when it is
+// stale or the key ID is unknown (signing key rotation)
+func (j *jwksCache) key(kid string) (crypto.PublicKey, error) {
+    j.mu.RLock()
+    key, ok := j.lookup(kid)
+    stale := j.refresh > 0 && time.Since(j.loadedAt) > j.refresh
+    canReload := time.Since(j.lastAttempt) > jwksMinReload
+    j.mu.RUnlock()
+
+    if (stale || !ok) && canReload {
+        if err := j.reload(); err != nil {
+            j.logger.Warn("failed to reload JWKS", zap.Error(err))
+        }
+        j.mu.RLock()
+        key, ok = j.lookup(kid)
+        j.mu.RUnlock()
+    }
+
+    if !ok {
+        return nil, fmt.Errorf("unknown signing key %q", kid)
+    }
+    return key, nil
+}
+
+// lookup finds a key by ID; tokens without kid match a single-key JWKS.
+// Callers must hold the lock.
+func (j *jwksCache) lookup(kid string) (crypto.PublicKey, bool) {
+    if kid == "" && len(j.keys) == 1 {
+        for _, key := range j.keys {
+            return key, true
+        }
+    }
+    key, ok := j.keys[kid]
+    return key, ok
+}
+
+// reload fetches and parses the JWKS document
+func (j *jwksCache) reload() error {
+    j.mu.Lock()
+    j.lastAttempt = time.Now()
+    j.mu.Unlock()
+
+    data, err := j.fetch()
+    if err != nil {
+        return err
+    }
+
+    keys, err := parseJWKS(data)
+    if err != nil {
+        return err
+    }
+
+    j.mu.Lock()
+    j.keys = keys
+    j.loadedAt = time.Now()
+    j.mu.Unlock()
+
+    j.logger.Debug("JWKS loaded", zap.Int("keys", len(keys)))
+    return nil
+}
+
+// fetch reads the raw JWKS document
+func (j *jwksCache) fetch() ([]byte, error) {
+    if j.file != "" {
+        data, err := os.ReadFile(j.file)
+        if err != nil {
+            return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+        }
+        return data, nil
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+    }
+
+    resp, err := j.client.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+    }
+
+    data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+    if err != nil {
+        return nil, fmt.Errorf("failed to read JWKS response: %w", err)
+    }
+    return data, nil
+}
+
+// jsonWebKey is the subset of RFC 7517 fields used for signature keys
+type jsonWebKey struct {
+    Kty string `json:"kty"`
+    Kid string `json:"kid"`
+    Use string `json:"use"`
+    N   string `json:"n"`
+    E   string `json:"e"`
+    Crv string `json:"crv"`
+    X   string `json:"x"`
+    Y   string `json:"y"`
+}
+
+// parseJWKS parses the RSA and EC signature keys of a JWKS document
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+    var document struct {
+        Keys []jsonWebKey `json:"keys"`
+    }
+    if err := json.Unmarshal(data, &document); err != nil {
+        return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+    }
+
+    keys := make(map[string]crypto.PublicKey, len(document.Keys))
+    for _, jwk := range document.Keys {
+        if jwk.Use != "" && jwk.Use != "sig" {
+            continue
+        }
+
+        var key crypto.PublicKey
+        var err error
+        switch jwk.Kty {
+        case "RSA":
+            key, err = parseRSAKey(jwk)
+        case "EC":
+            key, err = parseECKey(jwk)
+        default:
+            continue
+        }
+        if err != nil {
+            return nil, fmt.Errorf("invalid JWKS key %q: %w", jwk.Kid, err)
+        }
+        keys[jwk.Kid] = key
+    }
+
+    return keys, nil
+}
+
+// parseRSAKey builds an RSA public key from its modulus and exponent
+func parseRSAKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+    n, err := decodeBigInt(jwk.N)
+    if err != nil {
+        return nil, err
+    }
+    e, err := decodeBigInt(jwk.E)
+    if err != nil {
+        return nil, err
+    }
+    if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+        return nil, errors.New("invalid RSA exponent")
+    }
+    return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// parseECKey builds an ECDSA public key from its curve coordinates
+func parseECKey(jwk jsonWebKey) (*ecdsa.PublicKey, error) {
+    var curve elliptic.Curve
+    switch jwk.Crv {
+    case "P-256":
+        curve = elliptic.P256()
+    case "P-384":
+        curve = elliptic.P384()
+    case "P-521":
+        curve = elliptic.P521()
+    default:
+        return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+    }
+
+    x, err := decodeBigInt(jwk.X)
+    if err != nil {
+        return nil, err
+    }
+    y, err := decodeBigInt(jwk.Y)
+    if err != nil {
+        return nil, err
+    }
+    return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+    data, err := base64.RawURLEncoding.DecodeString(value)
+    if err != nil {
+        return nil, fmt.Errorf("invalid base64url value: %w", err)
+    }
+    return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/rsa"
+    "encoding/base64"
+    "encoding/json"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "distributed-cache/internal/config"
+)
+
+// testJWKS genera claves de firma RSA y EC y su documento JWKS
+func testJWKS(t *testing.T) (*rsa.PrivateKey, *ecdsa.PrivateKey, []byte) {
+    rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+    require.NoError(t, err)
+    ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    require.NoError(t, err)
+
+    encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+    document, err := json.Marshal(map[string]interface{}{
+        "keys": []map[string]string{
+            {"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+            {"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+        },
+    })
+    require.NoError(t, err)
+
+    return rsaKey, ecKey, document
+}
+
+func testJWTConfig() config.JWTConfig {
+    return config.JWTConfig{
+        Enabled:         true,
+        JWKSRefresh:     1 * time.Hour,
+        Issuer:          "https://issuer.example.com",
+        Audience:        "distributed-cache",
+        Algorithms:      []string{"RS256", "ES256"},
+        SubjectClaim:    "sub",
+        ScopesClaim:     "scope",
+        ScopePrefix:     "cache:",
+        NamespacesClaim: "cache_namespaces",
+    }
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+    base := jwt.MapClaims{
+        "iss": "https://issuer.example.com",
+        "aud": "distributed-cache",
+        "sub": "billing-service",
+        "exp": time.Now().Add(5 * time.Minute).Unix(),
+    }
+    for k, v := range claims {
+        base[k] = v
+    }
+
+    token := jwt.NewWithClaims(method, base)
+    token.Header["kid"] = kid
+    signed, err := token.SignedString(key)
+    require.NoError(t, err)
+    return signed
+}
+
+func TestAuthenticate_JWT(t *testing.T) {
+    rsaKey, ecKey, document := testJWKS(t)
+
+    jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+    require.NoError(t, os.WriteFile(jwksFile, document, 0o600))
+
+    jwtConfig := testJWTConfig()
+    jwtConfig.JWKSFile = jwksFile
+    router := setupAuthRouter(t, config.AuthConfig{Enabled: true, JWT: jwtConfig})
+
+    reader := signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, jwt.MapClaims{
+        "scope":            "openid cache:read",
+        "cache_namespaces": []string{"billing"},
+    })
+    writer := signToken(t, jwt.SigningMethodES256, "ec-1", ecKey, jwt.MapClaims{
+        "scope":            []string{"cache:write"},
+        "cache_namespaces": "*",
+    })
+    noNamespaces := signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, jwt.MapClaims{"scope": "cache:admin"})
+    expired := signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, jwt.MapClaims{
+        "scope":            "cache:read",
+        "cache_namespaces": "billing",
+        "exp":              time.Now().Add(-1 * time.Hour).Unix(),
+    })
+    wrongIssuer := signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, jwt.MapClaims{
+        "iss":              "https://other.example.com",
+        "scope":            "cache:read",
+        "cache_namespaces": "billing",
+    })
+    hmac := signToken(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), jwt.MapClaims{"scope": "cache:admin"})
+
+    tests := []struct {
+        name   string
+        method string
+        path   string
+        token  string
+        status int
+    }{
+        {"lectura en su namespace", "GET", "/api/v1/cache/billing:1", reader, http.StatusOK},
+        {"lectura fuera del namespace", "GET", "/api/v1/cache/users:1", reader, http.StatusForbidden},
+        {"escritura sin scope", "PUT", "/api/v1/cache/billing:1", reader, http.StatusForbidden},
+        {"escritura con ES256", "PUT", "/api/v1/cache/users:1", writer, http.StatusOK},
+        {"sin claim de namespaces", "GET", "/api/v1/cache/billing:1", noNamespaces, http.StatusForbidden},
+        {"token expirado", "GET", "/api/v1/cache/billing:1", expired, http.StatusUnauthorized},
+        {"emisor incorrecto", "GET", "/api/v1/cache/billing:1", wrongIssuer, http.StatusUnauthorized},
+        {"algoritmo no permitido", "GET", "/api/v1/cache/billing:1", hmac, http.StatusUnauthorized},
+        {"token malformado", "GET", "/api/v1/cache/billing:1", "not-a-token", http.StatusUnauthorized},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(tt.method, tt.path, nil)
+            req.Header.Set("Authorization", "Bearer "+tt.token)
+
+            w := httptest.NewRecorder()
+            router.ServeHTTP(w, req)
+            assert.Equal(t, tt.status, w.Code)
+        })
+    }
+}
+
+func TestAuthenticate_JWKSFromURL(t *testing.T) {
+    rsaKey, _, document := testJWKS(t)
+
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        w.Write(document)
+    }))
+    defer server.Close()
+
+    jwtConfig := testJWTConfig()
+    jwtConfig.JWKSURL = server.URL
+    router := setupAuthRouter(t, config.AuthConfig{Enabled: true, JWT: jwtConfig})
+
+    token := signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, jwt.MapClaims{
+        "scope":            "cache:read",
+        "cache_namespaces": "billing",
+    })
+
+    req := httptest.NewRequest("GET", "/api/v1/cache/billing:1", nil)
+    req.Header.Set("Authorization", "Bearer "+token)
+
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+    "time"
+
+    "github.com/gin-gonic/gin"
+
+    "distributed-cache/internal/metrics"
+)
+
+// unmatchedRoute label for requests that did not match any route
+const unmatchedRoute = "unmatched"
+
+// Metrics middleware records request count and latency per route and status
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        start := time.Now()
+        c.Next()
+
+        // Use the route template to keep label cardinality bounded
+        route := c.FullPath()
+        if route == "" {
+            route = unmatchedRoute
+        }
+        m.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+    }
+}
@@ -1,32 +1,51 @@
 package middleware
 
 import (
+    "crypto/rand"
     "time"
 
     "github.com/gin-gonic/gin"
+    "go.opentelemetry.io/otel/trace"
     "go.uber.org/zap"
+
+    "distributed-cache/internal/logging"
 )
 
-// Logger middleware for request logging
+// Logger middleware stores a request-scoped logger in the request context,
+// tagged with the request ID, client IP and trace ID, and logs each request
+// when it completes. It must run after RequestID and Tracing.
 func Logger(logger *zap.Logger) gin.HandlerFunc {
-    return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-        logger.Info("HTTP Request",
-            zap.String("client_ip", param.ClientIP),
-            zap.String("method", param.Method),
-            zap.String("path", param.Path),
-            zap.Int("status_code", param.StatusCode),
-            zap.Duration("latency", param.Latency),
-            zap.String("user_agent", param.Request.UserAgent()),
+    return func(c *gin.Context) {
+        start := time.Now()
+
+        fields := []zap.Field{
+            zap.String("request_id", c.GetString(RequestIDKey)),
+            zap.String("client", c.ClientIP()),
+        }
+        if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.HasTraceID() {
+            fields = append(fields, zap.String("trace_id", spanContext.TraceID().String()))
+        }
+        ctx, _ := logging.With(c.Request.Context(), logger, fields...)
+        c.Request = c.Request.WithContext(ctx)
+
+        c.Next()
+
+        // Re-read the logger so fields added downstream (e.g. principal) are included
+        logging.FromContext(c.Request.Context(), logger).Info("HTTP Request",
+            zap.String("method", c.Request.Method),
+            zap.String("path", c.Request.URL.Path),
+            zap.Int("status_code", c.Writer.Status()),
+            zap.Duration("latency", time.Since(start)),
+            zap.String("user_agent", c.Request.UserAgent()),
         )
-        return ""
-    })
+    }
 }
 
 // CORS middleware
 func CORS() gin.HandlerFunc {
     return func(c *gin.Context) {
         c.Header("Access-Control-Allow-Origin", "*")
-        c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, HEAD, OPTIONS")
+        c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS")
         c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
         c.Header("Access-Control-Allow-Credentials", "true")
 
@@ -42,7 +61,7 @@ func CORS() gin.HandlerFunc {
 // Recovery middleware personalizado
 func Recovery(logger *zap.Logger) gin.HandlerFunc {
     return gin.RecoveryWithWriter(gin.DefaultWriter, func(c *gin.Context, recovered interface{}) {
-        logger.Error("Panic recovered",
+        logging.FromContext(c.Request.Context(), logger).Error("Panic recovered",
             zap.Any("error", recovered),
             zap.String("path", c.Request.URL.Path),
             zap.String("method", c.Request.Method),
@@ -51,13 +70,8 @@ func Recovery(logger *zap.Logger) gin.HandlerFunc {
     })
 }
 
-// RateLimiter middleware básico (en producción usar Redis)
-func RateLimiter() gin.HandlerFunc {
-    return func(c *gin.Context) {
-        // Implementación básica - en producción usar una solución más robusta
-        c.Next()
-    }
-}
+// RequestIDKey clave del request ID en el contexto de Gin
+const RequestIDKey = "RequestID"
 
 // RequestID middleware para trazabilidad
 func RequestID() gin.HandlerFunc {
@@ -67,7 +81,7 @@ func RequestID() gin.HandlerFunc {
             requestID = generateRequestID()
         }
         c.Header("X-Request-ID", requestID)
-        c.Set("RequestID", requestID)
+        c.Set(RequestIDKey, requestID)
         c.Next()
     }
 }
@@ -77,12 +91,24 @@ func generateRequestID() string {
     return time.Now().Format("20060102150405") + "-" + randomString(8)
 }
 
-// randomString genera una cadena aleatoria
+// randomString genera una cadena aleatoria usando crypto/rand
 func randomString(length int) string {
     const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-    b := make([]byte, length)
-    for i := range b {
-        b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
+    // Bytes >= maxByte are discarded so every character is equally likely
+    const maxByte = 256 - 256%len(charset)
+
+    b := make([]byte, 0, length)
+    buf := make([]byte, length)
+    for len(b) < length {
+        if _, err := rand.Read(buf); err != nil {
+            // crypto/rand only fails if the OS entropy source is broken
+            panic("failed to read random bytes: " + err.Error())
+        }
+        for _, r := range buf {
+            if int(r) < maxByte && len(b) < length {
+                b = append(b, charset[int(r)%len(charset)])
+            }
+        }
     }
     return string(b)
 }
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap"
+    "go.uber.org/zap/zaptest/observer"
+
+    "distributed-cache/internal/config"
+    "distributed-cache/internal/logging"
+)
+
+func TestLogger_RequestScopedFields(t *testing.T) {
+    core, logs := observer.New(zap.DebugLevel)
+    logger := zap.New(core)
+
+    authenticate, err := Authenticate(config.AuthConfig{}, logger)
+    require.NoError(t, err)
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.Use(RequestID(), Logger(logger), authenticate)
+    router.GET("/items", func(c *gin.Context) {
+        logging.FromContext(c.Request.Context(), nil).Info("handler")
+        c.Status(http.StatusOK)
+    })
+
+    req := httptest.NewRequest(http.MethodGet, "/items", nil)
+    req.Header.Set("X-Request-ID", "req-42")
+    router.ServeHTTP(httptest.NewRecorder(), req)
+
+    entries := logs.All()
+    require.Len(t, entries, 2)
+    for _, entry := range entries {
+        fields := entry.ContextMap()
+        assert.Equal(t, "req-42", fields["request_id"])
+        assert.Equal(t, "192.0.2.1", fields["client"])
+        assert.Equal(t, "anonymous", fields["principal"])
+    }
+    assert.Equal(t, "handler", entries[0].Message)
+    assert.Equal(t, "HTTP Request", entries[1].Message)
+}
+
+func TestRandomString(t *testing.T) {
+    seen := make(map[string]bool)
+    for i := 0; i < 100; i++ {
+        s := randomString(8)
+        assert.Len(t, s, 8)
+        assert.False(t, seen[s], "duplicate random string %q", s)
+        seen[s] = true
+    }
+
+    // The old implementation produced the same character repeated
+    distinct := make(map[rune]bool)
+    for _, r := range randomString(32) {
+        distinct[r] = true
+    }
+    assert.Greater(t, len(distinct), 1)
+}
+
+func TestIdempotencyScope(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    scope := func(name, key string) string {
+        c, _ := gin.CreateTestContext(httptest.NewRecorder())
+        c.Set(principalContextKey, &Principal{Name: name})
+        return IdempotencyScope(c, "http", key)
+    }
+
+    // A ":" in the principal name cannot move the boundary with the key
+    assert.NotEqual(t, scope("team:a", "key"), scope("team", "a:key"))
+    assert.Equal(t, scope("team", "key"), scope("team", "key"))
+}
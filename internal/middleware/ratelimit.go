@@ -0,0 +1,138 @@
+package middleware
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "math"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+    "distributed-cache/internal/logging"
+)
+
+// APIKeyHeader is the header carrying the client API key
+const APIKeyHeader = "X-API-Key"
+
+// rateLimitRule is a rule resolved for the current request
+type rateLimitRule struct {
+    bucket string
+    limit  cache.RateLimit
+}
+
+// RateLimiter middleware backed by Redis. Every matching rule (client IP,
+// API key and route group) must allow the request; the most restrictive
+// result is reported in the X-RateLimit-* headers.
+func RateLimiter(limiter cache.RateLimiter, cfg config.RateLimitConfig, logger *zap.Logger) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if !cfg.Enabled || isExemptPath(c.Request.URL.Path, cfg.ExemptPaths) {
+            c.Next()
+            return
+        }
+
+        var tightest *cache.RateLimitResult
+        for _, rule := range rateLimitRules(c, cfg) {
+            result, err := limiter.Take(c.Request.Context(), rule.bucket, rule.limit, 1)
+            if err != nil {
+                // Fail open: an unavailable limiter must not take the API down
+                logging.FromContext(c.Request.Context(), logger).Warn("rate limiter unavailable, allowing request",
+                    zap.Error(err),
+                    zap.String("bucket", rule.bucket))
+                continue
+            }
+
+            if tightest == nil || !result.Allowed || (tightest.Allowed && result.Remaining < tightest.Remaining) {
+                tightest = result
+            }
+            if !result.Allowed {
+                break
+            }
+        }
+
+        if tightest == nil {
+            c.Next()
+            return
+        }
+
+        SetRateLimitHeaders(c, tightest)
+        if !tightest.Allowed {
+            logging.FromContext(c.Request.Context(), logger).Debug("request rate limited",
+                zap.String("path", c.Request.URL.Path))
+            c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+            return
+        }
+
+        c.Next()
+    }
+}
+
+// SetRateLimitHeaders writes the X-RateLimit-* and Retry-After headers for a result
+func SetRateLimitHeaders(c *gin.Context, result *cache.RateLimitResult) {
+    c.Header("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+    c.Header("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+    c.Header("X-RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.ResetAfter), 10))
+    if !result.Allowed {
+        c.Header("Retry-After", strconv.FormatInt(ceilSeconds(result.RetryAfter), 10))
+    }
+}
+
+// rateLimitRules resolves the rules that apply to the request
+func rateLimitRules(c *gin.Context, cfg config.RateLimitConfig) []rateLimitRule {
+    var rules []rateLimitRule
+    clientIP := c.ClientIP()
+
+    if cfg.PerIP.Enabled() {
+        rules = append(rules, rateLimitRule{bucket: "ip:" + clientIP, limit: cfg.PerIP})
+    }
+
+    // A key with its own limit replaces the per API key default
+    if principal, ok := PrincipalFromContext(c); ok && principal.RateLimit.Enabled() {
+        rules = append(rules, rateLimitRule{bucket: "principal:" + principal.Name, limit: principal.RateLimit})
+    } else if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" && cfg.PerAPIKey.Enabled() {
+        rules = append(rules, rateLimitRule{bucket: "apikey:" + hashAPIKey(apiKey), limit: cfg.PerAPIKey})
+    }
+
+    // Only the most specific route group applies
+    var route *config.RouteRateLimit
+    for i := range cfg.Routes {
+        candidate := &cfg.Routes[i]
+        if !candidate.Enabled() || !strings.HasPrefix(c.Request.URL.Path, candidate.Prefix) {
+            continue
+        }
+        if route == nil || len(candidate.Prefix) > len(route.Prefix) {
+            route = candidate
+        }
+    }
+    if route != nil {
+        rules = append(rules, rateLimitRule{bucket: "route:" + route.Prefix + ":" + clientIP, limit: route.RateLimit})
+    }
+
+    return rules
+}
+
+// isExemptPath reports whether the path is excluded from rate limiting
+func isExemptPath(path string, exempt []string) bool {
+    for _, p := range exempt {
+        if path == p {
+            return true
+        }
+    }
+    return false
+}
+
+// hashAPIKey returns a non-reversible identifier for an API key
+func hashAPIKey(apiKey string) string {
+    sum := sha256.Sum256([]byte(apiKey))
+    return hex.EncodeToString(sum[:])
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int64 {
+    return int64(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/propagation"
+    semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDHeader response header carrying the trace ID of the request
+const TraceIDHeader = "X-Trace-ID"
+
+// tracerName instrumentation scope of the HTTP server spans
+const tracerName = "distributed-cache/internal/middleware"
+
+// Tracing middleware starts a server span per request, continuing the trace
+// from an incoming W3C traceparent header. It must run after RequestID so the
+// span can be linked to the request ID.
+func Tracing() gin.HandlerFunc {
+    tracer := otel.Tracer(tracerName)
+
+    return func(c *gin.Context) {
+        ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+        route := c.FullPath()
+        spanName := c.Request.Method
+        if route != "" {
+            spanName += " " + route
+        }
+
+        ctx, span := tracer.Start(ctx, spanName,
+            trace.WithSpanKind(trace.SpanKindServer),
+            trace.WithAttributes(
+                semconv.HTTPMethod(c.Request.Method),
+                semconv.HTTPRoute(route),
+                semconv.URLPath(c.Request.URL.Path),
+                semconv.ClientAddress(c.ClientIP()),
+                semconv.UserAgentOriginal(c.Request.UserAgent()),
+                attribute.String("request.id", c.GetString(RequestIDKey)),
+            ))
+        defer span.End()
+
+        if span.SpanContext().HasTraceID() {
+            c.Header(TraceIDHeader, span.SpanContext().TraceID().String())
+        }
+
+        c.Request = c.Request.WithContext(ctx)
+        c.Next()
+
+        status := c.Writer.Status()
+        span.SetAttributes(semconv.HTTPStatusCode(status))
+        if status >= http.StatusInternalServerError {
+            span.SetStatus(codes.Error, http.StatusText(status))
+        }
+        if len(c.Errors) > 0 {
+            span.RecordError(c.Errors.Last())
+        }
+    }
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/propagation"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing_PropagatesTraceparent(t *testing.T) {
+    recorder := tracetest.NewSpanRecorder()
+    provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+    otel.SetTracerProvider(provider)
+    otel.SetTextMapPropagator(propagation.TraceContext{})
+    defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.Use(RequestID(), Tracing())
+    router.GET("/api/v1/cache/:key", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/cache/users:1", nil)
+    req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+    req.Header.Set("X-Request-ID", "req-123")
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+
+    assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", w.Header().Get(TraceIDHeader))
+
+    spans := recorder.Ended()
+    require.Len(t, spans, 1)
+    span := spans[0]
+    assert.Equal(t, "GET /api/v1/cache/:key", span.Name())
+    assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+    assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+    assert.Contains(t, span.Attributes(), attribute.String("request.id", "req-123"))
+    assert.Contains(t, span.Attributes(), attribute.Int("http.status_code", http.StatusNotFound))
+}
@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "sync"
+    "time"
+
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+    "distributed-cache/pkg/models"
+)
+
+const (
+    // maxClaimBatch bounds the due jobs claimed at a time per webhook
+    maxClaimBatch = 10
+    // maxRetryDelay caps the exponential backoff of failed deliveries
+    maxRetryDelay = time.Hour
+)
+
+// Dispatcher delivers the jobs of the configured topics to their webhooks.
+// Several instances may run against the same Redis: every job is claimed by
+// one of them, and redelivered if it is not acknowledged within the lease.
+type Dispatcher struct {
+    jobs     cache.JobScheduler
+    webhooks []config.ScheduleWebhook
+    interval time.Duration
+    lease    time.Duration
+    client   *http.Client
+    logger   *zap.Logger
+
+    done chan struct{}
+    wg   sync.WaitGroup
+}
+
+// NewDispatcher validates the webhooks, filling unset options with sensible defaults
+func NewDispatcher(jobs cache.JobScheduler, cfg config.SchedulerConfig, logger *zap.Logger) (*Dispatcher, error) {
+    if cfg.PollInterval <= 0 {
+        cfg.PollInterval = 500 * time.Millisecond
+    }
+    if cfg.LeaseTimeout <= 0 {
+        cfg.LeaseTimeout = 30 * time.Second
+    }
+
+    webhooks := make([]config.ScheduleWebhook, len(cfg.Webhooks))
+    for i, webhook := range cfg.Webhooks {
+        if webhook.Topic == "" {
+            return nil, fmt.Errorf("webhook %d: topic is required", i)
+        }
+        target, err := url.Parse(webhook.URL)
+        if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+            return nil, fmt.Errorf("webhook for topic %s: url must be an absolute http or https URL", webhook.Topic)
+        }
+        if webhook.Timeout <= 0 {
+            webhook.Timeout = 10 * time.Second
+        }
+        if webhook.Timeout >= cfg.LeaseTimeout {
+            return nil, fmt.Errorf("webhook for topic %s: timeout must be shorter than the lease timeout", webhook.Topic)
+        }
+        if webhook.RetryDelay <= 0 {
+            webhook.RetryDelay = 5 * time.Second
+        }
+        webhooks[i] = webhook
+    }
+
+    return &Dispatcher{
+        jobs:     jobs,
+        webhooks: webhooks,
+        interval: cfg.PollInterval,
+        lease:    cfg.LeaseTimeout,
+        client:   &http.Client{},
+        logger:   logger,
+        done:     make(chan struct{}),
+    }, nil
+}
+
+// Start launches one delivery loop per webhook
+func (d *Dispatcher) Start() {
+    for _, webhook := range d.webhooks {
+        d.wg.Add(1)
+        go d.run(webhook)
+    }
+}
+
+// Stop ends the delivery loops, waiting for the deliveries in progress.
+// Jobs claimed and not delivered are redelivered when their lease expires.
+func (d *Dispatcher) Stop() {
+    close(d.done)
+    d.wg.Wait()
+}
+
+// run delivers the due jobs of a webhook until the dispatcher stops
+func (d *Dispatcher) run(webhook config.ScheduleWebhook) {
+    defer d.wg.Done()
+
+    ticker := time.NewTicker(d.interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-d.done:
+            return
+        case <-ticker.C:
+            // Keep claiming while full batches come back
+            batch := claimBatch(d.lease, webhook.Timeout)
+            for d.dispatch(webhook, batch) == batch {
+                select {
+                case <-d.done:
+                    return
+                default:
+                }
+            }
+        }
+    }
+}
+
+// dispatch claims a batch of due jobs and delivers them, returning how many were claimed
+func (d *Dispatcher) dispatch(webhook config.ScheduleWebhook, batch int) int {
+    jobs, err := d.jobs.ClaimJobs(context.Background(), webhook.Topic, batch, d.lease)
+    if err != nil {
+        d.logger.Error("failed to claim scheduled jobs", zap.Error(err), zap.String("topic", webhook.Topic))
+        return 0
+    }
+
+    for _, job := range jobs {
+        d.deliver(webhook, job)
+    }
+    return len(jobs)
+}
+
+// deliver posts the job to the webhook, acknowledging it on a 2xx response
+// and rescheduling it with exponential backoff otherwise
+func (d *Dispatcher) deliver(webhook config.ScheduleWebhook, job *models.ScheduledJob) {
+    logger := d.logger.With(
+        zap.String("topic", job.Topic),
+        zap.String("id", job.ID),
+        zap.Int64("attempt", job.Attempts))
+
+    if err := d.post(webhook, job); err != nil {
+        delay := retryDelay(webhook.RetryDelay, job.Attempts)
+        logger.Warn("webhook delivery failed", zap.Error(err), zap.Duration("retry_in", delay))
+        if _, err := d.jobs.RetryJob(context.Background(), job.Topic, job.ID, delay); err != nil {
+            // The lease expires and the job is delivered again anyway
+            logger.Error("failed to reschedule job", zap.Error(err))
+        }
+        return
+    }
+
+    if _, err := d.jobs.DeleteJob(context.Background(), job.Topic, job.ID); err != nil {
+        logger.Error("failed to acknowledge delivered job", zap.Error(err))
+        return
+    }
+    logger.Debug("job delivered to webhook")
+}
+
+// post sends the job as JSON. The job ID goes in the Idempotency-Key header
+// so receivers can discard redeliveries.
+func (d *Dispatcher) post(webhook config.ScheduleWebhook, job *models.ScheduledJob) error {
+    body, err := json.Marshal(job)
+    if err != nil {
+        return fmt.Errorf("failed to marshal job: %w", err)
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), webhook.Timeout)
+    defer cancel()
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+    if err != nil {
+        return fmt.Errorf("failed to create webhook request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Idempotency-Key", job.ID)
+
+    resp, err := d.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return fmt.Errorf("unexpected status %d", resp.StatusCode)
+    }
+    return nil
+}
+
+// claimBatch is how many jobs can be posted one after another, each taking
+// up to timeout, before the lease they were claimed under expires
+func claimBatch(lease, timeout time.Duration) int {
+    batch := int(lease / timeout)
+    if batch < 1 {
+        batch = 1
+    }
+    if batch > maxClaimBatch {
+        batch = maxClaimBatch
+    }
+    return batch
+}
+
+// retryDelay doubles base for every attempt after the first, up to maxRetryDelay
+func retryDelay(base time.Duration, attempts int64) time.Duration {
+    delay := base
+    for i := int64(1); i < attempts && delay < maxRetryDelay; i++ {
+        delay *= 2
+    }
+    if delay > maxRetryDelay {
+        delay = maxRetryDelay
+    }
+    return delay
+}
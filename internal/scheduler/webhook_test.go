@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap/zaptest"
+
+    "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
+    "distributed-cache/pkg/models"
+)
+
+func TestDispatcher_DeliversWithRetry(t *testing.T) {
+    logger := zaptest.NewLogger(t)
+    store, err := cache.NewRedisCache(cache.DefaultCacheConfig(), logger)
+    require.NoError(t, err)
+    defer store.Close()
+    require.NoError(t, store.Clear(context.Background()))
+
+    var mu sync.Mutex
+    var deliveries []models.ScheduledJob
+    var keys []string
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var job models.ScheduledJob
+        require.NoError(t, json.NewDecoder(r.Body).Decode(&job))
+
+        mu.Lock()
+        defer mu.Unlock()
+        deliveries = append(deliveries, job)
+        keys = append(keys, r.Header.Get("Idempotency-Key"))
+        // La primera entrega falla
+        if len(deliveries) == 1 {
+            w.WriteHeader(http.StatusServiceUnavailable)
+        }
+    }))
+    defer server.Close()
+
+    dispatcher, err := NewDispatcher(store, config.SchedulerConfig{
+        PollInterval: 10 * time.Millisecond,
+        LeaseTimeout: 5 * time.Second,
+        Webhooks: []config.ScheduleWebhook{
+            {Topic: "emails", URL: server.URL, Timeout: time.Second, RetryDelay: 20 * time.Millisecond},
+        },
+    }, logger)
+    require.NoError(t, err)
+    dispatcher.Start()
+    defer dispatcher.Stop()
+
+    job, err := store.ScheduleJob(context.Background(), "emails", map[string]interface{}{"to": "ana@example.com"}, time.Now().Add(50*time.Millisecond))
+    require.NoError(t, err)
+
+    require.Eventually(t, func() bool {
+        stored, err := store.GetJob(context.Background(), "emails", job.ID)
+        return err == nil && stored == nil
+    }, 2*time.Second, 10*time.Millisecond)
+
+    mu.Lock()
+    defer mu.Unlock()
+    require.Len(t, deliveries, 2)
+    assert.Equal(t, job.ID, deliveries[1].ID)
+    assert.Equal(t, int64(1), deliveries[0].Attempts)
+    assert.Equal(t, int64(2), deliveries[1].Attempts)
+    assert.Equal(t, map[string]interface{}{"to": "ana@example.com"}, deliveries[1].Payload)
+    assert.Equal(t, []string{job.ID, job.ID}, keys)
+}
+
+func TestNewDispatcher_Validation(t *testing.T) {
+    logger := zaptest.NewLogger(t)
+
+    cases := map[string]config.ScheduleWebhook{
+        "sin tema":         {URL: "http://localhost:9000/hooks"},
+        "url relativa":     {Topic: "emails", URL: "/hooks"},
+        "esquema inválido": {Topic: "emails", URL: "ftp://localhost/hooks"},
+        "timeout largo":    {Topic: "emails", URL: "http://localhost:9000/hooks", Timeout: time.Minute},
+    }
+    for name, webhook := range cases {
+        _, err := NewDispatcher(nil, config.SchedulerConfig{
+            LeaseTimeout: 30 * time.Second,
+            Webhooks:     []config.ScheduleWebhook{webhook},
+        }, logger)
+        assert.Error(t, err, name)
+    }
+
+    dispatcher, err := NewDispatcher(nil, config.SchedulerConfig{
+        Webhooks: []config.ScheduleWebhook{{Topic: "emails", URL: "http://localhost:9000/hooks"}},
+    }, logger)
+    require.NoError(t, err)
+    assert.Equal(t, 10*time.Second, dispatcher.webhooks[0].Timeout)
+    assert.Equal(t, 5*time.Second, dispatcher.webhooks[0].RetryDelay)
+}
+
+func TestRetryDelay(t *testing.T) {
+    assert.Equal(t, 5*time.Second, retryDelay(5*time.Second, 1))
+    assert.Equal(t, 10*time.Second, retryDelay(5*time.Second, 2))
+    assert.Equal(t, 40*time.Second, retryDelay(5*time.Second, 4))
+    assert.Equal(t, maxRetryDelay, retryDelay(5*time.Second, 100))
+}
+
+func TestClaimBatch(t *testing.T) {
+    // Con el timeout por defecto caben 3 entregas en el lease de 30s
+    assert.Equal(t, 3, claimBatch(30*time.Second, 10*time.Second))
+    assert.Equal(t, 1, claimBatch(30*time.Second, 20*time.Second))
+    assert.Equal(t, maxClaimBatch, claimBatch(30*time.Second, time.Second))
+}
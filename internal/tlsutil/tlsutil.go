@@ -0,0 +1,309 @@
+package tlsutil
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "errors"
+    "fmt"
+    "os"
+    "sync"
+    "time"
+
+    "go.uber.org/zap"
+)
+
+// ServerOptions configures TLS for inbound connections
+type ServerOptions struct {
+    CertFile       string
+    KeyFile        string
+    ClientCAFile   string        // CA used to verify client certificates (mTLS)
+    ClientAuth     string        // none, request, require, verify_if_given, require_and_verify
+    ReloadInterval time.Duration // Minimum time between checks for changed files, zero checks on every handshake
+}
+
+// ClientOptions configures TLS for outbound connections
+type ClientOptions struct {
+    CAFile             string // CA used to verify the server, system roots if empty
+    CertFile           string // Client certificate for mTLS
+    KeyFile            string
+    ServerName         string
+    InsecureSkipVerify bool
+    ReloadInterval     time.Duration
+}
+
+// ServerConfig builds a server TLS configuration whose certificate and
+// client CA pool are reloaded when the files change on disk
+func ServerConfig(opts ServerOptions, logger *zap.Logger) (*tls.Config, error) {
+    if opts.CertFile == "" || opts.KeyFile == "" {
+        return nil, errors.New("TLS requires cert_file and key_file")
+    }
+
+    clientAuth, err := parseClientAuth(opts.ClientAuth, opts.ClientCAFile != "")
+    if err != nil {
+        return nil, err
+    }
+
+    certs, err := newCertReloader(opts.CertFile, opts.KeyFile, opts.ReloadInterval, logger)
+    if err != nil {
+        return nil, err
+    }
+
+    var clientCAs *poolReloader
+    if opts.ClientCAFile != "" {
+        clientCAs, err = newPoolReloader(opts.ClientCAFile, opts.ReloadInterval, logger)
+        if err != nil {
+            return nil, err
+        }
+    } else if clientAuth >= tls.VerifyClientCertIfGiven {
+        return nil, errors.New("client certificate verification requires client_ca_file")
+    }
+
+    base := &tls.Config{
+        MinVersion:     tls.VersionTLS12,
+        GetCertificate: certs.getCertificate,
+        ClientAuth:     clientAuth,
+    }
+
+    // A per-connection config picks up the current client CA pool
+    base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+        config := base.Clone()
+        config.GetConfigForClient = nil
+        if clientCAs != nil {
+            config.ClientCAs = clientCAs.pool()
+        }
+        return config, nil
+    }
+
+    return base, nil
+}
+
+// ClientConfig builds a client TLS configuration whose client certificate
+// and CA pool are reloaded when the files change on disk
+func ClientConfig(opts ClientOptions, logger *zap.Logger) (*tls.Config, error) {
+    config := &tls.Config{
+        MinVersion:         tls.VersionTLS12,
+        ServerName:         opts.ServerName,
+        InsecureSkipVerify: opts.InsecureSkipVerify,
+    }
+
+    if opts.CertFile != "" || opts.KeyFile != "" {
+        certs, err := newCertReloader(opts.CertFile, opts.KeyFile, opts.ReloadInterval, logger)
+        if err != nil {
+            return nil, err
+        }
+        config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+            return certs.getCertificate(nil)
+        }
+    }
+
+    if opts.CAFile != "" && !opts.InsecureSkipVerify {
+        roots, err := newPoolReloader(opts.CAFile, opts.ReloadInterval, logger)
+        if err != nil {
+            return nil, err
+        }
+
+        // The standard verification cannot swap its roots, so it is replaced
+        // by an equivalent check against the current CA pool
+        config.InsecureSkipVerify = true
+        config.VerifyConnection = func(cs tls.ConnectionState) error {
+            return verifyServer(cs, roots.pool(), opts.ServerName)
+        }
+    }
+
+    return config, nil
+}
+
+// verifyServer verifies the server certificate chain and host name
+func verifyServer(cs tls.ConnectionState, roots *x509.CertPool, serverName string) error {
+    if len(cs.PeerCertificates) == 0 {
+        return errors.New("server presented no certificate")
+    }
+
+    if serverName == "" {
+        serverName = cs.ServerName
+    }
+
+    intermediates := x509.NewCertPool()
+    for _, cert := range cs.PeerCertificates[1:] {
+        intermediates.AddCert(cert)
+    }
+
+    _, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+        Roots:         roots,
+        Intermediates: intermediates,
+        DNSName:       serverName,
+        KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+    })
+    return err
+}
+
+// parseClientAuth converts the configured client authentication mode
+func parseClientAuth(mode string, hasCA bool) (tls.ClientAuthType, error) {
+    switch mode {
+    case "":
+        if hasCA {
+            return tls.RequireAndVerifyClientCert, nil
+        }
+        return tls.NoClientCert, nil
+    case "none":
+        return tls.NoClientCert, nil
+    case "request":
+        return tls.RequestClientCert, nil
+    case "require":
+        return tls.RequireAnyClientCert, nil
+    case "verify_if_given":
+        return tls.VerifyClientCertIfGiven, nil
+    case "require_and_verify":
+        return tls.RequireAndVerifyClientCert, nil
+    default:
+        return tls.NoClientCert, fmt.Errorf("unknown client_auth mode: %s", mode)
+    }
+}
+
+// fileWatcher detects changes in a set of files by size and modification time
+type fileWatcher struct {
+    files    []string
+    interval time.Duration
+    checked  time.Time
+    stamps   []string
+}
+
+// changed reports whether any file changed since the last call. Callers
+// must serialize access.
+func (fw *fileWatcher) changed() bool {
+    if fw.interval > 0 && time.Since(fw.checked) < fw.interval {
+        return false
+    }
+    fw.checked = time.Now()
+
+    stamps := make([]string, len(fw.files))
+    for i, file := range fw.files {
+        info, err := os.Stat(file)
+        if err != nil {
+            // Files are often replaced non-atomically; keep the current state
+            return false
+        }
+        stamps[i] = fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+    }
+
+    changed := false
+    for i := range stamps {
+        if fw.stamps == nil || stamps[i] != fw.stamps[i] {
+            changed = true
+        }
+    }
+    fw.stamps = stamps
+    return changed
+}
+
+// certReloader serves a certificate pair, reloading it when the files change
+type certReloader struct {
+    certFile string
+    keyFile  string
+    logger   *zap.Logger
+
+    mu      sync.Mutex
+    watcher fileWatcher
+    cert    *tls.Certificate
+}
+
+// newCertReloader loads the certificate pair for the first time
+func newCertReloader(certFile, keyFile string, interval time.Duration, logger *zap.Logger) (*certReloader, error) {
+    r := &certReloader{
+        certFile: certFile,
+        keyFile:  keyFile,
+        logger:   logger,
+        watcher:  fileWatcher{files: []string{certFile, keyFile}, interval: interval},
+    }
+
+    r.watcher.changed()
+    cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+    if err != nil {
+        return nil, fmt.Errorf("failed to load certificate: %w", err)
+    }
+    r.cert = &cert
+
+    return r, nil
+}
+
+// getCertificate returns the current certificate, reloading it if needed
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.watcher.changed() {
+        cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+        if err != nil {
+            r.logger.Error("failed to reload certificate, keeping previous one",
+                zap.Error(err),
+                zap.String("cert_file", r.certFile))
+        } else {
+            r.cert = &cert
+            r.logger.Info("certificate reloaded", zap.String("cert_file", r.certFile))
+        }
+    }
+
+    return r.cert, nil
+}
+
+// poolReloader serves a CA pool, reloading it when the file changes
+type poolReloader struct {
+    file   string
+    logger *zap.Logger
+
+    mu      sync.Mutex
+    watcher fileWatcher
+    current *x509.CertPool
+}
+
+// newPoolReloader loads the CA pool for the first time
+func newPoolReloader(file string, interval time.Duration, logger *zap.Logger) (*poolReloader, error) {
+    r := &poolReloader{
+        file:    file,
+        logger:  logger,
+        watcher: fileWatcher{files: []string{file}, interval: interval},
+    }
+
+    r.watcher.changed()
+    pool, err := loadPool(file)
+    if err != nil {
+        return nil, err
+    }
+    r.current = pool
+
+    return r, nil
+}
+
+// pool returns the current CA pool, reloading it if needed
+func (r *poolReloader) pool() *x509.CertPool {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.watcher.changed() {
+        pool, err := loadPool(r.file)
+        if err != nil {
+            r.logger.Error("failed to reload CA file, keeping previous one",
+                zap.Error(err),
+                zap.String("ca_file", r.file))
+        } else {
+            r.current = pool
+            r.logger.Info("CA file reloaded", zap.String("ca_file", r.file))
+        }
+    }
+
+    return r.current
+}
+
+// loadPool reads a PEM bundle into a certificate pool
+func loadPool(file string) (*x509.CertPool, error) {
+    data, err := os.ReadFile(file)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read CA file: %w", err)
+    }
+
+    pool := x509.NewCertPool()
+    if !pool.AppendCertsFromPEM(data) {
+        return nil, fmt.Errorf("no certificates found in CA file: %s", file)
+    }
+    return pool, nil
+}
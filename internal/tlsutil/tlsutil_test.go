@@ -0,0 +1,212 @@
+package tlsutil
+
+import (
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/tls"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/pem"
+    "math/big"
+    "net"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+    "go.uber.org/zap/zaptest"
+)
+
+// testCA firma certificados de prueba
+type testCA struct {
+    cert *x509.Certificate
+    key  *ecdsa.PrivateKey
+    pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    require.NoError(t, err)
+
+    template := &x509.Certificate{
+        SerialNumber:          big.NewInt(1),
+        Subject:               pkix.Name{CommonName: "test-ca"},
+        NotBefore:             time.Now().Add(-1 * time.Hour),
+        NotAfter:              time.Now().Add(1 * time.Hour),
+        IsCA:                  true,
+        KeyUsage:              x509.KeyUsageCertSign,
+        BasicConstraintsValid: true,
+    }
+    der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+    require.NoError(t, err)
+    cert, err := x509.ParseCertificate(der)
+    require.NoError(t, err)
+
+    return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue genera un certificado firmado por la CA y lo escribe en dir
+func (ca *testCA) issue(t *testing.T, dir, name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    require.NoError(t, err)
+
+    template := &x509.Certificate{
+        SerialNumber: big.NewInt(serial),
+        Subject:      pkix.Name{CommonName: name},
+        DNSNames:     []string{"localhost"},
+        IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+        NotBefore:    time.Now().Add(-1 * time.Hour),
+        NotAfter:     time.Now().Add(1 * time.Hour),
+        KeyUsage:     x509.KeyUsageDigitalSignature,
+        ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+    }
+    der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+    require.NoError(t, err)
+    keyDER, err := x509.MarshalECPrivateKey(key)
+    require.NoError(t, err)
+
+    certFile := filepath.Join(dir, name+".crt")
+    keyFile := filepath.Join(dir, name+".key")
+    require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+    require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+    return certFile, keyFile
+}
+
+// serve acepta conexiones TLS y completa el handshake
+func serve(t *testing.T, config *tls.Config) string {
+    listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+    require.NoError(t, err)
+    t.Cleanup(func() { listener.Close() })
+
+    go func() {
+        for {
+            conn, err := listener.Accept()
+            if err != nil {
+                return
+            }
+            go func() {
+                defer conn.Close()
+                conn.(*tls.Conn).Handshake()
+                conn.Read(make([]byte, 1))
+            }()
+        }
+    }()
+
+    return listener.Addr().String()
+}
+
+// dial completa un handshake y devuelve el número de serie del servidor
+func dial(addr string, config *tls.Config) (int64, error) {
+    conn, err := tls.Dial("tcp", addr, config)
+    if err != nil {
+        return 0, err
+    }
+    defer conn.Close()
+
+    // Con TLS 1.3 el rechazo del certificado de cliente llega tras el handshake
+    conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+    if _, err := conn.Read(make([]byte, 1)); err != nil {
+        if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+            return 0, err
+        }
+    }
+    return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64(), nil
+}
+
+func TestServerConfig_MutualTLS(t *testing.T) {
+    dir := t.TempDir()
+    ca := newTestCA(t)
+    caFile := filepath.Join(dir, "ca.crt")
+    require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+
+    serverCert, serverKey := ca.issue(t, dir, "server", 10, x509.ExtKeyUsageServerAuth)
+    clientCert, clientKey := ca.issue(t, dir, "client", 20, x509.ExtKeyUsageClientAuth)
+
+    serverConfig, err := ServerConfig(ServerOptions{
+        CertFile:     serverCert,
+        KeyFile:      serverKey,
+        ClientCAFile: caFile,
+    }, zaptest.NewLogger(t))
+    require.NoError(t, err)
+    addr := serve(t, serverConfig)
+
+    // Cliente con certificado
+    clientConfig, err := ClientConfig(ClientOptions{
+        CAFile:     caFile,
+        CertFile:   clientCert,
+        KeyFile:    clientKey,
+        ServerName: "localhost",
+    }, zaptest.NewLogger(t))
+    require.NoError(t, err)
+
+    serial, err := dial(addr, clientConfig)
+    require.NoError(t, err)
+    assert.Equal(t, int64(10), serial)
+
+    // Cliente sin certificado
+    anonymous, err := ClientConfig(ClientOptions{CAFile: caFile, ServerName: "localhost"}, zaptest.NewLogger(t))
+    require.NoError(t, err)
+
+    _, err = dial(addr, anonymous)
+    assert.Error(t, err)
+}
+
+func TestServerConfig_ReloadsCertificate(t *testing.T) {
+    dir := t.TempDir()
+    ca := newTestCA(t)
+    caFile := filepath.Join(dir, "ca.crt")
+    require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+
+    serverCert, serverKey := ca.issue(t, dir, "server", 10, x509.ExtKeyUsageServerAuth)
+
+    serverConfig, err := ServerConfig(ServerOptions{CertFile: serverCert, KeyFile: serverKey}, zaptest.NewLogger(t))
+    require.NoError(t, err)
+    addr := serve(t, serverConfig)
+
+    clientConfig, err := ClientConfig(ClientOptions{CAFile: caFile, ServerName: "localhost"}, zaptest.NewLogger(t))
+    require.NoError(t, err)
+
+    serial, err := dial(addr, clientConfig)
+    require.NoError(t, err)
+    assert.Equal(t, int64(10), serial)
+
+    // Rotar el certificado en disco
+    ca.issue(t, dir, "server", 11, x509.ExtKeyUsageServerAuth)
+    future := time.Now().Add(1 * time.Minute)
+    require.NoError(t, os.Chtimes(serverCert, future, future))
+    require.NoError(t, os.Chtimes(serverKey, future, future))
+
+    serial, err = dial(addr, clientConfig)
+    require.NoError(t, err)
+    assert.Equal(t, int64(11), serial)
+}
+
+func TestClientConfig_RejectsUnknownCA(t *testing.T) {
+    dir := t.TempDir()
+    ca := newTestCA(t)
+    other := newTestCA(t)
+    otherFile := filepath.Join(dir, "other.crt")
+    require.NoError(t, os.WriteFile(otherFile, other.pem, 0o600))
+
+    serverCert, serverKey := ca.issue(t, dir, "server", 10, x509.ExtKeyUsageServerAuth)
+    serverConfig, err := ServerConfig(ServerOptions{CertFile: serverCert, KeyFile: serverKey}, zaptest.NewLogger(t))
+    require.NoError(t, err)
+    addr := serve(t, serverConfig)
+
+    clientConfig, err := ClientConfig(ClientOptions{CAFile: otherFile, ServerName: "localhost"}, zaptest.NewLogger(t))
+    require.NoError(t, err)
+
+    _, err = dial(addr, clientConfig)
+    assert.Error(t, err)
+}
+
+func TestServerConfig_InvalidOptions(t *testing.T) {
+    _, err := ServerConfig(ServerOptions{}, zaptest.NewLogger(t))
+    assert.Error(t, err)
+
+    _, err = parseClientAuth("sometimes", false)
+    assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package tracing
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+    "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+    "go.opentelemetry.io/otel/propagation"
+    "go.opentelemetry.io/otel/sdk/resource"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+    "go.uber.org/zap"
+
+    "distributed-cache/internal/config"
+)
+
+// Supported exporters
+const (
+    ExporterOTLP   = "otlp"
+    ExporterStdout = "stdout"
+    ExporterFile   = "file"
+)
+
+// ShutdownFunc flushes pending spans and releases the exporter
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. When tracing is disabled only the propagator is installed, so
+// incoming traceparent headers are still honoured by downstream services.
+func Setup(cfg config.TracingConfig, version string, logger *zap.Logger) (ShutdownFunc, error) {
+    otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+        propagation.TraceContext{},
+        propagation.Baggage{},
+    ))
+
+    if !cfg.Enabled {
+        return func(context.Context) error { return nil }, nil
+    }
+
+    exporter, closer, err := newExporter(cfg)
+    if err != nil {
+        return nil, err
+    }
+
+    res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+        semconv.SchemaURL,
+        semconv.ServiceName(cfg.ServiceName),
+        semconv.ServiceVersion(version),
+    ))
+    if err != nil {
+        return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+    }
+
+    provider := sdktrace.NewTracerProvider(
+        sdktrace.WithBatcher(exporter),
+        sdktrace.WithResource(res),
+        sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+    )
+    otel.SetTracerProvider(provider)
+    otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+        logger.Warn("tracing error", zap.Error(err))
+    }))
+
+    logger.Info("tracing enabled",
+        zap.String("exporter", cfg.Exporter),
+        zap.Float64("sample_ratio", cfg.SampleRatio))
+
+    return func(ctx context.Context) error {
+        err := provider.Shutdown(ctx)
+        if closer != nil {
+            if closeErr := closer.Close(); err == nil {
+                err = closeErr
+            }
+        }
+        return err
+    }, nil
+}
+
+// newExporter creates the span exporter selected in the configuration
+func newExporter(cfg config.TracingConfig) (sdktrace.SpanExporter, io.Closer, error) {
+    switch cfg.Exporter {
+    case ExporterOTLP, "":
+        opts := []otlptracehttp.Option{}
+        if cfg.Endpoint != "" {
+            opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+        }
+        if cfg.Insecure {
+            opts = append(opts, otlptracehttp.WithInsecure())
+        }
+        if len(cfg.Headers) > 0 {
+            opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+        }
+        exporter, err := otlptracehttp.New(context.Background(), opts...)
+        if err != nil {
+            return nil, nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+        }
+        return exporter, nil, nil
+
+    case ExporterStdout:
+        exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+        if err != nil {
+            return nil, nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+        }
+        return exporter, nil, nil
+
+    case ExporterFile:
+        if cfg.FilePath == "" {
+            return nil, nil, fmt.Errorf("tracing file exporter requires file_path")
+        }
+        file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+        if err != nil {
+            return nil, nil, fmt.Errorf("failed to open tracing file: %w", err)
+        }
+        exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+        if err != nil {
+            file.Close()
+            return nil, nil, fmt.Errorf("failed to create file exporter: %w", err)
+        }
+        return exporter, file, nil
+
+    default:
+        return nil, nil, fmt.Errorf("unknown tracing exporter: %s", cfg.Exporter)
+    }
+}
@@ -0,0 +1,401 @@
+// Package jsonpatch applies JSON Patch (RFC 6902) and JSON Merge Patch
+// (RFC 7396) documents to JSON values decoded with encoding/json, that is
+// maps, slices, float64, string, bool and nil.
+package jsonpatch
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "strconv"
+    "strings"
+)
+
+// Media types of the supported patch formats
+const (
+    MediaTypeJSONPatch  = "application/json-patch+json"
+    MediaTypeMergePatch = "application/merge-patch+json"
+)
+
+var (
+    // ErrInvalidPatch is returned for patch documents that do not follow RFC 6902
+    ErrInvalidPatch = errors.New("invalid patch")
+    // ErrPathNotFound is returned when an operation references a missing location
+    ErrPathNotFound = errors.New("path not found")
+    // ErrTestFailed is returned when a test operation does not match
+    ErrTestFailed = errors.New("test operation failed")
+)
+
+// Error reports the operation of a patch that could not be applied
+type Error struct {
+    Index int // Position of the operation in the patch
+    Op    string
+    Path  string
+    Err   error
+}
+
+func (e *Error) Error() string {
+    return fmt.Sprintf("operation %d (%s %q): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+    return e.Err
+}
+
+// Operation a single JSON Patch operation
+type Operation struct {
+    Op    string      `json:"op"`
+    Path  string      `json:"path"`
+    From  string      `json:"from,omitempty"`
+    Value interface{} `json:"value,omitempty"`
+
+    hasValue bool // "value" was present, even if null
+    hasFrom  bool
+}
+
+// UnmarshalJSON decodes an operation keeping track of a null value
+func (o *Operation) UnmarshalJSON(data []byte) error {
+    var raw struct {
+        Op    string          `json:"op"`
+        Path  *string         `json:"path"`
+        From  *string         `json:"from"`
+        Value json.RawMessage `json:"value"`
+    }
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return err
+    }
+    if raw.Path == nil {
+        return fmt.Errorf("%w: missing path", ErrInvalidPatch)
+    }
+
+    *o = Operation{Op: raw.Op, Path: *raw.Path}
+    if raw.From != nil {
+        o.From, o.hasFrom = *raw.From, true
+    }
+    if raw.Value != nil {
+        o.hasValue = true
+        if err := json.Unmarshal(raw.Value, &o.Value); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Patch a JSON Patch document
+type Patch []Operation
+
+// DecodePatch parses and validates a JSON Patch document
+func DecodePatch(data []byte) (Patch, error) {
+    var patch Patch
+    if err := json.Unmarshal(data, &patch); err != nil {
+        if errors.Is(err, ErrInvalidPatch) {
+            return nil, err
+        }
+        return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+    }
+
+    for i, op := range patch {
+        if err := op.validate(); err != nil {
+            return nil, &Error{Index: i, Op: op.Op, Path: op.Path, Err: err}
+        }
+    }
+    return patch, nil
+}
+
+func (o *Operation) validate() error {
+    if _, err := parsePointer(o.Path); err != nil {
+        return err
+    }
+    switch o.Op {
+    case "add", "replace", "test":
+        if !o.hasValue {
+            return fmt.Errorf("%w: missing value", ErrInvalidPatch)
+        }
+    case "move", "copy":
+        if !o.hasFrom {
+            return fmt.Errorf("%w: missing from", ErrInvalidPatch)
+        }
+        if _, err := parsePointer(o.From); err != nil {
+            return err
+        }
+        if o.Op == "move" && strings.HasPrefix(o.Path, o.From+"/") {
+            return fmt.Errorf("%w: cannot move a value into one of its children", ErrInvalidPatch)
+        }
+    case "remove":
+    default:
+        return fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, o.Op)
+    }
+    return nil
+}
+
+// Apply returns doc with the patch applied. Operations are applied in order
+// and the patch is all or nothing: doc is never modified.
+func (p Patch) Apply(doc interface{}) (interface{}, error) {
+    doc = deepCopy(doc)
+    for i, op := range p {
+        var err error
+        if doc, err = op.apply(doc); err != nil {
+            return nil, &Error{Index: i, Op: op.Op, Path: op.Path, Err: err}
+        }
+    }
+    return doc, nil
+}
+
+func (o *Operation) apply(doc interface{}) (interface{}, error) {
+    path, err := parsePointer(o.Path)
+    if err != nil {
+        return nil, err
+    }
+
+    switch o.Op {
+    case "add":
+        return add(doc, path, deepCopy(o.Value))
+    case "remove":
+        doc, _, err = remove(doc, path)
+        return doc, err
+    case "replace":
+        if _, err := get(doc, path); err != nil {
+            return nil, err
+        }
+        return set(doc, path, deepCopy(o.Value))
+    case "move":
+        if o.From == o.Path {
+            _, err := get(doc, path)
+            return doc, err
+        }
+        from, _ := parsePointer(o.From)
+        doc, value, err := remove(doc, from)
+        if err != nil {
+            return nil, err
+        }
+        return add(doc, path, value)
+    case "copy":
+        from, _ := parsePointer(o.From)
+        value, err := get(doc, from)
+        if err != nil {
+            return nil, err
+        }
+        return add(doc, path, deepCopy(value))
+    case "test":
+        value, err := get(doc, path)
+        if err != nil {
+            return nil, err
+        }
+        if !reflect.DeepEqual(value, o.Value) {
+            return nil, ErrTestFailed
+        }
+        return doc, nil
+    }
+    return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, o.Op)
+}
+
+// MergePatch returns target with a JSON Merge Patch applied. A null member
+// removes it from the target and a patch that is not an object replaces
+// the target. Neither argument is modified.
+func MergePatch(target, patch interface{}) interface{} {
+    members, ok := patch.(map[string]interface{})
+    if !ok {
+        return patch
+    }
+
+    current, _ := target.(map[string]interface{})
+    merged := make(map[string]interface{}, len(current)+len(members))
+    for name, value := range current {
+        merged[name] = value
+    }
+    for name, value := range members {
+        if value == nil {
+            delete(merged, name)
+            continue
+        }
+        merged[name] = MergePatch(merged[name], value)
+    }
+    return merged
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+    if pointer == "" {
+        return nil, nil
+    }
+    if pointer[0] != '/' {
+        return nil, fmt.Errorf("%w: pointer %q must start with /", ErrInvalidPatch, pointer)
+    }
+
+    tokens := strings.Split(pointer[1:], "/")
+    for i, token := range tokens {
+        for j := 0; j < len(token); j++ {
+            if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+                return nil, fmt.Errorf("%w: invalid escape in pointer %q", ErrInvalidPatch, pointer)
+            }
+        }
+        tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+    }
+    return tokens, nil
+}
+
+// get returns the value referenced by path
+func get(doc interface{}, path []string) (interface{}, error) {
+    for _, token := range path {
+        switch node := doc.(type) {
+        case map[string]interface{}:
+            value, ok := node[token]
+            if !ok {
+                return nil, ErrPathNotFound
+            }
+            doc = value
+        case []interface{}:
+            i, err := arrayIndex(token, len(node)-1)
+            if err != nil {
+                return nil, err
+            }
+            doc = node[i]
+        default:
+            return nil, ErrPathNotFound
+        }
+    }
+    return doc, nil
+}
+
+// update replaces the container holding the last token of path by the
+// result of fn, rebuilding the parents that changed
+func update(doc interface{}, path []string, fn func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+    if len(path) == 1 {
+        return fn(doc, path[0])
+    }
+
+    switch node := doc.(type) {
+    case map[string]interface{}:
+        child, ok := node[path[0]]
+        if !ok {
+            return nil, ErrPathNotFound
+        }
+        child, err := update(child, path[1:], fn)
+        if err != nil {
+            return nil, err
+        }
+        node[path[0]] = child
+        return node, nil
+    case []interface{}:
+        i, err := arrayIndex(path[0], len(node)-1)
+        if err != nil {
+            return nil, err
+        }
+        child, err := update(node[i], path[1:], fn)
+        if err != nil {
+            return nil, err
+        }
+        node[i] = child
+        return node, nil
+    }
+    return nil, ErrPathNotFound
+}
+
+// add inserts value at path; array elements after it are shifted
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+    if len(path) == 0 {
+        return value, nil
+    }
+    return update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+        switch node := parent.(type) {
+        case map[string]interface{}:
+            node[token] = value
+            return node, nil
+        case []interface{}:
+            i := len(node)
+            if token != "-" {
+                var err error
+                if i, err = arrayIndex(token, len(node)); err != nil {
+                    return nil, err
+                }
+            }
+            node = append(node, nil)
+            copy(node[i+1:], node[i:])
+            node[i] = value
+            return node, nil
+        }
+        return nil, ErrPathNotFound
+    })
+}
+
+// set replaces the existing value at path
+func set(doc interface{}, path []string, value interface{}) (interface{}, error) {
+    if len(path) == 0 {
+        return value, nil
+    }
+    return update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+        switch node := parent.(type) {
+        case map[string]interface{}:
+            node[token] = value
+            return node, nil
+        case []interface{}:
+            i, err := arrayIndex(token, len(node)-1)
+            if err != nil {
+                return nil, err
+            }
+            node[i] = value
+            return node, nil
+        }
+        return nil, ErrPathNotFound
+    })
+}
+
+// remove deletes the value at path and returns it
+func remove(doc interface{}, path []string) (_ interface{}, removed interface{}, err error) {
+    if len(path) == 0 {
+        return nil, nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPatch)
+    }
+    doc, err = update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+        switch node := parent.(type) {
+        case map[string]interface{}:
+            value, ok := node[token]
+            if !ok {
+                return nil, ErrPathNotFound
+            }
+            removed = value
+            delete(node, token)
+            return node, nil
+        case []interface{}:
+            i, err := arrayIndex(token, len(node)-1)
+            if err != nil {
+                return nil, err
+            }
+            removed = node[i]
+            return append(node[:i], node[i+1:]...), nil
+        }
+        return nil, ErrPathNotFound
+    })
+    return doc, removed, err
+}
+
+// arrayIndex parses an array index token, which may not exceed max
+func arrayIndex(token string, max int) (int, error) {
+    if token == "" || (len(token) > 1 && token[0] == '0') || strings.Trim(token, "0123456789") != "" {
+        return 0, ErrPathNotFound
+    }
+    i, err := strconv.Atoi(token)
+    if err != nil || i > max {
+        return 0, ErrPathNotFound
+    }
+    return i, nil
+}
+
+// deepCopy copies the maps and slices of a decoded JSON value
+func deepCopy(value interface{}) interface{} {
+    switch v := value.(type) {
+    case map[string]interface{}:
+        copied := make(map[string]interface{}, len(v))
+        for name, member := range v {
+            copied[name] = deepCopy(member)
+        }
+        return copied
+    case []interface{}:
+        copied := make([]interface{}, len(v))
+        for i, element := range v {
+            copied[i] = deepCopy(element)
+        }
+        return copied
+    }
+    return value
+}
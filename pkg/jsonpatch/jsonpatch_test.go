@@ -0,0 +1,127 @@
+package jsonpatch
+
+import (
+    "encoding/json"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, data string) interface{} {
+    var value interface{}
+    require.NoError(t, json.Unmarshal([]byte(data), &value))
+    return value
+}
+
+func TestPatch_Apply(t *testing.T) {
+    // Ejemplos del apéndice A de RFC 6902
+    tests := []struct {
+        name     string
+        doc      string
+        patch    string
+        expected string
+    }{
+        {"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+        {"add element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+        {"append element", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc"]}]`, `{"foo":["bar",["abc"]]}`},
+        {"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+        {"remove element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+        {"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+        {"move member", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+        {"move element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+        {"copy", `{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"}]`, `{"foo":{"bar":1},"baz":{"bar":1}}`},
+        {"test and add", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/foo","value":["a",2,"c"]},{"op":"add","path":"/n","value":null}]`, `{"baz":"qux","foo":["a",2,"c"],"n":null}`},
+        {"escaped pointer", `{"a/b":{"m~n":1}}`, `[{"op":"replace","path":"/a~1b/m~0n","value":2}]`, `{"a/b":{"m~n":2}}`},
+        {"replace document", `{"foo":"bar"}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            patch, err := DecodePatch([]byte(tt.patch))
+            require.NoError(t, err)
+
+            result, err := patch.Apply(decode(t, tt.doc))
+            require.NoError(t, err)
+            assert.Equal(t, decode(t, tt.expected), result)
+        })
+    }
+}
+
+func TestPatch_Errors(t *testing.T) {
+    doc := decode(t, `{"foo":["bar"],"baz":"qux"}`)
+
+    // Documentos de patch mal formados
+    for _, invalid := range []string{
+        `{"op":"add"}`,
+        `[{"op":"add","path":"/a"}]`,
+        `[{"op":"jump","path":"/a"}]`,
+        `[{"op":"remove","path":"a"}]`,
+        `[{"op":"copy","path":"/a"}]`,
+        `[{"op":"move","from":"/foo","path":"/foo/0"}]`,
+        `[{"op":"remove","path":"/a~2"}]`,
+    } {
+        _, err := DecodePatch([]byte(invalid))
+        assert.ErrorIs(t, err, ErrInvalidPatch, invalid)
+    }
+
+    // Operaciones que no se pueden aplicar
+    tests := []struct {
+        patch string
+        err   error
+    }{
+        {`[{"op":"remove","path":"/missing"}]`, ErrPathNotFound},
+        {`[{"op":"replace","path":"/foo/1","value":1}]`, ErrPathNotFound},
+        {`[{"op":"add","path":"/foo/01","value":1}]`, ErrPathNotFound},
+        {`[{"op":"add","path":"/missing/a","value":1}]`, ErrPathNotFound},
+        {`[{"op":"test","path":"/baz","value":"other"}]`, ErrTestFailed},
+    }
+    for _, tt := range tests {
+        patch, err := DecodePatch([]byte(tt.patch))
+        require.NoError(t, err)
+
+        _, err = patch.Apply(doc)
+        assert.ErrorIs(t, err, tt.err, tt.patch)
+    }
+
+    // Un patch que falla no modifica el documento
+    patch, err := DecodePatch([]byte(`[{"op":"add","path":"/foo/-","value":"x"},{"op":"test","path":"/baz","value":"other"}]`))
+    require.NoError(t, err)
+    _, err = patch.Apply(doc)
+    var patchErr *Error
+    require.ErrorAs(t, err, &patchErr)
+    assert.Equal(t, 1, patchErr.Index)
+    assert.Equal(t, decode(t, `{"foo":["bar"],"baz":"qux"}`), doc)
+}
+
+func TestMergePatch(t *testing.T) {
+    // Ejemplos del apéndice A de RFC 7396
+    tests := []struct {
+        target   string
+        patch    string
+        expected string
+    }{
+        {`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+        {`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+        {`{"a":"b"}`, `{"a":null}`, `{}`},
+        {`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+        {`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+        {`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+        {`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+        {`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+        {`["a","b"]`, `["c","d"]`, `["c","d"]`},
+        {`{"a":"b"}`, `["c"]`, `["c"]`},
+        {`{"a":"foo"}`, `null`, `null`},
+        {`{"a":"foo"}`, `"bar"`, `"bar"`},
+        {`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+        {`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+        {`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+    }
+
+    for _, tt := range tests {
+        target := decode(t, tt.target)
+        result := MergePatch(target, decode(t, tt.patch))
+        assert.Equal(t, decode(t, tt.expected), result, tt.target+" + "+tt.patch)
+        assert.Equal(t, decode(t, tt.target), target)
+    }
+}
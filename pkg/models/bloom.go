@@ -0,0 +1,16 @@
+package models
+
+import (
+    "time"
+)
+
+// BloomFilter describes a Bloom filter sized for a capacity and false-positive rate
+type BloomFilter struct {
+    Key       string    `json:"key"`
+    Capacity  int64     `json:"capacity"`   // Items the filter holds at ErrorRate
+    ErrorRate float64   `json:"error_rate"` // Target false-positive probability
+    Bits      uint64    `json:"bits"`
+    Hashes    int       `json:"hashes"`
+    Items     int64     `json:"items"` // Items added that were not already reported present
+    CreatedAt time.Time `json:"created_at"`
+}
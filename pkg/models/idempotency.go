@@ -0,0 +1,34 @@
+package models
+
+import (
+    "time"
+)
+
+// Idempotency record states
+const (
+    IdempotencyInProgress = "in_progress"
+    IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyRecord represents the state of a request identified by an idempotency key
+type IdempotencyRecord struct {
+    Key         string              `json:"key"`
+    Status      string              `json:"status"`
+    Fingerprint string              `json:"fingerprint,omitempty"` // Identifies the request that claimed the key
+    Owner       string              `json:"owner,omitempty"`       // Only known by the claimer, required to complete or release
+    Response    *IdempotentResponse `json:"response,omitempty"`    // Set once completed
+    CreatedAt   time.Time           `json:"created_at"`
+    ExpiresAt   time.Time           `json:"expires_at"`
+}
+
+// IdempotentResponse is the stored result replayed to duplicate requests
+type IdempotentResponse struct {
+    StatusCode int                 `json:"status_code"`
+    Header     map[string][]string `json:"header,omitempty"`
+    Body       []byte              `json:"body,omitempty"` // Base64 in JSON
+}
+
+// Completed reports whether the record holds a final response
+func (r *IdempotencyRecord) Completed() bool {
+    return r.Status == IdempotencyCompleted
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+    "time"
+)
+
+// Lock represents a distributed lock held by an owner
+type Lock struct {
+    Name       string    `json:"name"`
+    Owner      string    `json:"owner"`
+    Token      int64     `json:"token"` // Fencing token, increases on every acquisition
+    AcquiredAt time.Time `json:"acquired_at"`
+    ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// RemainingTTL returns the remaining time until the lock expires
+func (l *Lock) RemainingTTL() time.Duration {
+    remaining := time.Until(l.ExpiresAt)
+    if remaining < 0 {
+        return 0
+    }
+    return remaining
+}
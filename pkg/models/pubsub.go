@@ -0,0 +1,8 @@
+package models
+
+// PubSubMessage represents a message received on a pub/sub channel
+type PubSubMessage struct {
+    Channel string      `json:"channel"`
+    Pattern string      `json:"pattern,omitempty"` // Pattern that matched the channel, for pattern subscriptions
+    Payload interface{} `json:"payload"`
+}
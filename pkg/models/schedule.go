@@ -0,0 +1,15 @@
+package models
+
+import (
+    "time"
+)
+
+// ScheduledJob represents a payload delivered to the consumers of a topic at a future time
+type ScheduledJob struct {
+    ID        string      `json:"id"`
+    Topic     string      `json:"topic"`
+    Payload   interface{} `json:"payload"`
+    DueAt     time.Time   `json:"due_at"` // Next delivery; once delivered, when it is redelivered unless acknowledged
+    CreatedAt time.Time   `json:"created_at"`
+    Attempts  int64       `json:"attempts"` // Times the job has been delivered
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+    "time"
+)
+
+// Session represents a user session with sliding expiry
+type Session struct {
+    ID             string                 `json:"id"`
+    UserID         string                 `json:"user_id"`
+    Attributes     map[string]interface{} `json:"attributes"`
+    TTL            time.Duration          `json:"ttl"` // Idle time after which the session expires
+    CreatedAt      time.Time              `json:"created_at"`
+    LastAccessedAt time.Time              `json:"last_accessed_at"`
+    ExpiresAt      time.Time              `json:"expires_at"`
+}
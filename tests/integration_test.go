@@ -1,46 +1,65 @@
 package tests
 
 import (
+    "bufio"
     "bytes"
     "context"
     "encoding/json"
     "fmt"
     "net/http"
     "net/http/httptest"
+    "strings"
     "testing"
     "time"
 
     "github.com/gin-gonic/gin"
+    "github.com/go-redis/redis/v8"
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/require"
     "go.uber.org/zap/zaptest"
 
     "distributed-cache/internal/cache"
+    "distributed-cache/internal/config"
     "distributed-cache/internal/handlers"
+    "distributed-cache/internal/middleware"
 )
 
 func setupTestServer(t *testing.T) (*gin.Engine, cache.Cache) {
     logger := zaptest.NewLogger(t)
-    config := cache.DefaultCacheConfig()
+    cacheConfig := cache.DefaultCacheConfig()
 
-    cacheInstance, err := cache.NewRedisCache(config, logger)
+    cacheInstance, err := cache.NewRedisCache(cacheConfig, logger)
     require.NoError(t, err)
 
-    // Clear the cache
-    err = cacheInstance.Clear(context.Background())
+    // Clear keeps the internal keys, so every test starts from an empty database
+    client := redis.NewClient(&redis.Options{Addr: cacheConfig.Addresses[0]})
+    defer client.Close()
+    err = client.FlushDB(context.Background()).Err()
     require.NoError(t, err)
 
     gin.SetMode(gin.TestMode)
     router := gin.New()
 
     cacheHandler := handlers.NewCacheHandler(cacheInstance, logger)
+    patchHandler := handlers.NewPatchHandler(cacheInstance, logger)
 
-    api := router.Group("/api/v1")
+    idempotency := middleware.Idempotency(cacheInstance, config.IdempotencyConfig{
+        Enabled:     true,
+        TTL:         time.Hour,
+        LockTTL:     30 * time.Second,
+        WaitTimeout: 100 * time.Millisecond,
+        Methods:     []string{"POST", "PUT", "PATCH", "DELETE"},
+    }, logger)
+
+    api := router.Group("/api/v1", idempotency)
     cache := api.Group("/cache")
     {
         cache.PUT("/:key", cacheHandler.SetItem)
         cache.GET("/:key", cacheHandler.GetItem)
         cache.DELETE("/:key", cacheHandler.DeleteItem)
+        cache.POST("/:key/getset", cacheHandler.GetSetItem)
+        cache.POST("/:key/getdel", cacheHandler.GetDelItem)
+        cache.PATCH("/:key", patchHandler.PatchItem)
         cache.HEAD("/:key", cacheHandler.ExistsItem)
         cache.POST("/batch", cacheHandler.SetMultiple)
         cache.POST("/batch/get", cacheHandler.GetMultiple)
@@ -52,8 +71,129 @@ func setupTestServer(t *testing.T) (*gin.Engine, cache.Cache) {
         cache.GET("/:key/ttl", cacheHandler.GetTTL)
     }
 
+    lockHandler := handlers.NewLockHandler(cacheInstance, logger)
+    locks := api.Group("/locks")
+    {
+        locks.POST("/:name", lockHandler.Acquire)
+        locks.PUT("/:name/renew", lockHandler.Renew)
+        locks.DELETE("/:name", lockHandler.Release)
+    }
+
+    rateLimitHandler := handlers.NewRateLimitHandler(cacheInstance, logger)
+    api.POST("/ratelimit/:bucket/take", rateLimitHandler.Take)
+
+    idempotencyHandler := handlers.NewIdempotencyHandler(cacheInstance, logger)
+    idempotencyKeys := api.Group("/idempotency")
+    {
+        idempotencyKeys.POST("/:id/claim", idempotencyHandler.Claim)
+        idempotencyKeys.PUT("/:id/complete", idempotencyHandler.Complete)
+        idempotencyKeys.DELETE("/:id", idempotencyHandler.Release)
+        idempotencyKeys.GET("/:id", idempotencyHandler.Get)
+    }
+
+    sessionHandler := handlers.NewSessionHandler(cacheInstance, logger)
+    sessions := api.Group("/sessions")
+    {
+        sessions.POST("", sessionHandler.CreateSession)
+        sessions.GET("", sessionHandler.ListUserSessions)
+        sessions.DELETE("", sessionHandler.DeleteUserSessions)
+        sessions.GET("/:id", sessionHandler.GetSession)
+        sessions.PATCH("/:id", sessionHandler.UpdateSession)
+        sessions.POST("/:id/touch", sessionHandler.TouchSession)
+        sessions.DELETE("/:id", sessionHandler.DeleteSession)
+    }
+
+    hashHandler := handlers.NewHashHandler(cacheInstance, logger)
+    hash := api.Group("/hash")
+    {
+        hash.GET("/:key", hashHandler.GetHash)
+        hash.GET("/:key/len", hashHandler.CountFields)
+        hash.PUT("/:key/fields", hashHandler.SetFields)
+        hash.GET("/:key/fields/:field", hashHandler.GetField)
+        hash.PUT("/:key/fields/:field", hashHandler.SetField)
+        hash.DELETE("/:key/fields/:field", hashHandler.DeleteField)
+        hash.POST("/:key/fields/:field/incr", hashHandler.IncrementField)
+    }
+
+    listHandler := handlers.NewListHandler(cacheInstance, config.ListConfig{MaxBlockingPops: 1}, logger)
+    list := api.Group("/list")
+    {
+        list.GET("/:key", listHandler.GetRange)
+        list.GET("/:key/len", listHandler.Length)
+        list.POST("/:key/push", listHandler.Push)
+        list.POST("/:key/pop", listHandler.Pop)
+        list.PUT("/:key/trim", listHandler.Trim)
+    }
+
+    setHandler := handlers.NewSetHandler(cacheInstance, logger)
+    set := api.Group("/set")
+    {
+        set.POST("/union", setHandler.Union)
+        set.POST("/inter", setHandler.Intersection)
+        set.GET("/:key/members", setHandler.ScanMembers)
+        set.POST("/:key/members", setHandler.AddMembers)
+        set.DELETE("/:key/members", setHandler.RemoveMembers)
+        set.GET("/:key/members/:member", setHandler.IsMember)
+        set.DELETE("/:key/members/:member", setHandler.RemoveMember)
+        set.GET("/:key/card", setHandler.Cardinality)
+    }
+
+    zsetHandler := handlers.NewZSetHandler(cacheInstance, logger)
+    zset := api.Group("/zset")
+    {
+        zset.POST("/:key/members", zsetHandler.AddMembers)
+        zset.GET("/:key/members/:member", zsetHandler.GetRank)
+        zset.DELETE("/:key/members/:member", zsetHandler.RemoveMember)
+        zset.POST("/:key/members/:member/incr", zsetHandler.IncrementScore)
+        zset.GET("/:key/range", zsetHandler.RangeByRank)
+        zset.GET("/:key/range/score", zsetHandler.RangeByScore)
+        zset.GET("/:key/card", zsetHandler.Cardinality)
+    }
+
+    hllHandler := handlers.NewHyperLogLogHandler(cacheInstance, config.HyperLogLogConfig{MaxElements: 100}, logger)
+    hll := api.Group("/hll")
+    {
+        hll.POST("/:key", hllHandler.Add)
+        hll.GET("/:key", hllHandler.Count)
+        hll.POST("/:key/merge", hllHandler.Merge)
+    }
+
+    bloomHandler := handlers.NewBloomHandler(cacheInstance, config.BloomConfig{DefaultCapacity: 1000, DefaultErrorRate: 0.01, MaxBits: 1 << 20, MaxItems: 100}, logger)
+    bloom := api.Group("/bloom")
+    {
+        bloom.PUT("/:key", bloomHandler.Reserve)
+        bloom.GET("/:key", bloomHandler.Info)
+        bloom.DELETE("/:key", bloomHandler.Delete)
+        bloom.POST("/:key/items", bloomHandler.AddItems)
+        bloom.POST("/:key/items/exists", bloomHandler.CheckItems)
+        bloom.GET("/:key/items/:item", bloomHandler.CheckItem)
+    }
+
+    scheduleHandler := handlers.NewScheduleHandler(cacheInstance, config.SchedulerConfig{PollInterval: 20 * time.Millisecond, LeaseTimeout: 30 * time.Second}, logger)
+    schedule := api.Group("/schedule")
+    {
+        schedule.POST("", scheduleHandler.Schedule)
+        schedule.POST("/:topic/poll", scheduleHandler.Poll)
+        schedule.GET("/:topic/events", scheduleHandler.Events)
+        schedule.GET("/:topic/jobs/:id", scheduleHandler.GetJob)
+        schedule.DELETE("/:topic/jobs/:id", scheduleHandler.CancelJob)
+        schedule.POST("/:topic/jobs/:id/ack", scheduleHandler.AckJob)
+        schedule.POST("/:topic/jobs/:id/retry", scheduleHandler.RetryJob)
+    }
+
+    pubsubHandler := handlers.NewPubSubHandler(cacheInstance, config.PubSubConfig{BufferSize: 10, WriteTimeout: time.Second, MaxSubscribers: 1}, logger)
+    pubsub := api.Group("/pubsub")
+    {
+        pubsub.GET("", pubsubHandler.Subscribe)
+        pubsub.POST("/:channel", pubsubHandler.Publish)
+    }
+
     router.GET("/health", cacheHandler.Health)
 
+    healthHandler := handlers.NewHealthHandler(cacheInstance, config.HealthConfig{CheckTimeout: 2 * time.Second}, logger)
+    router.GET("/livez", healthHandler.Livez)
+    router.GET("/readyz", healthHandler.Readyz)
+
     return router, cacheInstance
 }
 
@@ -340,6 +480,61 @@ func TestAPI_Health(t *testing.T) {
     assert.Equal(t, "healthy", response["status"])
 }
 
+func TestAPI_Readyz(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    req := httptest.NewRequest("GET", "/livez", nil)
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    req = httptest.NewRequest("GET", "/readyz", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response struct {
+        Status   string                    `json:"status"`
+        Draining bool                      `json:"draining"`
+        Checks   map[string]handlers.Check `json:"checks"`
+    }
+    require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+    assert.Equal(t, "ready", response.Status)
+    assert.False(t, response.Draining)
+    assert.Equal(t, handlers.CheckOK, response.Checks["redis"].Status)
+    assert.Contains(t, response.Checks, "pool")
+    assert.Contains(t, response.Checks, "replication")
+}
+
+func TestAPI_ReadyzDraining(t *testing.T) {
+    logger := zaptest.NewLogger(t)
+    cacheInstance, err := cache.NewRedisCache(cache.DefaultCacheConfig(), logger)
+    require.NoError(t, err)
+    defer cacheInstance.Close()
+
+    healthHandler := handlers.NewHealthHandler(cacheInstance, config.HealthConfig{CheckTimeout: 2 * time.Second}, logger)
+    router := gin.New()
+    router.GET("/livez", healthHandler.Livez)
+    router.GET("/readyz", healthHandler.Readyz)
+
+    healthHandler.SetDraining(true)
+
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+    assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+    var response map[string]interface{}
+    require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+    assert.Equal(t, "not_ready", response["status"])
+    assert.Equal(t, true, response["draining"])
+
+    // Liveness is unaffected while draining
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+    assert.Equal(t, http.StatusOK, w.Code)
+}
+
 func TestAPI_ExpirationBehavior(t *testing.T) {
     router, cacheInstance := setupTestServer(t)
     defer cacheInstance.Close()
@@ -373,3 +568,982 @@ func TestAPI_ExpirationBehavior(t *testing.T) {
     router.ServeHTTP(w, req)
     assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+
+func TestAPI_Locks(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    // Adquirir el lock
+    body, _ := json.Marshal(map[string]interface{}{"owner": "worker-1", "ttl": "30s"})
+    req := httptest.NewRequest("POST", "/api/v1/locks/nightly-job", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var lockResponse map[string]interface{}
+    err := json.Unmarshal(w.Body.Bytes(), &lockResponse)
+    assert.NoError(t, err)
+    assert.Equal(t, "worker-1", lockResponse["owner"])
+    assert.NotZero(t, lockResponse["token"])
+
+    // Otro worker recibe conflicto
+    body, _ = json.Marshal(map[string]interface{}{"owner": "worker-2"})
+    req = httptest.NewRequest("POST", "/api/v1/locks/nightly-job", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusConflict, w.Code)
+
+    // Renovar
+    body, _ = json.Marshal(map[string]interface{}{"owner": "worker-1", "ttl": "1m"})
+    req = httptest.NewRequest("PUT", "/api/v1/locks/nightly-job/renew", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    // Liberar
+    body, _ = json.Marshal(map[string]interface{}{"owner": "worker-1"})
+    req = httptest.NewRequest("DELETE", "/api/v1/locks/nightly-job", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAPI_RateLimitTake(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    payload := map[string]interface{}{
+        "algorithm": "sliding_window",
+        "limit":     2,
+        "window":    "1m",
+    }
+
+    // Las dos primeras peticiones se permiten
+    for i := 0; i < 2; i++ {
+        body, _ := json.Marshal(payload)
+        req := httptest.NewRequest("POST", "/api/v1/ratelimit/reports/take", bytes.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+    }
+
+    // La tercera se rechaza
+    body, _ := json.Marshal(payload)
+    req := httptest.NewRequest("POST", "/api/v1/ratelimit/reports/take", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusTooManyRequests, w.Code)
+    assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+    var response map[string]interface{}
+    err := json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, false, response["allowed"])
+
+    // Más tokens que la capacidad o una ventana menor de 1ms se rechazan
+    for _, invalid := range []map[string]interface{}{
+        {"limit": 2, "window": "1m", "tokens": 3},
+        {"limit": 2, "window": "1m", "burst": 5, "tokens": 6},
+        {"limit": 2, "window": "500us"},
+    } {
+        body, _ := json.Marshal(invalid)
+        req := httptest.NewRequest("POST", "/api/v1/ratelimit/reports/take", bytes.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusBadRequest, w.Code)
+    }
+}
+
+func TestAPI_HashFields(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    // Guardar varios campos con TTL
+    body, _ := json.Marshal(map[string]interface{}{
+        "fields": map[string]interface{}{"name": "Ada", "visits": 1},
+        "ttl":    "1h",
+    })
+    req := httptest.NewRequest("PUT", "/api/v1/hash/profile:1/fields", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    // Actualizar un solo campo
+    body, _ = json.Marshal(map[string]interface{}{"value": "Ada Lovelace"})
+    req = httptest.NewRequest("PUT", "/api/v1/hash/profile:1/fields/name", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    // Incrementar un contador
+    body, _ = json.Marshal(map[string]interface{}{"by": 5})
+    req = httptest.NewRequest("POST", "/api/v1/hash/profile:1/fields/visits/incr", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response map[string]interface{}
+    err := json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, float64(6), response["value"])
+
+    // Leer el hash completo
+    req = httptest.NewRequest("GET", "/api/v1/hash/profile:1", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    err = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    fields := response["fields"].(map[string]interface{})
+    assert.Equal(t, "Ada Lovelace", fields["name"])
+    assert.Equal(t, float64(6), fields["visits"])
+
+    // Borrar un campo
+    req = httptest.NewRequest("DELETE", "/api/v1/hash/profile:1/fields/name", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    req = httptest.NewRequest("GET", "/api/v1/hash/profile:1/fields/name", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+
+    // Un campo no numérico no se puede incrementar
+    body, _ = json.Marshal(map[string]interface{}{"value": "text"})
+    req = httptest.NewRequest("PUT", "/api/v1/hash/profile:1/fields/name", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    router.ServeHTTP(httptest.NewRecorder(), req)
+
+    req = httptest.NewRequest("POST", "/api/v1/hash/profile:1/fields/name/incr", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAPI_ListQueue(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    // Encolar trabajos con un tamaño máximo
+    body, _ := json.Marshal(map[string]interface{}{
+        "values":  []interface{}{"job-1", "job-2", "job-3"},
+        "ttl":     "1h",
+        "max_len": 2,
+    })
+    req := httptest.NewRequest("POST", "/api/v1/list/queue:jobs/push", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response map[string]interface{}
+    err := json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, float64(2), response["length"])
+
+    // Leer el rango completo
+    req = httptest.NewRequest("GET", "/api/v1/list/queue:jobs", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    err = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, []interface{}{"job-2", "job-3"}, response["values"])
+
+    // Consumir por la cabeza
+    req = httptest.NewRequest("POST", "/api/v1/list/queue:jobs/pop", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    err = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, "job-2", response["value"])
+
+    // Un pop bloqueante sobre una lista vacía termina con 404 al agotar el timeout
+    req = httptest.NewRequest("POST", "/api/v1/list/queue:empty/pop?timeout=100ms", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+
+    // Timeout fuera de rango
+    req = httptest.NewRequest("POST", "/api/v1/list/queue:jobs/pop?timeout=1h", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+
+    // Los pops bloqueantes por encima del límite se rechazan para no agotar el pool
+    waiting := make(chan int)
+    go func() {
+        req := httptest.NewRequest("POST", "/api/v1/list/queue:empty/pop?timeout=500ms", nil)
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        waiting <- w.Code
+    }()
+    time.Sleep(100 * time.Millisecond)
+
+    req = httptest.NewRequest("POST", "/api/v1/list/queue:empty/pop?timeout=500ms", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+    assert.Equal(t, http.StatusNotFound, <-waiting)
+
+    // Sin timeout no cuenta para el límite
+    req = httptest.NewRequest("POST", "/api/v1/list/queue:empty/pop", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPI_SetsAndLeaderboard(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    // Añadir miembros a dos sets
+    for key, members := range map[string][]string{
+        "team:red":  {"ana", "bob"},
+        "team:blue": {"bob", "eve"},
+    } {
+        body, _ := json.Marshal(map[string]interface{}{"members": members})
+        req := httptest.NewRequest("POST", "/api/v1/set/"+key+"/members", bytes.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusOK, w.Code)
+    }
+
+    req := httptest.NewRequest("GET", "/api/v1/set/team:red/members/ana", nil)
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response map[string]interface{}
+    err := json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, true, response["is_member"])
+
+    // Intersección
+    body, _ := json.Marshal(map[string]interface{}{"keys": []string{"team:red", "team:blue"}})
+    req = httptest.NewRequest("POST", "/api/v1/set/inter", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    err = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, []interface{}{"bob"}, response["members"])
+
+    // Leaderboard con TTL
+    body, _ = json.Marshal(map[string]interface{}{
+        "members": []map[string]interface{}{
+            {"member": "ana", "score": 10},
+            {"member": "bob", "score": 30},
+        },
+        "ttl": "24h",
+    })
+    req = httptest.NewRequest("POST", "/api/v1/zset/scores:daily/members", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    body, _ = json.Marshal(map[string]interface{}{"by": 25})
+    req = httptest.NewRequest("POST", "/api/v1/zset/scores:daily/members/ana/incr", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    req = httptest.NewRequest("GET", "/api/v1/zset/scores:daily/range?start=0&stop=0&reverse=true", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    err = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    members := response["members"].([]interface{})
+    require.Len(t, members, 1)
+    assert.Equal(t, "ana", members[0].(map[string]interface{})["member"])
+
+    req = httptest.NewRequest("GET", "/api/v1/zset/scores:daily/members/bob?reverse=true", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    err = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, float64(1), response["rank"])
+
+    // Límites de puntuación inválidos
+    req = httptest.NewRequest("GET", "/api/v1/zset/scores:daily/range/score?min=abc", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPI_PatchItem(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    body, _ := json.Marshal(map[string]interface{}{
+        "value": map[string]interface{}{"name": "Ada", "tags": []string{"math"}, "visits": 1},
+        "ttl":   "30m",
+    })
+    req := httptest.NewRequest("PUT", "/api/v1/cache/profile", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    require.Equal(t, http.StatusOK, w.Code)
+
+    patch := func(contentType, body string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest("PATCH", "/api/v1/cache/profile", strings.NewReader(body))
+        req.Header.Set("Content-Type", contentType)
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    // JSON Patch
+    w = patch("application/json-patch+json", `[
+        {"op": "test", "path": "/visits", "value": 1},
+        {"op": "replace", "path": "/visits", "value": 2},
+        {"op": "add", "path": "/tags/-", "value": "computing"}
+    ]`)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response map[string]interface{}
+    err := json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, map[string]interface{}{
+        "name":   "Ada",
+        "tags":   []interface{}{"math", "computing"},
+        "visits": float64(2),
+    }, response["value"])
+
+    // Merge Patch
+    w = patch("application/merge-patch+json", `{"name": "Ada Lovelace", "tags": null}`)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    err = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, map[string]interface{}{"name": "Ada Lovelace", "visits": float64(2)}, response["value"])
+
+    // El TTL original se conserva
+    ttl, err := cacheInstance.TTL(context.Background(), "profile")
+    require.NoError(t, err)
+    assert.Greater(t, ttl, 29*time.Minute)
+    assert.LessOrEqual(t, ttl, 30*time.Minute)
+
+    // Errores
+    w = patch("application/json-patch+json", `[{"op": "test", "path": "/visits", "value": 1}]`)
+    assert.Equal(t, http.StatusConflict, w.Code)
+
+    w = patch("application/json-patch+json", `[{"op": "remove", "path": "/missing"}]`)
+    assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+    w = patch("application/json-patch+json", `[{"op": "add", "path": "/a"}]`)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+
+    w = patch("application/json", `{"name": "Ada"}`)
+    assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+    assert.Contains(t, w.Header().Get("Accept-Patch"), "application/merge-patch+json")
+
+    req = httptest.NewRequest("PATCH", "/api/v1/cache/missing", strings.NewReader(`{"a": 1}`))
+    req.Header.Set("Content-Type", "application/merge-patch+json")
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPI_ConditionalWrites(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    put := func(path, value string) *httptest.ResponseRecorder {
+        body, _ := json.Marshal(map[string]interface{}{"value": value})
+        req := httptest.NewRequest("PUT", path, bytes.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    // Crear una sola vez
+    w := put("/api/v1/cache/dedupe:order-7?mode=nx", "processed")
+    assert.Equal(t, http.StatusOK, w.Code)
+    w = put("/api/v1/cache/dedupe:order-7?mode=nx", "again")
+    assert.Equal(t, http.StatusConflict, w.Code)
+
+    // Actualizar solo si existe
+    w = put("/api/v1/cache/missing?mode=xx", "value")
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    w = put("/api/v1/cache/dedupe:order-7?mode=xx", "refunded")
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    w = put("/api/v1/cache/dedupe:order-7?mode=other", "value")
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+
+    // GetSet
+    body, _ := json.Marshal(map[string]interface{}{"value": "archived", "ttl": "10m"})
+    req := httptest.NewRequest("POST", "/api/v1/cache/dedupe:order-7/getset", bytes.NewReader(body))
+    req.Header.Set("Content-Type", "application/json")
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var response map[string]interface{}
+    err := json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    require.NotNil(t, response["previous"])
+    assert.Equal(t, "refunded", response["previous"].(map[string]interface{})["value"])
+
+    // GetDel
+    req = httptest.NewRequest("POST", "/api/v1/cache/dedupe:order-7/getdel", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    err = json.Unmarshal(w.Body.Bytes(), &response)
+    assert.NoError(t, err)
+    assert.Equal(t, "archived", response["value"])
+
+    req = httptest.NewRequest("POST", "/api/v1/cache/dedupe:order-7/getdel", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPI_IdempotencyKeyHeader(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    ctx := context.Background()
+    require.NoError(t, cacheInstance.Set(ctx, "payment:1", "pending", time.Hour))
+
+    getdel := func(key, idempotencyKey string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest("POST", "/api/v1/cache/"+key+"/getdel", nil)
+        if idempotencyKey != "" {
+            req.Header.Set(middleware.IdempotencyKeyHeader, idempotencyKey)
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    w := getdel("payment:1", "charge-1")
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Empty(t, w.Header().Get(middleware.IdempotentReplayedHeader))
+    first := w.Body.String()
+
+    // El reintento recibe la misma respuesta aunque la clave ya no exista
+    w = getdel("payment:1", "charge-1")
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "true", w.Header().Get(middleware.IdempotentReplayedHeader))
+    assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+    assert.Equal(t, first, w.Body.String())
+
+    // Sin cabecera la petición se ejecuta de nuevo
+    w = getdel("payment:1", "")
+    assert.Equal(t, http.StatusNotFound, w.Code)
+
+    // La misma clave con otra petición
+    w = getdel("payment:2", "charge-1")
+    assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+    // Un duplicado concurrente recibe 409 tras esperar
+    pop := func() *httptest.ResponseRecorder {
+        req := httptest.NewRequest("POST", "/api/v1/list/jobs/pop?timeout=1s", nil)
+        req.Header.Set(middleware.IdempotencyKeyHeader, "pop-1")
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    done := make(chan *httptest.ResponseRecorder)
+    go func() { done <- pop() }()
+    time.Sleep(200 * time.Millisecond)
+
+    w = pop()
+    assert.Equal(t, http.StatusConflict, w.Code)
+    assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+    first = (<-done).Body.String()
+    w = pop()
+    assert.Equal(t, "true", w.Header().Get(middleware.IdempotentReplayedHeader))
+    assert.Equal(t, first, w.Body.String())
+}
+
+func TestAPI_IdempotencyKeys(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    send := func(method, path string, payload interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+        body, _ := json.Marshal(payload)
+        req := httptest.NewRequest(method, path, bytes.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+
+        var response map[string]interface{}
+        json.Unmarshal(w.Body.Bytes(), &response)
+        return w, response
+    }
+
+    // Reclamar la clave
+    w, response := send("POST", "/api/v1/idempotency/job-1/claim", map[string]interface{}{"fingerprint": "abc", "lock_ttl": "10s"})
+    assert.Equal(t, http.StatusCreated, w.Code)
+    assert.Equal(t, "job-1", response["key"])
+    assert.Equal(t, "in_progress", response["status"])
+    owner, _ := response["owner"].(string)
+    require.NotEmpty(t, owner)
+
+    // Un duplicado ve la petición en curso
+    w, response = send("POST", "/api/v1/idempotency/job-1/claim", map[string]interface{}{"fingerprint": "abc"})
+    assert.Equal(t, http.StatusConflict, w.Code)
+    assert.Nil(t, response["owner"])
+
+    w, _ = send("POST", "/api/v1/idempotency/job-1/claim", map[string]interface{}{"fingerprint": "other"})
+    assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+    // Completar
+    result := map[string]interface{}{"status_code": 201, "body": "eyJpZCI6MX0="}
+    w, _ = send("PUT", "/api/v1/idempotency/job-1/complete", map[string]interface{}{"owner": "intruder", "response": result})
+    assert.Equal(t, http.StatusConflict, w.Code)
+
+    w, response = send("PUT", "/api/v1/idempotency/job-1/complete", map[string]interface{}{"owner": owner, "response": result, "ttl": "1h"})
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "completed", response["status"])
+
+    // Los duplicados reciben la respuesta guardada
+    w, response = send("POST", "/api/v1/idempotency/job-1/claim", map[string]interface{}{"fingerprint": "abc"})
+    assert.Equal(t, http.StatusOK, w.Code)
+    require.NotNil(t, response["response"])
+    assert.Equal(t, "eyJpZCI6MX0=", response["response"].(map[string]interface{})["body"])
+
+    req := httptest.NewRequest("GET", "/api/v1/idempotency/job-1", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    // Liberar una clave en curso
+    w, response = send("POST", "/api/v1/idempotency/job-2/claim", map[string]interface{}{"owner": "worker-1"})
+    assert.Equal(t, http.StatusCreated, w.Code)
+    assert.Equal(t, "worker-1", response["owner"])
+
+    w, _ = send("DELETE", "/api/v1/idempotency/job-2", map[string]interface{}{"owner": "worker-1"})
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    req = httptest.NewRequest("GET", "/api/v1/idempotency/job-2", nil)
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+
+    // Un lock_ttl menor de 1ms se rechaza
+    w, _ = send("POST", "/api/v1/idempotency/job-3/claim", map[string]interface{}{"lock_ttl": "500us"})
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPI_Sessions(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    send := func(method, path string, payload interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+        var body []byte
+        if payload != nil {
+            body, _ = json.Marshal(payload)
+        }
+        req := httptest.NewRequest(method, path, bytes.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+
+        var response map[string]interface{}
+        json.Unmarshal(w.Body.Bytes(), &response)
+        return w, response
+    }
+
+    // Crear dos sesiones del mismo usuario
+    w, response := send("POST", "/api/v1/sessions", map[string]interface{}{
+        "user_id":    "ada",
+        "attributes": map[string]interface{}{"theme": "dark"},
+        "ttl":        "15m",
+    })
+    assert.Equal(t, http.StatusCreated, w.Code)
+    assert.Equal(t, "15m0s", response["ttl"])
+    id, _ := response["id"].(string)
+    require.NotEmpty(t, id)
+
+    w, _ = send("POST", "/api/v1/sessions", map[string]interface{}{"user_id": "ada"})
+    assert.Equal(t, http.StatusCreated, w.Code)
+
+    w, _ = send("POST", "/api/v1/sessions", map[string]interface{}{"attributes": map[string]interface{}{}})
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+
+    // Leer, actualizar y extender
+    w, response = send("GET", "/api/v1/sessions/"+id, nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "ada", response["user_id"])
+
+    w, response = send("PATCH", "/api/v1/sessions/"+id, map[string]interface{}{
+        "attributes": map[string]interface{}{"theme": nil, "cart_items": 2},
+    })
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, map[string]interface{}{"cart_items": float64(2)}, response["attributes"])
+
+    w, _ = send("POST", "/api/v1/sessions/"+id+"/touch", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    // Listar las sesiones del usuario
+    w, response = send("GET", "/api/v1/sessions?user_id=ada", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, float64(2), response["count"])
+    // El listado no expone los IDs, que dan acceso a la sesión
+    for _, session := range response["sessions"].([]interface{}) {
+        assert.NotContains(t, session, "id")
+        assert.Equal(t, "ada", session.(map[string]interface{})["user_id"])
+    }
+
+    // Las claves internas de las sesiones no aparecen en los listados de claves
+    w, response = send("GET", "/api/v1/cache/keys?pattern=*session*", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, float64(0), response["count"])
+
+    w, _ = send("GET", "/api/v1/sessions", nil)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+
+    // Cerrar una sesión y después todas
+    w, _ = send("DELETE", "/api/v1/sessions/"+id, nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    w, _ = send("DELETE", "/api/v1/sessions/"+id, nil)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    w, _ = send("POST", "/api/v1/sessions/"+id+"/touch", nil)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+
+    w, response = send("DELETE", "/api/v1/sessions?user_id=ada", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, float64(1), response["deleted"])
+
+    w, response = send("GET", "/api/v1/sessions?user_id=ada", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, float64(0), response["count"])
+}
+
+func TestAPI_ProbabilisticStructures(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    send := func(method, path string, payload interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+        var body []byte
+        if payload != nil {
+            body, _ = json.Marshal(payload)
+        }
+        req := httptest.NewRequest(method, path, bytes.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+
+        var response map[string]interface{}
+        json.Unmarshal(w.Body.Bytes(), &response)
+        return w, response
+    }
+
+    // Visitantes únicos con HyperLogLog
+    w, response := send("POST", "/api/v1/hll/visits:home", map[string]interface{}{"elements": []string{"u1", "u2", "u3", "u1"}, "ttl": "24h"})
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, true, response["changed"])
+    w, _ = send("POST", "/api/v1/hll/visits:docs", map[string]interface{}{"elements": []string{"u3", "u4"}})
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    w, response = send("GET", "/api/v1/hll/visits:home", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, float64(3), response["count"])
+
+    w, response = send("POST", "/api/v1/hll/visits:all/merge", map[string]interface{}{"keys": []string{"visits:home", "visits:docs"}})
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, float64(4), response["count"])
+
+    w, _ = send("POST", "/api/v1/hll/visits:home", map[string]interface{}{"elements": []string{}})
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    w, _ = send("POST", "/api/v1/hll/visits:home", map[string]interface{}{"elements": make([]string, 101)})
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+
+    // Filtro de Bloom reservado explícitamente
+    w, response = send("PUT", "/api/v1/bloom/seen:payments", map[string]interface{}{"capacity": 1000, "error_rate": 0.001})
+    assert.Equal(t, http.StatusCreated, w.Code)
+    assert.Equal(t, float64(10), response["hashes"])
+    w, _ = send("PUT", "/api/v1/bloom/seen:payments", map[string]interface{}{"capacity": 1000, "error_rate": 0.001})
+    assert.Equal(t, http.StatusConflict, w.Code)
+    w, _ = send("PUT", "/api/v1/bloom/seen:invalid", map[string]interface{}{"capacity": 1000, "error_rate": 2})
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    // Filtros mayores que bloom.max_bits y peticiones con demasiados elementos
+    w, _ = send("PUT", "/api/v1/bloom/seen:huge", map[string]interface{}{"capacity": 1000000, "error_rate": 0.001})
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    w, _ = send("POST", "/api/v1/bloom/seen:payments/items", map[string]interface{}{"items": make([]string, 101)})
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+
+    w, response = send("POST", "/api/v1/bloom/seen:payments/items", map[string]interface{}{"items": []string{"p1", "p2", "p1"}})
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, []interface{}{true, true, false}, response["added"])
+
+    w, response = send("GET", "/api/v1/bloom/seen:payments/items/p1", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, true, response["exists"])
+
+    w, response = send("POST", "/api/v1/bloom/seen:payments/items/exists", map[string]interface{}{"items": []string{"p2", "p9"}})
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, []interface{}{true, false}, response["exists"])
+
+    // Al añadir a un filtro inexistente se crea con el tamaño por defecto
+    w, _ = send("POST", "/api/v1/bloom/seen:emails/items", map[string]interface{}{"items": []string{"a@example.com"}})
+    assert.Equal(t, http.StatusOK, w.Code)
+    w, response = send("GET", "/api/v1/bloom/seen:emails", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, float64(1000), response["capacity"])
+    assert.Equal(t, float64(1), response["items"])
+
+    // En un filtro inexistente no hay ningún elemento
+    w, response = send("GET", "/api/v1/bloom/seen:missing/items/p1", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, false, response["exists"])
+
+    w, _ = send("DELETE", "/api/v1/bloom/seen:emails", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    w, _ = send("GET", "/api/v1/bloom/seen:emails", nil)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPI_ScheduledJobs(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    send := func(method, path string, payload interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+        var body []byte
+        if payload != nil {
+            body, _ = json.Marshal(payload)
+        }
+        req := httptest.NewRequest(method, path, bytes.NewReader(body))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+
+        var response map[string]interface{}
+        json.Unmarshal(w.Body.Bytes(), &response)
+        return w, response
+    }
+
+    // Un trabajo para dentro de una hora se puede consultar y cancelar
+    w, response := send("POST", "/api/v1/schedule", map[string]interface{}{"topic": "reports", "payload": "monthly", "delay": "1h"})
+    require.Equal(t, http.StatusCreated, w.Code)
+    laterID := response["id"].(string)
+
+    w, response = send("GET", "/api/v1/schedule/reports/jobs/"+laterID, nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "monthly", response["payload"])
+
+    w, response = send("POST", "/api/v1/schedule/reports/poll", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Empty(t, response["jobs"])
+
+    w, _ = send("DELETE", "/api/v1/schedule/reports/jobs/"+laterID, nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    w, _ = send("GET", "/api/v1/schedule/reports/jobs/"+laterID, nil)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+
+    // El long-poll espera a que venza el trabajo
+    w, response = send("POST", "/api/v1/schedule", map[string]interface{}{"topic": "emails", "payload": map[string]interface{}{"to": "ana@example.com"}, "delay": "100ms"})
+    require.Equal(t, http.StatusCreated, w.Code)
+    id := response["id"].(string)
+
+    w, response = send("POST", "/api/v1/schedule/emails/poll?timeout=2s&lease=100ms", nil)
+    require.Equal(t, http.StatusOK, w.Code)
+    jobs := response["jobs"].([]interface{})
+    require.Len(t, jobs, 1)
+    assert.Equal(t, id, jobs[0].(map[string]interface{})["id"])
+    assert.Equal(t, float64(1), jobs[0].(map[string]interface{})["attempts"])
+
+    // Sin ack se vuelve a entregar al vencer el lease, ahora por SSE
+    ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+    defer cancel()
+    req := httptest.NewRequest("GET", "/api/v1/schedule/emails/events", nil).WithContext(ctx)
+    stream := httptest.NewRecorder()
+    router.ServeHTTP(stream, req)
+    assert.Equal(t, "text/event-stream", stream.Header().Get("Content-Type"))
+    assert.Contains(t, stream.Body.String(), "event:job")
+    assert.Contains(t, stream.Body.String(), `"id":"`+id+`"`)
+    assert.Contains(t, stream.Body.String(), `"attempts":2`)
+
+    w, _ = send("POST", "/api/v1/schedule/emails/jobs/"+id+"/ack", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    w, _ = send("POST", "/api/v1/schedule/emails/jobs/"+id+"/ack", nil)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+
+    w, _ = send("POST", "/api/v1/schedule", map[string]interface{}{"topic": "emails", "delay": "1m", "at": time.Now()})
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    w, _ = send("POST", "/api/v1/schedule", map[string]interface{}{"payload": "no topic"})
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    w, _ = send("POST", "/api/v1/schedule/emails/poll?timeout=1m", nil)
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPI_ScheduleEventsShutdown(t *testing.T) {
+    cacheInstance, err := cache.NewRedisCache(cache.DefaultCacheConfig(), zaptest.NewLogger(t))
+    require.NoError(t, err)
+    defer cacheInstance.Close()
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    scheduleHandler := handlers.NewScheduleHandler(cacheInstance, config.SchedulerConfig{PollInterval: 20 * time.Millisecond}, zaptest.NewLogger(t))
+    router.GET("/schedule/:topic/events", scheduleHandler.Events)
+
+    server := httptest.NewServer(router)
+    defer server.Close()
+    server.Config.RegisterOnShutdown(scheduleHandler.Shutdown)
+
+    resp, err := http.Get(server.URL + "/schedule/emails/events")
+    require.NoError(t, err)
+    defer resp.Body.Close()
+    require.Equal(t, http.StatusOK, resp.StatusCode)
+
+    // El apagado cierra el stream en lugar de esperar a que el cliente lo corte
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+    defer cancel()
+    assert.NoError(t, server.Config.Shutdown(ctx))
+}
+
+func TestAPI_PubSub(t *testing.T) {
+    router, cacheInstance := setupTestServer(t)
+    defer cacheInstance.Close()
+
+    server := httptest.NewServer(router)
+    defer server.Close()
+
+    publish := func(channel string, message interface{}) (int, map[string]interface{}) {
+        body, _ := json.Marshal(map[string]interface{}{"message": message})
+        resp, err := http.Post(server.URL+"/api/v1/pubsub/"+channel, "application/json", bytes.NewReader(body))
+        require.NoError(t, err)
+        defer resp.Body.Close()
+
+        var response map[string]interface{}
+        json.NewDecoder(resp.Body).Decode(&response)
+        return resp.StatusCode, response
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/v1/pubsub?channel=orders&pattern=news.*", nil)
+    resp, err := http.DefaultClient.Do(req)
+    require.NoError(t, err)
+    defer resp.Body.Close()
+    require.Equal(t, http.StatusOK, resp.StatusCode)
+    assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+    // Lee el siguiente evento del stream
+    stream := bufio.NewReader(resp.Body)
+    next := func() (string, map[string]interface{}) {
+        var event string
+        var data map[string]interface{}
+        for {
+            line, err := stream.ReadString('\n')
+            require.NoError(t, err)
+            line = strings.TrimSpace(line)
+            switch {
+            case strings.HasPrefix(line, "event:"):
+                event = strings.TrimPrefix(line, "event:")
+            case strings.HasPrefix(line, "data:"):
+                require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &data))
+            case line == "" && event != "":
+                return event, data
+            }
+        }
+    }
+
+    event, data := next()
+    assert.Equal(t, "subscribed", event)
+    assert.Equal(t, []interface{}{"orders"}, data["channels"])
+
+    status, response := publish("orders", map[string]interface{}{"id": 42})
+    assert.Equal(t, http.StatusOK, status)
+    assert.Equal(t, float64(1), response["receivers"])
+
+    event, data = next()
+    assert.Equal(t, "message", event)
+    assert.Equal(t, "orders", data["channel"])
+    assert.Equal(t, map[string]interface{}{"id": float64(42)}, data["payload"])
+
+    publish("news.sports", "goal")
+    event, data = next()
+    assert.Equal(t, "message", event)
+    assert.Equal(t, "news.sports", data["channel"])
+    assert.Equal(t, "news.*", data["pattern"])
+    assert.Equal(t, "goal", data["payload"])
+
+    // Límite de suscripciones por instancia
+    second, err := http.Get(server.URL + "/api/v1/pubsub?channel=orders")
+    require.NoError(t, err)
+    second.Body.Close()
+    assert.Equal(t, http.StatusServiceUnavailable, second.StatusCode)
+
+    status, _ = publish("orders", nil)
+    assert.Equal(t, http.StatusBadRequest, status)
+
+    badRequest, err := http.Get(server.URL + "/api/v1/pubsub")
+    require.NoError(t, err)
+    badRequest.Body.Close()
+    assert.Equal(t, http.StatusBadRequest, badRequest.StatusCode)
+}
+
+func TestAPI_PubSubShutdown(t *testing.T) {
+    cacheInstance, err := cache.NewRedisCache(cache.DefaultCacheConfig(), zaptest.NewLogger(t))
+    require.NoError(t, err)
+    defer cacheInstance.Close()
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    pubsubHandler := handlers.NewPubSubHandler(cacheInstance, config.PubSubConfig{}, zaptest.NewLogger(t))
+    router.GET("/pubsub", pubsubHandler.Subscribe)
+
+    server := httptest.NewServer(router)
+    defer server.Close()
+    server.Config.RegisterOnShutdown(pubsubHandler.Shutdown)
+
+    resp, err := http.Get(server.URL + "/pubsub?channel=orders")
+    require.NoError(t, err)
+    defer resp.Body.Close()
+    require.Equal(t, http.StatusOK, resp.StatusCode)
+
+    // El apagado cierra la suscripción en lugar de esperar a que el cliente la corte
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+    defer cancel()
+    assert.NoError(t, server.Config.Shutdown(ctx))
+}